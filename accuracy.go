@@ -0,0 +1,72 @@
+package apcomplex
+
+import "C"
+
+// Accuracy describes the rounding error of the most recent operation on one part of
+// a Complex, relative to the exact result (mirrors big.Accuracy).
+type Accuracy int8
+
+// Constants describing the Accuracy of a result.
+const (
+	Below Accuracy = -1 // rounded value < exact value
+	Exact Accuracy = 0  // rounded value == exact value
+	Above Accuracy = +1 // rounded value > exact value
+)
+
+func (a Accuracy) String() string {
+	switch a {
+	case Below:
+		return "Below"
+	case Exact:
+		return "Exact"
+	case Above:
+		return "Above"
+	}
+	return "Accuracy(?)"
+}
+
+// accOf turns an MPFR ternary value into an Accuracy.
+func accOf(t C.int) Accuracy {
+	switch {
+	case t < 0:
+		return Below
+	case t > 0:
+		return Above
+	}
+	return Exact
+}
+
+// inexOf packs two MPFR ternary values into MPC's combined form (MPC_INEX).
+func inexOf(re, im C.int) C.int {
+	pos := func(t C.int) C.int {
+		switch {
+		case t < 0:
+			return 2
+		case t > 0:
+			return 1
+		}
+		return 0
+	}
+	return pos(re) | pos(im)<<2
+}
+
+// inexParts unpacks MPC's combined ternary value (MPC_INEX_RE / MPC_INEX_IM).
+func inexParts(inex C.int) (re, im Accuracy) {
+	neg := func(t C.int) Accuracy {
+		switch t {
+		case 0:
+			return Exact
+		case 2:
+			return Below
+		}
+		return Above
+	}
+	return neg(inex & 3), neg(inex >> 2)
+}
+
+// Acc reports how the real and imaginary parts of c were rounded by the most recent
+// operation that stored into c.
+func (c *Complex) Acc() (re, im Accuracy) { return inexParts(c.inex) }
+
+// IsExact reports whether the most recent operation that stored into c was exact in both parts.
+func (c *Complex) IsExact() bool { return c.inex == 0 }
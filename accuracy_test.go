@@ -0,0 +1,44 @@
+package apcomplex
+
+import "testing"
+
+func TestAccExactOps(t *testing.T) {
+	a := tp("1.5+0.25i")
+	b := tp("2-0.5i")
+	z := Add(a, b)
+	if re, im := z.Acc(); re != Exact || im != Exact || !z.IsExact() {
+		t.Fatalf("1.5+0.25i + 2-0.5i should be exact, got %v %v", re, im)
+	}
+	if e := Exp(tp("0")); !e.IsExact() {
+		t.Fatalf("exp(0) should be exact")
+	}
+}
+
+func TestAccDirected(t *testing.T) {
+	one := MustParse("1+2i", 64)
+	three := MustParse("3", 64)
+	lo := New(64).Div(one, three, RNDDD)
+	if re, im := lo.Acc(); re != Below || im != Below {
+		t.Fatalf("RNDDD quotient should be Below/Below, got %v/%v", re, im)
+	}
+	hi := New(64).Div(one, three, Rounding(RNDU, RNDZ))
+	if re, im := hi.Acc(); re != Above || im != Below {
+		t.Fatalf("(RNDU,RNDZ) quotient should be Above/Below, got %v/%v", re, im)
+	}
+	if hi.IsExact() {
+		t.Fatalf("1/3 cannot be exact")
+	}
+}
+
+func TestAccParse(t *testing.T) {
+	z := New(8).SetMode(RNDUU)
+	if err := z.SetString("0.1+0.5i"); err != nil {
+		t.Fatal(err)
+	}
+	if re, im := z.Acc(); re != Above || im != Exact {
+		t.Fatalf("parse 0.1+0.5i at 8 bits upward: got %v/%v", re, im)
+	}
+	if err := z.SetString("1x+2i"); err == nil {
+		t.Fatalf("expected error for malformed real part")
+	}
+}
@@ -0,0 +1,439 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// The Airy functions over the complex plane. With ζ = (2/3) z^(3/2), small |ζ| goes to
+// the Maclaurin series, summed at extra precision to absorb the cancellation among
+// terms as large as e^|ζ|. Once |ζ| is large enough for the smallest term of the
+// asymptotic expansion in 1/ζ to be below the working precision, Ai and Ai′ come from
+// that expansion, which holds up to exponentially small terms for |arg z| ≤ 2π/3. Past
+// the Stokes lines arg z = ±2π/3 the subdominant exponential is switched on, so there
+// the connection formula Ai(z) = -ω Ai(ωz) - ω² Ai(ω²z), ω = e^(2πi/3), takes over with
+// both rotated arguments back inside the sector; Bi is always taken from Ai at ωz and
+// ω̄z.
+//
+// Ai(x) for real |x| ≤ aiRealLimit is mpfr_ai's correctly rounded value. Everything else
+// is evaluated at airyWorkPrec and rounded once, with an error of a few ulps of the terms
+// combined: on the negative real axis that is the envelope of the oscillation, not the
+// value, so relative accuracy drops next to the zeros.
+
+// aiRealLimit bounds the real arguments handed to mpfr_ai, which gets slow far out on
+// the real axis.
+const aiRealLimit = 100
+
+// airyWorkPrec returns the working precision for an Airy function at prec bits from a:
+// e^(-ζ) turns the absolute error of ζ, about |a|^(3/2), into a relative one.
+func airyWorkPrec(prec uint, a *Complex) uint {
+	wp := prec + gammaGuard
+	if e := magExp(a); e > 0 {
+		wp += 3*uint(e)/2 + uint(bits.Len(uint(e)))
+	}
+	return wp
+}
+
+// airyZeta returns |ζ| = (2/3)|z|^(3/2) in float64, enough to pick an algorithm.
+func airyZeta(z *Complex) float64 {
+	return 2.0 / 3 * math.Pow(cmplx.Abs(z.Complex128()), 1.5)
+}
+
+// airyLarge reports whether the asymptotic expansions reach wp bits at z.
+func airyLarge(z *Complex, wp uint) bool { return airyZeta(z) >= 0.35*float64(wp)+4 }
+
+// airySeries sets ai = Ai(z) and bi = Bi(z), or Ai′(z) and Bi′(z) if deriv is set, from
+// the Maclaurin series
+//
+//	Ai(z) = c₁ f(z) - c₂ g(z),  Bi(z) = √3 (c₁ f(z) + c₂ g(z)),
+//	f(z) = Σ 3^k (1/3)_k z^(3k) / (3k)!,  g(z) = Σ 3^k (2/3)_k z^(3k+1) / (3k+1)!,
+//
+// with c₁ = Ai(0) = 3^(-2/3)/Γ(2/3) and c₂ = -Ai′(0) = 3^(-1/3)/Γ(1/3). Either of ai and
+// bi may be nil. The terms grow to about e^|ζ| while Ai may be as small as e^-|ζ|,
+// which sets the extra precision.
+func airySeries(ai, bi, z *Complex, deriv bool, wp uint) {
+	zeta := airyZeta(z)
+	wq := wp + uint(math.Ceil(2*zeta*math.Log2E)) + 8
+	z3, tf, tg := New(wq), New(wq), New(wq)
+	defer z3.Close()
+	defer tf.Close()
+	defer tg.Close()
+	z3.Sqr(z, RNDNN).Mul(z3, z, RNDNN)
+	// The first terms and the ratios of consecutive ones: t_k = t_(k-1) z³ / d(k).
+	df := func(k uint64) uint64 { return (3*k - 1) * 3 * k }
+	dg := func(k uint64) uint64 { return 3 * k * (3*k + 1) }
+	if deriv {
+		// f′ = z²/2 + …, g′ = 1 + …
+		tf.Sqr(z, RNDNN)
+		C.mpc_div_2si(&tf.z[0], &tf.z[0], 1, C.MPC_RNDNN)
+		tg.SetInt64(1, 0)
+		df = func(k uint64) uint64 { return 3 * k * (3*k + 2) }
+		dg = func(k uint64) uint64 { return 3 * k * (3*k - 2) }
+	} else {
+		tf.SetInt64(1, 0)
+		tg.Set(z, RNDNN)
+	}
+	f, g := New(wq).Set(tf, RNDNN), New(wq).Set(tg, RNDNN)
+	defer f.Close()
+	defer g.Close()
+	r3 := math.Pow(cmplx.Abs(z.Complex128()), 3)
+	for k := uint64(1); ; k++ {
+		tf.Mul(tf, z3, RNDNN)
+		C.mpc_div_ui(&tf.z[0], &tf.z[0], C.ulong(df(k)), C.MPC_RNDNN)
+		tg.Mul(tg, z3, RNDNN)
+		C.mpc_div_ui(&tg.z[0], &tg.z[0], C.ulong(dg(k)), C.MPC_RNDNN)
+		f.Add(f, tf, RNDNN)
+		g.Add(g, tg, RNDNN)
+		if 18*float64(k*k) > r3 && (tf.IsZero() || magExp(tf) < magExp(f)-int(wq)) &&
+			(tg.IsZero() || magExp(tg) < magExp(g)-int(wq)) {
+			break
+		}
+	}
+	c1, c2, t := NewReal(wq), NewReal(wq), NewReal(wq)
+	defer c1.Close()
+	defer c2.Close()
+	defer t.Close()
+	// c₂ = 1/(∛3 Γ(1/3)), c₁ = 1/(∛9 Γ(2/3))
+	C.mpfr_set_ui(t.ptr(), 3, C.MPFR_RNDN)
+	C.mpfr_cbrt(t.ptr(), t.ptr(), C.MPFR_RNDN)
+	C.mpfr_set_ui(c2.ptr(), 1, C.MPFR_RNDN)
+	C.mpfr_div_ui(c2.ptr(), c2.ptr(), 3, C.MPFR_RNDN)
+	C.mpfr_gamma(c2.ptr(), c2.ptr(), C.MPFR_RNDN)
+	C.mpfr_mul(c2.ptr(), c2.ptr(), t.ptr(), C.MPFR_RNDN)
+	C.mpfr_ui_div(c2.ptr(), 1, c2.ptr(), C.MPFR_RNDN)
+	C.mpfr_set_ui(c1.ptr(), 2, C.MPFR_RNDN)
+	C.mpfr_div_ui(c1.ptr(), c1.ptr(), 3, C.MPFR_RNDN)
+	C.mpfr_gamma(c1.ptr(), c1.ptr(), C.MPFR_RNDN)
+	C.mpfr_mul(c1.ptr(), c1.ptr(), t.ptr(), C.MPFR_RNDN)
+	C.mpfr_mul(c1.ptr(), c1.ptr(), t.ptr(), C.MPFR_RNDN)
+	C.mpfr_ui_div(c1.ptr(), 1, c1.ptr(), C.MPFR_RNDN)
+	C.mpc_mul_fr(&f.z[0], &f.z[0], c1.ptr(), C.MPC_RNDNN)
+	C.mpc_mul_fr(&g.z[0], &g.z[0], c2.ptr(), C.MPC_RNDNN)
+	if ai != nil {
+		ai.Sub(f, g, RNDNN)
+	}
+	if bi != nil {
+		C.mpfr_sqrt_ui(t.ptr(), 3, C.MPFR_RNDN)
+		bi.Add(f, g, RNDNN)
+		C.mpc_mul_fr(&bi.z[0], &bi.z[0], t.ptr(), C.MPC_RNDNN)
+	}
+}
+
+// airyAsymptotic sets s = Ai(z), or Ai′(z) if deriv is set, from
+//
+//	Ai(z) ~ e^(-ζ) / (2√π z^(1/4)) Σ (-1)^k u_k / ζ^k,
+//	Ai′(z) ~ -z^(1/4) e^(-ζ) / (2√π) Σ (-1)^k v_k / ζ^k,
+//
+// u_k = (6k-5)(6k-3)(6k-1) / ((2k-1) 216k) u_(k-1), u₀ = 1, and v_k = -(6k+1)/(6k-1) u_k.
+// They hold for |arg z| ≤ 2π/3 up to terms about e^(-2|ζ|) relative, which is also
+// where the terms stop shrinking, so the caller must make sure that is below 2^-wp.
+func airyAsymptotic(s, z *Complex, deriv bool, wp uint) {
+	zeta := airyZeta(z)
+	q, w, inv, t, v, sum := New(wp), New(wp), New(wp), New(wp).SetInt64(1, 0), New(wp), New(wp).SetInt64(1, 0)
+	defer q.Close()
+	defer w.Close()
+	defer inv.Close()
+	defer t.Close()
+	defer v.Close()
+	defer sum.Close()
+	// q = z^(1/4), w = ζ = (2/3) z √z
+	q.Sqrt(z, RNDNN)
+	w.Mul(z, q, RNDNN)
+	C.mpc_mul_2si(&w.z[0], &w.z[0], 1, C.MPC_RNDNN)
+	C.mpc_div_ui(&w.z[0], &w.z[0], 3, C.MPC_RNDNN)
+	q.Sqrt(q, RNDNN)
+	inv.Inv(w, RNDNN).Neg(inv, RNDNN)
+	for k := uint64(1); float64(k) < 2*zeta+2; k++ {
+		// t = (-1)^k u_k / ζ^k
+		t.Mul(t, inv, RNDNN)
+		C.mpc_mul_ui(&t.z[0], &t.z[0], C.ulong((6*k-5)*(6*k-3)*(6*k-1)), C.MPC_RNDNN)
+		C.mpc_div_ui(&t.z[0], &t.z[0], C.ulong((2*k-1)*216*k), C.MPC_RNDNN)
+		term := t
+		if deriv {
+			// (-1)^k v_k / ζ^k
+			C.mpc_mul_ui(&v.z[0], &t.z[0], C.ulong(6*k+1), C.MPC_RNDNN)
+			C.mpc_div_ui(&v.z[0], &v.z[0], C.ulong(6*k-1), C.MPC_RNDNN)
+			term = v.Neg(v, RNDNN)
+		}
+		sum.Add(sum, term, RNDNN)
+		if term.IsZero() || magExp(term) < magExp(sum)-int(wp) {
+			break
+		}
+	}
+	w.Neg(w, RNDNN).Exp(w, RNDNN)
+	sum.Mul(sum, w, RNDNN)
+	if deriv {
+		sum.Mul(sum, q, RNDNN).Neg(sum, RNDNN)
+	} else {
+		sum.Div(sum, q, RNDNN)
+	}
+	sp := NewReal(wp).SetConst(ConstPi)
+	defer sp.Close()
+	sp.Sqrt(sp, RNDN)
+	C.mpc_div_fr(&sum.z[0], &sum.z[0], sp.ptr(), C.MPC_RNDNN)
+	C.mpc_div_2si(&s.z[0], &sum.z[0], 1, C.MPC_RNDNN)
+}
+
+// rotateThird sets r = e^(iπk/3) for an integer k.
+func rotateThird(r *Complex, k int) {
+	s, c := math.Sincos(math.Pi * float64(k) / 3)
+	// cos is 0 or ±1/2 or ±1, sin is 0 or ±√3/2
+	C.mpfr_set_d(r.re(), C.double(math.Round(2*c)/2), C.MPFR_RNDN)
+	if s == 0 || math.Abs(s) < 0.5 {
+		C.mpfr_set_zero(r.im(), 1)
+		return
+	}
+	C.mpfr_sqrt_ui(r.im(), 3, C.MPFR_RNDN)
+	C.mpfr_div_2si(r.im(), r.im(), 1, C.MPFR_RNDN)
+	if s < 0 {
+		C.mpfr_neg(r.im(), r.im(), C.MPFR_RNDN)
+	}
+}
+
+// airyRotated sets s = e^(iπj/6) A(e^(2πik/3) z), A being Ai, or Ai′ if deriv is set,
+// using the asymptotic expansion if direct is set and airyAi otherwise.
+func airyRotated(s, z *Complex, deriv bool, k, j int, direct bool, wp uint) {
+	r, u := New(wp), New(wp)
+	defer r.Close()
+	defer u.Close()
+	rotateThird(r, 2*k)
+	u.Mul(z, r, RNDNN)
+	if direct {
+		airyAsymptotic(s, u, deriv, wp)
+	} else {
+		airyAi(s, u, deriv, wp)
+	}
+	// e^(iπj/6) = e^(iπ(j/2)/3) for even j, e^(iπ((j-3)/2)/3) i for odd j
+	if j%2 == 0 {
+		rotateThird(r, j/2)
+		s.Mul(s, r, RNDNN)
+		return
+	}
+	rotateThird(r, (j-3)/2)
+	s.Mul(s, r, RNDNN).MulI(s, 1, RNDNN)
+}
+
+// airyAi sets s = Ai(z), or Ai′(z) if deriv is set, at wp bits for finite z.
+func airyAi(s, z *Complex, deriv bool, wp uint) {
+	if !airyLarge(z, wp) {
+		airySeries(s, nil, z, deriv, wp)
+		return
+	}
+	v := z.Complex128()
+	if real(v) >= -0.5*cmplx.Abs(v) {
+		airyAsymptotic(s, z, deriv, wp)
+		return
+	}
+	// Ai(z) = -ω Ai(ωz) - ω² Ai(ω²z), Ai′(z) = -ω² Ai′(ωz) - ω Ai′(ω²z): e^(iπj/6) with
+	// j = 10 for -ω, 2 for -ω² and their derivative counterparts.
+	t := New(wp)
+	defer t.Close()
+	j1, j2 := 10, 2
+	if deriv {
+		j1, j2 = 2, 10
+	}
+	airyRotated(s, z, deriv, 1, j1, true, wp)
+	airyRotated(t, z, deriv, 2, j2, true, wp)
+	s.Add(s, t, RNDNN)
+}
+
+// airyBi sets s = Bi(z), or Bi′(z) if deriv is set, at wp bits for finite z. For large
+// |z| it is
+//
+//	Bi(z) = e^(iπ/6) Ai(ωz) + e^(-iπ/6) Ai(ω̄z),  Bi′(z) = e^(5iπ/6) Ai′(ωz) + e^(-5iπ/6) Ai′(ω̄z).
+func airyBi(s, z *Complex, deriv bool, wp uint) {
+	if !airyLarge(z, wp) {
+		airySeries(nil, s, z, deriv, wp)
+		return
+	}
+	t := New(wp)
+	defer t.Close()
+	j := 1
+	if deriv {
+		j = 5
+	}
+	airyRotated(s, z, deriv, 1, j, false, wp)
+	airyRotated(t, z, deriv, -1, -j, false, wp)
+	s.Add(s, t, RNDNN)
+}
+
+// setAirySpecial handles non-finite arguments: on the real axis the function tends to
+// pos at +∞ and to neg at -∞, NaN meaning it has no limit; anything else is NaN. It
+// reports whether c has been set.
+func (c *Complex) setAirySpecial(a *Complex, pos, neg float64) bool {
+	if C.mpfr_number_p(a.re()) != 0 && C.mpfr_number_p(a.im()) != 0 {
+		return false
+	}
+	lim := pos
+	if C.mpfr_sgn(a.re()) < 0 {
+		lim = neg
+	}
+	if C.mpfr_inf_p(a.re()) != 0 && C.mpfr_zero_p(a.im()) != 0 && !math.IsNaN(lim) {
+		C.mpfr_set_d(c.re(), C.double(lim), C.MPFR_RNDN)
+		C.mpfr_set_zero(c.im(), 1)
+	} else {
+		C.mpc_set_nan(&c.z[0])
+	}
+	c.inex = 0
+	return true
+}
+
+// airy sets c to the Airy function picked by bi and deriv at a and returns c.
+func (c *Complex) airy(a *Complex, bi, deriv bool, m RoundingMode) *Complex {
+	wp := airyWorkPrec(max(c.prec, a.prec), a)
+	s := New(wp)
+	defer s.Close()
+	if bi {
+		airyBi(s, a, deriv, wp)
+	} else {
+		airyAi(s, a, deriv, wp)
+	}
+	return c.setResult(s, a, m)
+}
+
+// Ai sets c = Ai(a), the Airy function of the first kind, and returns c. Ai is the
+// solution of w″ = zw that decays along the positive real axis.
+func (c *Complex) Ai(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if C.mpfr_zero_p(a.im()) != 0 && C.mpfr_cmp_d(a.re(), -aiRealLimit) >= 0 && C.mpfr_cmp_d(a.re(), aiRealLimit) <= 0 {
+		c.setRealAxis(a, m, func(x, y *C.__mpfr_struct, r C.mpfr_rnd_t) C.int { return C.mpfr_ai(x, y, r) })
+		return c
+	}
+	if c.setAirySpecial(a, 0, 0) {
+		return c
+	}
+	return c.airy(a, false, false, m)
+}
+
+// Bi sets c = Bi(a), the Airy function of the second kind, and returns c.
+func (c *Complex) Bi(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setAirySpecial(a, math.Inf(1), 0) {
+		return c
+	}
+	return c.airy(a, true, false, m)
+}
+
+// AiPrime sets c = Ai′(a) and returns c.
+func (c *Complex) AiPrime(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setAirySpecial(a, 0, math.NaN()) {
+		return c
+	}
+	return c.airy(a, false, true, m)
+}
+
+// BiPrime sets c = Bi′(a) and returns c.
+func (c *Complex) BiPrime(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setAirySpecial(a, math.Inf(1), math.NaN()) {
+		return c
+	}
+	return c.airy(a, true, true, m)
+}
+
+// airyZero sets z to the zero of Ai (bi = false) or Bi next to the guess z0 and returns
+// it, by Newton's method: to convergence at 64 bits, then one step per doubling of the
+// precision up to wp. A zero of a real guess is kept real.
+func airyZero(z0 complex128, bi bool, wp uint) *Complex {
+	onAxis := imag(z0) == 0
+	p := uint(64)
+	z := New(wp).SetComplex128(z0)
+	f, d := New(wp), New(wp)
+	defer f.Close()
+	defer d.Close()
+	step := func(p uint) {
+		q := airyWorkPrec(p, z)
+		if bi {
+			airyBi(f, z, false, q)
+			airyBi(d, z, true, q)
+		} else {
+			airyAi(f, z, false, q)
+			airyAi(d, z, true, q)
+		}
+		f.Div(f, d, RNDNN)
+		z.Sub(z, f, RNDNN)
+		if onAxis {
+			C.mpfr_set_zero(z.im(), 1)
+		}
+	}
+	for i := 0; i < 64; i++ {
+		step(p)
+		if f.IsZero() || magExp(f) < magExp(z)-int(p)+8 {
+			break
+		}
+	}
+	for p < wp {
+		p = min(2*p, wp)
+		step(p)
+	}
+	step(wp)
+	return z
+}
+
+// airyT returns T(t) = t^(2/3) (1 + 5/48 t⁻²), the start of the asymptotic expansion
+// of the zeros of Ai and Bi, accurate enough for Newton's method to take over.
+func airyT(t complex128) complex128 {
+	return cmplx.Pow(t, 2.0/3) * (1 + 5/(48*t*t))
+}
+
+// AiZero sets r to a_k, the k-th zero of Ai (k ≥ 1), and returns r. All zeros of Ai lie
+// on the negative real axis: a₁ = -2.3381…, a₂ = -4.0879…, …
+func (r *Real) AiZero(k int, mode ...Rnd) *Real {
+	if k < 1 {
+		r.SetNaN()
+		return r
+	}
+	wp := r.prec + gammaGuard
+	z := airyZero(-airyT(complex(3*math.Pi/8*float64(4*k-1), 0)), false, wp)
+	defer z.Close()
+	r.inex = C.mpfr_set(r.ptr(), z.re(), r.rnd(mode))
+	return r
+}
+
+// BiZero sets r to b_k, the k-th zero of Bi (k ≥ 1) on the negative real axis, and
+// returns r: b₁ = -1.1737…, b₂ = -3.2710…, … Bi has further zeros off the axis, given by
+// BiComplexZero.
+func (r *Real) BiZero(k int, mode ...Rnd) *Real {
+	if k < 1 {
+		r.SetNaN()
+		return r
+	}
+	wp := r.prec + gammaGuard
+	z := airyZero(-airyT(complex(3*math.Pi/8*float64(4*k-3), 0)), true, wp)
+	defer z.Close()
+	r.inex = C.mpfr_set(r.ptr(), z.re(), r.rnd(mode))
+	return r
+}
+
+// BiComplexZero sets c to β_k, the k-th zero of Bi (k ≥ 1) in the upper half-plane, and
+// returns c. These zeros lie close to the ray arg z = π/3, β₁ = 0.9775… + 2.1413…i;
+// their conjugates are the zeros in the lower half-plane.
+func (c *Complex) BiComplexZero(k int, mode ...RoundingMode) *Complex {
+	if k < 1 {
+		C.mpc_set_nan(&c.z[0])
+		c.inex = 0
+		return c
+	}
+	m := RoundingMode(c.rnd(mode))
+	wp := c.prec + gammaGuard
+	// β_k ~ e^(iπ/3) T(3π(4k-1)/8 + (3i/4) log 2)
+	t := complex(3*math.Pi/8*float64(4*k-1), 0.75*math.Ln2)
+	z := airyZero(cmplx.Rect(1, math.Pi/3)*airyT(t), true, wp)
+	defer z.Close()
+	c.inex = C.mpc_set(&c.z[0], &z.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// Non-mutating helpers
+func Ai(a *Complex, mode ...RoundingMode) *Complex      { return newLike(a).Ai(a, mode...) }
+func Bi(a *Complex, mode ...RoundingMode) *Complex      { return newLike(a).Bi(a, mode...) }
+func AiPrime(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).AiPrime(a, mode...) }
+func BiPrime(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).BiPrime(a, mode...) }
@@ -0,0 +1,167 @@
+package apcomplex
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestAiryKnown(t *testing.T) {
+	tol := MustParseReal("1e-19", 64)
+	tests := []struct {
+		name string
+		f    func(*Complex, ...RoundingMode) *Complex
+		z    string
+		want string
+	}{
+		{"Ai", Ai, "0", "0.3550280538878172392600631860041831763980"},
+		{"Bi", Bi, "0", "0.6149266274460007351509223690936135535947"},
+		{"Ai′", AiPrime, "0", "-0.2588194037928067984051835601892039634790"},
+		{"Bi′", BiPrime, "0", "0.4482883573538263579148237103988283908662"},
+		{"Bi", Bi, "1", "1.207423594952871259436378817028286907"},
+		{"Ai′", AiPrime, "1", "-0.159147441296793212783"},
+		{"Bi′", BiPrime, "1", "0.932435933392775632964"},
+	}
+	for _, tt := range tests {
+		got := tt.f(MustParse(tt.z, 128))
+		if !got.WithinAbs(MustParse(tt.want, 128), tol) || !got.Im().IsZero() {
+			t.Errorf("%s(%s) = %v, want %s", tt.name, tt.z, got, tt.want)
+		}
+	}
+	// Ai of real arguments up to aiRealLimit is correctly rounded by MPFR; just off the
+	// axis it still agrees.
+	for _, s := range []string{"-50", "-7.5", "3", "40"} {
+		x := MustParse(s, 200)
+		want := NewReal(200).Ai(x.Re())
+		if got := Ai(x); got.Re().Cmp(want) != 0 {
+			t.Errorf("Ai(%s) = %v, want %v", s, got, want)
+		}
+		got := Ai(Add(x, MustParse("1e-80i", 200)))
+		if !relClose(New(200).SetReals(got.Re(), nil), New(200).SetReals(want, nil), 12) {
+			t.Errorf("Ai(%s + 1e-80i) = %v, want %v", s, got, want)
+		}
+	}
+	// Past aiRealLimit the connection formula (left) and the asymptotic expansion
+	// (right) take over; the references are mpfr_ai's values.
+	far := []struct{ x, want string }{
+		{"-150", "4.903808270241090054437270040695134233795919043e-2"},
+		{"-1000", "5.597189577301991884219182675613447470377978906e-2"},
+		{"200", "9.153624308452684416581286152020470223014100459e-821"},
+	}
+	for _, tt := range far {
+		want := MustParse(tt.want, 160)
+		if got := Ai(MustParse(tt.x, 160)); !relClose(got, want, 16) || !got.Im().IsZero() {
+			t.Errorf("Ai(%s) = %v, want %v", tt.x, got, want)
+		}
+	}
+	specials := []struct {
+		f    func(*Complex, ...RoundingMode) *Complex
+		z    string
+		want complex128
+	}{
+		{Ai, "inf", 0}, {Ai, "-inf", 0}, {Bi, "inf", complex(math.Inf(1), 0)}, {Bi, "-inf", 0},
+		{AiPrime, "inf", 0}, {BiPrime, "inf", complex(math.Inf(1), 0)},
+	}
+	for i, tt := range specials {
+		if got := tt.f(MustParse(tt.z, 64)).Complex128(); got != tt.want {
+			t.Errorf("%d: f(%s) = %v, want %v", i, tt.z, got, tt.want)
+		}
+	}
+	if got := AiPrime(MustParse("-inf", 64)); !got.IsNaN() {
+		t.Errorf("Ai′(-∞) = %v", got)
+	}
+}
+
+func TestAiryIdentities(t *testing.T) {
+	// The points cover the series and the asymptotic expansions at both precisions, on
+	// both sides of the Stokes lines arg z = ±2π/3 and on the negative real axis.
+	points := []string{"0.3+0.2i", "-2+1i", "6-4i", "30+1i", "-30+2i", "-20-25i", "10+17i", "-14+24.5i", "-14+23.5i", "-45+1e-10i", "0.5+60i"}
+	for _, prec := range []uint{64, 300} {
+		one := MustParse("1", prec)
+		pi := Pi(prec)
+		// ω = e^(2πi/3)
+		w := Exp(Div(Mul(pi, MustParse("2i", prec)), MustParse("3", prec)))
+		w2 := Sqr(w)
+		for _, s := range points {
+			z := MustParse(s, prec)
+			ai, bi, aip, bip := Ai(z), Bi(z), AiPrime(z), BiPrime(z)
+			// π (Ai Bi′ - Ai′ Bi) = 1
+			a := Mul(ai, bip)
+			wr := Mul(Sub(a, Mul(aip, bi)), pi)
+			if !absClose(wr, one, Mul(a, pi), 12) {
+				t.Errorf("%d bits: πW(%s) = %v", prec, s, wr)
+			}
+			// Ai(z) + ω Ai(ωz) + ω² Ai(ω²z) = 0
+			a, b := Mul(w, Ai(Mul(w, z))), Mul(w2, Ai(Mul(w2, z)))
+			scale := a
+			if ai.CmpAbs(a) > 0 {
+				scale = ai
+			}
+			if sum := Add(ai, Add(a, b)); !absClose(sum, New(prec).SetInt64(0, 0), scale, 12) {
+				t.Errorf("%d bits: connection at %s = %v", prec, s, sum)
+			}
+			if got, want := Ai(Conj(z)), Conj(ai); !got.Equal(want) {
+				t.Errorf("%d bits: Ai(conj %s) = %v, want %v", prec, s, got, want)
+			}
+		}
+	}
+	// The same values from different algorithms at different precisions.
+	for _, s := range points {
+		lo, hi := MustParse(s, 64), MustParse(s, 400)
+		for name, f := range map[string]func(*Complex, ...RoundingMode) *Complex{"Ai": Ai, "Bi": Bi, "Ai′": AiPrime, "Bi′": BiPrime} {
+			want := New(64).Set(f(hi))
+			if got := f(lo); !relClose(got, want, 4) {
+				t.Errorf("%s(%s) = %v at 64 bits, %v at 400", name, s, got, want)
+			}
+		}
+	}
+}
+
+func TestAiryZeros(t *testing.T) {
+	zeros := []struct {
+		f    func(*Real, int, ...Rnd) *Real
+		k    int
+		want string
+	}{
+		{(*Real).AiZero, 1, "-2.338107410459767038489197252446735440638"},
+		{(*Real).AiZero, 2, "-4.087949444130970616636988701457391060224"},
+		{(*Real).BiZero, 1, "-1.173713222709127924919979962473902104544"},
+		{(*Real).BiZero, 2, "-3.271093302836352715680228240166413806300"},
+	}
+	tol := MustParseReal("1e-38", 64)
+	for _, tt := range zeros {
+		got := tt.f(NewReal(160), tt.k)
+		if d := NewReal(160).Sub(got, MustParseReal(tt.want, 160)); d.CmpAbs(tol) > 0 {
+			t.Errorf("zero %d = %v, want %s", tt.k, got, tt.want)
+		}
+	}
+	// Far out the zeros approach -T(3π(4k-1)/8) with T(t) ~ t^(2/3) (1 + 5/48 t⁻²).
+	for _, prec := range []uint{64, 256} {
+		a := NewReal(prec).AiZero(50)
+		if z := Ai(New(prec).SetReals(a, nil)); z.AbsFloat64() > math.Ldexp(1, 8-int(prec)) {
+			t.Errorf("%d bits: Ai(a₅₀) = %v", prec, z)
+		}
+		x := 3 * math.Pi / 8 * 199
+		if d := a.Float64() + math.Pow(x, 2.0/3)*(1+5/(48*x*x)); math.Abs(d) > 1e-8 {
+			t.Errorf("%d bits: a₅₀ = %v", prec, a)
+		}
+		for k := 1; k <= 3; k++ {
+			b := New(prec).BiComplexZero(k)
+			if z := Bi(b); z.AbsFloat64() > math.Ldexp(BiPrime(b).AbsFloat64(), 8-int(prec)) || b.Im().Sign() <= 0 {
+				t.Errorf("%d bits: Bi(β_%d = %v) = %v", prec, k, b, z)
+			}
+		}
+	}
+	if got := NewReal(64).AiZero(0); !got.IsNaN() {
+		t.Errorf("a₀ = %v", got)
+	}
+}
+
+func ExampleAi() {
+	z := MustParse("1+i", 64)
+	fmt.Println(Ai(z).StringFixed(15))
+	fmt.Println(NewReal(64).AiZero(1).Text('f', 15))
+	// Output:
+	// 0.060458308371838-0.151889565877181i
+	// -2.338107410459767
+}
@@ -95,8 +95,8 @@ import (
 	"unsafe"
 )
 
-// default rounding mode (nearest, nearest)
-var defaultRnd = C.mpc_rnd_t(C.MPC_RNDNN)
+// DefaultPrec is the precision used when 0 bits are requested. It is process-wide;
+// use a Context to give different parts of a program their own precision.
 var DefaultPrec uint = 256
 
 // Complex is an arbitrary-precision complex backed by GNU MPC/MPFR.
@@ -104,6 +104,8 @@ var DefaultPrec uint = 256
 type Complex struct {
 	z    C.mpc_t
 	prec uint
+	mode RoundingMode
+	inex C.int // ternary value of the last operation (MPC_INEX encoding)
 	init bool
 	once sync.Once
 }
@@ -120,6 +122,21 @@ func New(bits uint) *Complex {
 	return c
 }
 
+// lazyInit initializes a zero Complex (as used by Scan and the decoders) with the given
+// precision, DefaultPrec if bits==0. The value is not registered with a finalizer since
+// it may live inside another object.
+func (c *Complex) lazyInit(bits uint) {
+	if c.init || c.prec != 0 {
+		return
+	}
+	if bits == 0 {
+		bits = DefaultPrec
+	}
+	c.prec = bits
+	C.mpc_init2(&c.z[0], C.mpfr_prec_t(c.prec))
+	c.init = true
+}
+
 // Close frees C resources.
 func (c *Complex) Close() {
 	if c == nil {
@@ -136,6 +153,10 @@ func (c *Complex) Close() {
 // Prec returns precision in bits.
 func (c *Complex) Prec() uint { return c.prec }
 
+// re/im return the MPFR parts of c (mpc_realref/mpc_imagref are macros cgo can't call).
+func (c *Complex) re() *C.__mpfr_struct { return &c.z[0].re[0] }
+func (c *Complex) im() *C.__mpfr_struct { return &c.z[0].im[0] }
+
 // SetPrec changes precision (rounding value to the new precision).
 func (c *Complex) SetPrec(bits uint) *Complex {
 	if !c.init {
@@ -152,24 +173,31 @@ func (c *Complex) SetPrec(bits uint) *Complex {
 	return c
 }
 
-// Clone returns a deep copy.
+// Clone returns a deep copy (including the rounding mode).
 func (c *Complex) Clone() *Complex {
 	out := New(c.prec)
-	C.mpc_set(&out.z[0], &c.z[0], defaultRnd)
+	out.mode = c.mode
+	out.inex = C.mpc_set(&out.z[0], &c.z[0], c.mode.mpc())
 	return out
 }
 
-// Parse parses a complex literal at given precision. Accepts:
+// newLike allocates a result for a non-mutating wrapper: a's precision and rounding mode.
+func newLike(a *Complex) *Complex { return New(a.prec).SetMode(a.mode) }
+
+// Parse parses a complex literal at the given precision, leniently (see ParseOptions
+// for the strict syntax). Accepted forms include
 //
-//	"a+bi", "a-bi", "i", "-i", plain real "a", or MPC form "(a b)" / "(a, b)".
-func Parse(s string, prec uint) (*Complex, error) {
-	z := New(prec)
-	if err := z.SetString(s); err != nil {
-		z.Close()
-		return nil, err
-	}
-	return z, nil
-}
+//	1.5-2i  -i  2.5e-3i  3j  2*i  i*2   cartesian, with i or j as the unit
+//	(1.5 -2)  (1.5, -2)                 MPC form
+//	5∠30°  5∠0.5236                     polar, the angle in radians unless marked °
+//	2·e^{i0.5}  2*exp(0.5i)  e^(i*0.5)  exponential polar form
+//	0x1.8p3+0x1p-2i  0b101  0o17        hexadecimal (p: binary exponent), binary, octal
+//	inf  -inf  nan  nan+infi            special values, also written @inf@ and @nan@
+//
+// Whitespace may surround signs, operators and parentheses, and a run of signs acts
+// as one ("1+-2i" is 1-2i). The empty string is zero. A malformed literal is reported
+// as a *ParseError with the offset of the offending byte.
+func Parse(s string, prec uint) (*Complex, error) { return ParseWith(s, prec, ParseOptions{}) }
 
 // MustParse panics on error.
 func MustParse(s string, prec uint) *Complex {
@@ -180,20 +208,31 @@ func MustParse(s string, prec uint) *Complex {
 	return z
 }
 
-// SetString sets c from a complex string (see Parse).
-func (c *Complex) SetString(s string) error {
-	if !c.init {
-		return errors.New("apcomplex: not initialized")
+// ParseBase is like Parse with the numbers written in the given base (2..62), as
+// produced by StringBase. Exponents are written with '@' (any base), 'e' (base <= 10)
+// or 'p' (binary exponent, bases 2 and 16). Above base 18 the letter i is also a digit,
+// and a final i is taken as the unit; above base 36 only a lowercase i is the unit.
+func ParseBase(s string, base int, prec uint) (*Complex, error) {
+	if base < 2 || base > 62 {
+		return nil, fmt.Errorf("apcomplex: invalid base %d", base)
 	}
-	re, im, ok := normalizeToPair(s)
-	if !ok {
-		return fmt.Errorf("apcomplex: invalid complex literal %q", s)
+	return ParseWith(s, prec, ParseOptions{Base: base})
+}
+
+// SetString sets c from a complex string (see Parse).
+func (c *Complex) SetString(s string) error { return c.SetStringWith(s, ParseOptions{}) }
+
+// SetStringBase sets c from a complex string in the given base (see ParseBase).
+func (c *Complex) SetStringBase(s string, base int) error {
+	if base < 2 || base > 62 {
+		return fmt.Errorf("apcomplex: invalid base %d", base)
 	}
-	return c.SetBase(re, im, 10)
+	return c.SetStringWith(s, ParseOptions{Base: base})
 }
 
 // SetBase sets c = re + i*im, parsing both parts using the given base (<=0 defaults to 10).
-func (c *Complex) SetBase(re, im string, base int) error {
+// Each part is rounded according to c's rounding mode unless a mode is passed explicitly.
+func (c *Complex) SetBase(re, im string, base int, mode ...RoundingMode) error {
 	if !c.init {
 		return errors.New("apcomplex: not initialized")
 	}
@@ -212,70 +251,38 @@ func (c *Complex) SetBase(re, im string, base int) error {
 	if base <= 0 {
 		b = 10
 	}
-	if C.mpfr_set_str(&r[0], cr, b, C.MPFR_RNDN) != 0 {
+	// mpfr_strtofr (unlike mpfr_set_str) reports the ternary value, so the parse
+	// rounding shows up in Acc.
+	m := RoundingMode(c.rnd(mode))
+	var end *C.char
+	tr := C.mpfr_strtofr(&r[0], cr, &end, b, m.Re().mpfr())
+	if *cr == 0 || *end != 0 {
 		return fmt.Errorf("apcomplex: invalid real part %q", re)
 	}
-	if C.mpfr_set_str(&i[0], ci, b, C.MPFR_RNDN) != 0 {
+	ti := C.mpfr_strtofr(&i[0], ci, &end, b, m.Im().mpfr())
+	if *ci == 0 || *end != 0 {
 		return fmt.Errorf("apcomplex: invalid imaginary part %q", im)
 	}
-	C.mpc_set_fr_fr(&c.z[0], &r[0], &i[0], defaultRnd)
+	C.mpc_set_fr_fr(&c.z[0], &r[0], &i[0], m.mpc()) // exact: same precision
+	c.inex = inexOf(tr, ti)
 	return nil
 }
 
 // SetParts sets c = re + i*im using base-10 strings.
 func (c *Complex) SetParts(re, im string) error { return c.SetBase(re, im, 10) }
 
-// normalizeToPair converts common forms into separate real/imag strings.
-func normalizeToPair(in string) (string, string, bool) {
-	s := strings.TrimSpace(in)
-	if s == "" {
-		return "0", "0", true
-	}
-	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
-		mid := strings.TrimSpace(s[1 : len(s)-1])
-		mid = strings.ReplaceAll(mid, ",", " ")
-		f := strings.Fields(mid)
-		if len(f) == 1 {
-			return f[0], "0", true
-		}
-		if len(f) >= 2 {
-			return f[0], f[1], true
-		}
-		return "", "", false
-	}
-	s = strings.ReplaceAll(s, "I", "i")
-	if s == "i" || s == "+i" {
-		return "0", "1", true
-	}
-	if s == "-i" {
-		return "0", "-1", true
-	}
-	if strings.HasSuffix(s, "i") {
-		core := strings.TrimSpace(s[:len(s)-1])
-		idx := lastSignNotInExponent(core)
-		if idx > 0 {
-			re := strings.TrimSpace(core[:idx])
-			im := strings.TrimSpace(core[idx:])
-			if im == "+" || im == "-" {
-				return re, "0", true
-			}
-			return re, im, true
-		}
-		return "0", core, true
-	}
-	return s, "0", true
-}
-
-// lastSignNotInExponent finds last '+'/'-' not part of an exponent and not at position 0.
-func lastSignNotInExponent(s string) int {
-	for i := len(s) - 1; i > 0; i-- {
-		if s[i] == '+' || s[i] == '-' {
-			if s[i-1] != 'e' && s[i-1] != 'E' {
-				return i
-			}
-		}
-	}
-	return -1
+// isExponentMark reports whether b introduces an exponent in base: '@' in any base,
+// 'e'/'E' up to base 10 and 'p'/'P' in bases 2 and 16.
+func isExponentMark(b byte, base int) bool {
+	switch b {
+	case '@':
+		return true
+	case 'e', 'E':
+		return base <= 10
+	case 'p', 'P':
+		return base == 2 || base == 16
+	}
+	return false
 }
 
 // Formatting
@@ -339,116 +346,203 @@ func (c *Complex) ImagStringFixed(digits int) string {
 	return C.GoString(p)
 }
 
-// Algebraic ops (mutating; return receiver for chaining)
-func (c *Complex) Set(a *Complex) *Complex { C.mpc_set(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Add(a, b *Complex) *Complex {
-	C.mpc_add(&c.z[0], &a.z[0], &b.z[0], defaultRnd)
+// mpfrStringFixed/mpfrStringSci format a single MPFR value like the Complex formatters.
+func mpfrStringFixed(x *C.__mpfr_struct, digits int) string {
+	if digits < 0 {
+		digits = 0
+	}
+	return goStringFree(C.apc_mpfr_to_str_fixed(x, C.int(digits)))
+}
+
+func mpfrStringSci(x *C.__mpfr_struct, digits int) string {
+	if digits < 1 {
+		digits = 1
+	}
+	return goStringFree(C.apc_mpfr_to_str_sci(x, C.int(digits)))
+}
+
+func goStringFree(p *C.char) string {
+	if p == nil {
+		return "<oom>"
+	}
+	defer C.free(unsafe.Pointer(p))
+	return C.GoString(p)
+}
+
+// Algebraic ops (mutating; return receiver for chaining). Each accepts an optional
+// RoundingMode overriding c's own mode for that call only.
+func (c *Complex) Set(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_set(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Add(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_add(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Sub(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_sub(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
 	return c
 }
-func (c *Complex) Sub(a, b *Complex) *Complex {
-	C.mpc_sub(&c.z[0], &a.z[0], &b.z[0], defaultRnd)
+func (c *Complex) Mul(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_mul(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
 	return c
 }
-func (c *Complex) Mul(a, b *Complex) *Complex {
-	C.mpc_mul(&c.z[0], &a.z[0], &b.z[0], defaultRnd)
+func (c *Complex) Div(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_div(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
 	return c
 }
-func (c *Complex) Div(a, b *Complex) *Complex {
-	C.mpc_div(&c.z[0], &a.z[0], &b.z[0], defaultRnd)
+func (c *Complex) Neg(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_neg(&c.z[0], &a.z[0], c.rnd(mode))
 	return c
 }
-func (c *Complex) Neg(a *Complex) *Complex  { C.mpc_neg(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Conj(a *Complex) *Complex { C.mpc_conj(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Inv(a *Complex) *Complex {
-	// c = 1 / a
-	C.mpc_set_ui_ui(&c.z[0], 1, 0, defaultRnd)
-	C.mpc_div(&c.z[0], &c.z[0], &a.z[0], defaultRnd)
+func (c *Complex) Conj(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_conj(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Inv(a *Complex, mode ...RoundingMode) *Complex {
+	// c = 1 / a (computed as ui/a so 1 is never rounded and a may alias c)
+	c.inex = C.mpc_ui_div(&c.z[0], 1, &a.z[0], c.rnd(mode))
 	return c
 }
 
 // Elementary/transcendental
-func (c *Complex) Sqrt(a *Complex) *Complex { C.mpc_sqrt(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Exp(a *Complex) *Complex  { C.mpc_exp(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Log(a *Complex) *Complex  { C.mpc_log(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Pow(a, b *Complex) *Complex {
-	C.mpc_pow(&c.z[0], &a.z[0], &b.z[0], defaultRnd)
+func (c *Complex) Sqrt(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_sqrt(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Exp(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_exp(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Log(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_log(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Pow(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_pow(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
 	return c
 }
 
-func (c *Complex) Sin(a *Complex) *Complex  { C.mpc_sin(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Cos(a *Complex) *Complex  { C.mpc_cos(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Tan(a *Complex) *Complex  { C.mpc_tan(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Asin(a *Complex) *Complex { C.mpc_asin(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Acos(a *Complex) *Complex { C.mpc_acos(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Atan(a *Complex) *Complex { C.mpc_atan(&c.z[0], &a.z[0], defaultRnd); return c }
+func (c *Complex) Sin(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_sin(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Cos(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_cos(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Tan(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_tan(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Asin(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_asin(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Acos(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_acos(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Atan(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_atan(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
 
-func (c *Complex) Sinh(a *Complex) *Complex  { C.mpc_sinh(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Cosh(a *Complex) *Complex  { C.mpc_cosh(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Tanh(a *Complex) *Complex  { C.mpc_tanh(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Asinh(a *Complex) *Complex { C.mpc_asinh(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Acosh(a *Complex) *Complex { C.mpc_acosh(&c.z[0], &a.z[0], defaultRnd); return c }
-func (c *Complex) Atanh(a *Complex) *Complex { C.mpc_atanh(&c.z[0], &a.z[0], defaultRnd); return c }
+func (c *Complex) Sinh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_sinh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Cosh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_cosh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Tanh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_tanh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Asinh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_asinh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Acosh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_acosh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+func (c *Complex) Atanh(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_atanh(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
 
 // Magnitude/argument as strings (computed with MPFR real temporaries)
 func (c *Complex) AbsStringFixed(a *Complex, digits int) string {
-	if digits < 0 {
-		digits = 0
-	}
-	var r C.mpfr_t
-	C.mpfr_init2(&r[0], C.mpfr_prec_t(c.prec))
-	defer C.mpfr_clear(&r[0])
-	C.mpc_abs(&r[0], &a.z[0], C.MPFR_RNDN)
-	p := C.apc_mpfr_to_str_fixed(&r[0], C.int(digits))
-	if p == nil {
-		return "<oom>"
-	}
-	defer C.free(unsafe.Pointer(p))
-	return C.GoString(p)
+	r := NewReal(c.prec).SetMode(c.mode.Re())
+	defer r.Close()
+	return r.AbsOf(a).StringFixed(digits)
 }
 
 func (c *Complex) ArgStringScientific(a *Complex, digits int) string {
-	if digits < 1 {
-		digits = 1
-	}
+	r := NewReal(c.prec).SetMode(c.mode.Re())
+	defer r.Close()
+	return r.ArgOf(a).StringScientific(digits)
+}
+
+// Abs returns |c| as a new Real with c's precision, rounded with the real part's mode.
+func (c *Complex) Abs() *Real { return NewReal(c.prec).SetMode(c.mode.Re()).AbsOf(c) }
+
+// Arg returns the principal argument of c in (-pi, pi] as a new Real with c's precision.
+func (c *Complex) Arg() *Real { return NewReal(c.prec).SetMode(c.mode.Re()).ArgOf(c) }
+
+// Norm returns |c|^2 (mpc_norm) as a new Real with c's precision.
+func (c *Complex) Norm() *Real { return NewReal(c.prec).SetMode(c.mode.Re()).NormOf(c) }
+
+// AbsFloat64, ArgFloat64 and NormFloat64 round the result directly to float64 (no
+// intermediate at c's precision), which is cheap enough for convergence tests.
+func (c *Complex) AbsFloat64() float64 {
+	return c.float64Of(func(r *C.__mpfr_struct) { C.mpc_abs(r, &c.z[0], C.MPFR_RNDN) })
+}
+func (c *Complex) ArgFloat64() float64 {
+	return c.float64Of(func(r *C.__mpfr_struct) { C.mpc_arg(r, &c.z[0], C.MPFR_RNDN) })
+}
+func (c *Complex) NormFloat64() float64 {
+	return c.float64Of(func(r *C.__mpfr_struct) { C.mpc_norm(r, &c.z[0], C.MPFR_RNDN) })
+}
+
+func (c *Complex) float64Of(f func(r *C.__mpfr_struct)) float64 {
 	var r C.mpfr_t
-	C.mpfr_init2(&r[0], C.mpfr_prec_t(c.prec))
+	C.mpfr_init2(&r[0], 53)
 	defer C.mpfr_clear(&r[0])
-	C.mpc_arg(&r[0], &a.z[0], C.MPFR_RNDN)
-	p := C.apc_mpfr_to_str_sci(&r[0], C.int(digits))
-	if p == nil {
-		return "<oom>"
-	}
-	defer C.free(unsafe.Pointer(p))
-	return C.GoString(p)
+	f(&r[0])
+	return float64(C.mpfr_get_d(&r[0], C.MPFR_RNDN))
 }
 
-// Non-mutating convenience wrappers
-func Add(a, b *Complex) *Complex { return New(a.prec).Add(a, b) }
-func Sub(a, b *Complex) *Complex { return New(a.prec).Sub(a, b) }
-func Mul(a, b *Complex) *Complex { return New(a.prec).Mul(a, b) }
-func Div(a, b *Complex) *Complex { return New(a.prec).Div(a, b) }
-func Neg(a *Complex) *Complex    { return New(a.prec).Neg(a) }
-func Conj(a *Complex) *Complex   { return New(a.prec).Conj(a) }
-func Inv(a *Complex) *Complex    { return New(a.prec).Inv(a) }
-func Sqrt(a *Complex) *Complex   { return New(a.prec).Sqrt(a) }
-func Exp(a *Complex) *Complex    { return New(a.prec).Exp(a) }
-func Log(a *Complex) *Complex    { return New(a.prec).Log(a) }
-func Pow(a, b *Complex) *Complex {
+// Non-mutating convenience wrappers. The result takes the precision and rounding mode
+// of the first operand; an explicit mode overrides the rounding for that call.
+func Add(a, b *Complex, mode ...RoundingMode) *Complex { return newLike(a).Add(a, b, mode...) }
+func Sub(a, b *Complex, mode ...RoundingMode) *Complex { return newLike(a).Sub(a, b, mode...) }
+func Mul(a, b *Complex, mode ...RoundingMode) *Complex { return newLike(a).Mul(a, b, mode...) }
+func Div(a, b *Complex, mode ...RoundingMode) *Complex { return newLike(a).Div(a, b, mode...) }
+func Neg(a *Complex, mode ...RoundingMode) *Complex    { return newLike(a).Neg(a, mode...) }
+func Conj(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Conj(a, mode...) }
+func Inv(a *Complex, mode ...RoundingMode) *Complex    { return newLike(a).Inv(a, mode...) }
+func Sqrt(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Sqrt(a, mode...) }
+func Exp(a *Complex, mode ...RoundingMode) *Complex    { return newLike(a).Exp(a, mode...) }
+func Log(a *Complex, mode ...RoundingMode) *Complex    { return newLike(a).Log(a, mode...) }
+func Pow(a, b *Complex, mode ...RoundingMode) *Complex {
 	p := a.prec
 	if b.prec > p {
 		p = b.prec
 	}
-	return New(p).Pow(a, b)
-}
-func Sin(a *Complex) *Complex   { return New(a.prec).Sin(a) }
-func Cos(a *Complex) *Complex   { return New(a.prec).Cos(a) }
-func Tan(a *Complex) *Complex   { return New(a.prec).Tan(a) }
-func Asin(a *Complex) *Complex  { return New(a.prec).Asin(a) }
-func Acos(a *Complex) *Complex  { return New(a.prec).Acos(a) }
-func Atan(a *Complex) *Complex  { return New(a.prec).Atan(a) }
-func Sinh(a *Complex) *Complex  { return New(a.prec).Sinh(a) }
-func Cosh(a *Complex) *Complex  { return New(a.prec).Cosh(a) }
-func Tanh(a *Complex) *Complex  { return New(a.prec).Tanh(a) }
-func Asinh(a *Complex) *Complex { return New(a.prec).Asinh(a) }
-func Acosh(a *Complex) *Complex { return New(a.prec).Acosh(a) }
-func Atanh(a *Complex) *Complex { return New(a.prec).Atanh(a) }
+	return New(p).SetMode(a.mode).Pow(a, b, mode...)
+}
+func Sin(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Sin(a, mode...) }
+func Cos(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Cos(a, mode...) }
+func Tan(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Tan(a, mode...) }
+func Asin(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Asin(a, mode...) }
+func Acos(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Acos(a, mode...) }
+func Atan(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Atan(a, mode...) }
+func Sinh(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Sinh(a, mode...) }
+func Cosh(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Cosh(a, mode...) }
+func Tanh(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Tanh(a, mode...) }
+func Asinh(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).Asinh(a, mode...) }
+func Acosh(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).Acosh(a, mode...) }
+func Atanh(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).Atanh(a, mode...) }
@@ -0,0 +1,807 @@
+// Package bessel provides the Bessel functions J, Y, the modified Bessel functions I, K
+// and the Hankel functions H¹, H² of complex order and complex argument, on top of
+// apcomplex.Complex.
+//
+// Each function picks its algorithm from |z|, |ν| and the precision:
+//
+//   - for small and moderate |z| the power series, summed with extra bits for the
+//     cancellation among its terms; Y and K come from J and I by the reflection formulas,
+//     or from their logarithmic series when ν is an integer;
+//   - for large |z| the asymptotic expansions of the Hankel functions, which every other
+//     function is expressed through, continued across the imaginary axis by the
+//     analytic continuation formulas;
+//   - when |ν| is too large for those expansions but still below |z|, the expansions at
+//     the fractional order followed by the three-term recurrence up to ν.
+//
+// Results carry the larger precision of ν and z. None is correctly rounded, real
+// arguments included: each comes from a working precision with guard bits for the
+// cancellation in its sums and is rounded once, so the error is a few ulps of the
+// largest quantity combined. Where a function oscillates, as J and Y do along the real
+// axis, that is its envelope rather than its value, and relative accuracy drops next to
+// its zeros. Branches are principal: the functions other than J and I of integer order
+// are cut along the negative real axis, where the sign of the zero imaginary part of z
+// selects the side.
+//
+// The result is NaN where the work would have no practical bound: for |z| ≥ 2^16384,
+// for |ν| > 2^20, and where ν and z are both large and so close that the sums would need
+// more than 2^16 extra bits.
+//
+// SPDX-License-Identifier: MIT
+package bessel
+
+import (
+	"math"
+	"math/cmplx"
+
+	apc "github.com/lukaszgryglicki/apcomplex"
+)
+
+// guard is the number of guard bits of the working precision.
+const guard = 32
+
+// Bounds on the arguments and the work. Past maxMagBits bits of |z| or maxOrder for |ν|
+// the phases and recurrences outgrow any practical precision, and no sum adds more than
+// maxExtra bits to the working precision for the cancellation among its terms; the
+// result is then NaN.
+const (
+	maxMagBits = 1 << 14
+	maxOrder   = 1 << 20
+	maxExtra   = 1 << 16
+)
+
+// J returns the Bessel function of the first kind J_ν(z).
+func J(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindJ)
+	if done {
+		return res
+	}
+	r := jAt(nu, z, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// Y returns the Bessel function of the second kind Y_ν(z).
+func Y(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindY)
+	if done {
+		return res
+	}
+	r := yAt(nu, z, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// I returns the modified Bessel function of the first kind I_ν(z).
+func I(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindI)
+	if done {
+		return res
+	}
+	r := iAt(nu, z, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// K returns the modified Bessel function of the second kind K_ν(z).
+func K(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindK)
+	if done {
+		return res
+	}
+	r := kAt(nu, z, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// H1 returns the Hankel function of the first kind H¹_ν(z) = J_ν(z) + iY_ν(z).
+func H1(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindH)
+	if done {
+		return res
+	}
+	r := hankelAt(nu, z, 1, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// H2 returns the Hankel function of the second kind H²_ν(z) = J_ν(z) - iY_ν(z).
+func H2(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex {
+	res, wp, done := prepare(nu, z, kindH)
+	if done {
+		return res
+	}
+	r := hankelAt(nu, z, 2, wp)
+	defer r.Close()
+	return res.Set(r, mode...)
+}
+
+// kind tells prepare how a function behaves at z = 0.
+type kind int
+
+const (
+	kindJ kind = iota // J and I: 1 for ν = 0, 0 for Re ν > 0 and integer ν
+	kindY             // Y: -∞ for real ν ≥ 0, ±∞ or 0 for real ν < 0
+	kindI
+	kindK // +∞ for real ν
+	kindH // H¹ and H²
+)
+
+// prepare allocates the result for ν and z and returns the working precision. It handles
+// non-finite arguments and those past maxMagBits and maxOrder (NaN) and z = 0 itself,
+// reporting done when the result is set.
+func prepare(nu, z *apc.Complex, k kind) (res *apc.Complex, wp uint, done bool) {
+	prec := max(nu.Prec(), z.Prec())
+	res = apc.New(prec).SetMode(z.Mode())
+	if !nu.IsFinite() || !z.IsFinite() {
+		res.SetFloat64(math.NaN(), math.NaN())
+		return res, 0, true
+	}
+	if z.IsZero() {
+		setZeroLimit(res, nu, k)
+		return res, 0, true
+	}
+	zb := magBits(z)
+	if zb > maxMagBits || nu.AbsFloat64() > maxOrder {
+		res.SetFloat64(math.NaN(), math.NaN())
+		return res, 0, true
+	}
+	wp = prec + guard + zb + magBits(nu)
+	return res, wp, false
+}
+
+// magBits returns ⌈log₂(1+|x|)⌉, computed from a Real so that it stays finite past the
+// float64 range.
+func magBits(x *apc.Complex) uint {
+	r := apc.NewReal(64).AbsOf(x)
+	defer r.Close()
+	one := apc.NewReal(64).SetInt64(1)
+	defer one.Close()
+	return uint(math.Ceil(r.Add(r, one).Log2(r).Float64()))
+}
+
+// setZeroLimit sets res to the limit at z = 0. For Re ν = 0, ν ≠ 0, the functions
+// carry (z/2)^(±ν), which circles without a limit, so the result is NaN. The limits that
+// are infinite for complex ν are +∞, the point at infinity; for real ν they have
+// signs, Y_ν(z) ~ -cos(νπ) Γ(-ν) (2/z)^(-ν) / π for ν < 0 among them, which
+// vanishes at the half-integers, where Y_ν = ±J_(-ν).
+func setZeroLimit(res, nu *apc.Complex, k kind) {
+	re := reSign(nu)
+	switch {
+	case nu.IsZero() && (k == kindJ || k == kindI):
+		res.SetInt64(1, 0)
+	case re == 0 && !nu.IsZero():
+		res.SetFloat64(math.NaN(), math.NaN())
+	case k == kindJ || k == kindI:
+		if re > 0 || isInt(nu) {
+			res.SetInt64(0, 0)
+		} else {
+			res.SetFloat64(math.Inf(1), 0)
+		}
+	case k == kindY && nu.IsReal():
+		switch c := cosPiSign(nu); {
+		case re >= 0:
+			res.SetFloat64(math.Inf(-1), 0)
+		case c == 0:
+			res.SetInt64(0, 0)
+		default:
+			res.SetFloat64(math.Inf(-c), 0)
+		}
+	default:
+		res.SetFloat64(math.Inf(1), 0)
+	}
+}
+
+// cosPiSign returns the sign of cos(νπ) for real ν, exactly: ν = n + d with n the
+// nearest integer and |d| ≤ 1/2, and cos(νπ) = (-1)ⁿ cos(dπ).
+func cosPiSign(nu *apc.Complex) int {
+	prec := nu.Prec()
+	re := nu.Re()
+	defer re.Close()
+	n := nearestInt(nu, prec)
+	defer n.Close()
+	half := apc.NewReal(prec).SetFloat64(0.5)
+	defer half.Close()
+	d := apc.NewReal(prec).Sub(re, n)
+	defer d.Close()
+	if d.CmpAbs(half) == 0 {
+		return 0
+	}
+	if d.Mul(n, half).IsInt() {
+		return 1
+	}
+	return -1
+}
+
+// reSign returns the sign of the real part of x.
+func reSign(x *apc.Complex) int {
+	re := x.Re()
+	defer re.Close()
+	return re.Sign()
+}
+
+// isInt reports whether ν is a real integer.
+func isInt(nu *apc.Complex) bool {
+	if !nu.IsReal() {
+		return false
+	}
+	re := nu.Re()
+	defer re.Close()
+	return re.IsInt()
+}
+
+// intOrder returns ν as an int; ν must satisfy isInt.
+func intOrder(nu *apc.Complex) int {
+	re := nu.Re()
+	defer re.Close()
+	return int(re.Int64())
+}
+
+// widen returns wp plus ⌈extra⌉ bits, or ok = false if that is more than maxExtra.
+func widen(wp uint, extra float64) (wq uint, ok bool) {
+	if !(extra <= maxExtra) {
+		return 0, false
+	}
+	return wp + uint(math.Ceil(max(extra, 0))), true
+}
+
+// nan returns a NaN with wp bits.
+func nan(wp uint) *apc.Complex { return apc.New(wp).SetFloat64(math.NaN(), math.NaN()) }
+
+// large reports whether |z| is large enough at wp bits for the Hankel expansions, whose
+// smallest term is about e^(-2|z|).
+func large(z *apc.Complex, wp uint) bool { return z.AbsFloat64() >= 0.35*float64(wp)+4 }
+
+// negligible reports whether |t| < 2^-wp |s|, using tmp as scratch.
+func negligible(t, s, tmp *apc.Complex, wp uint) bool {
+	return t.IsZero() || t.CmpAbs(tmp.Div2Si(s, int64(wp))) < 0
+}
+
+// iPow returns i^x = e^(iπx/2).
+func iPow(x *apc.Complex, wp uint) *apc.Complex {
+	pi := apc.Pi(wp)
+	defer pi.Close()
+	t := apc.New(wp).Mul(x, pi)
+	t.Div2Si(t, 1).MulI(t, 1)
+	return t.Exp(t)
+}
+
+// series returns (z/2)^ν Σ (±z²/4)^k / (k! Γ(ν+k+1)): J_ν(z) for sign = -1, I_ν(z) for
+// sign = 1. ν must not be a negative integer. The terms grow to about e^|z| while J is
+// about e^|Im z| and I about e^|Re z|, which sets the extra precision.
+func series(nu, z *apc.Complex, sign int, wp uint) *apc.Complex {
+	zf := z.Complex128()
+	lost := cmplx.Abs(zf) - math.Abs(imag(zf))
+	if sign > 0 {
+		lost = cmplx.Abs(zf) - math.Abs(real(zf))
+	}
+	wq, ok := widen(wp, lost*math.Log2E)
+	if !ok {
+		return nan(wp)
+	}
+	h := apc.New(wq).Div2Si(z, 1)
+	defer h.Close()
+	q := apc.New(wq).Sqr(h)
+	defer q.Close()
+	if sign < 0 {
+		q.Neg(q)
+	}
+	nuq := apc.New(wq).Set(nu)
+	defer nuq.Close()
+	n := apc.New(wq).SetInt64(1, 0)
+	defer n.Close()
+	t := apc.New(wq).Add(nuq, n)
+	defer t.Close()
+	t.Gamma(t).Inv(t)
+	sum := apc.New(wq).Set(t)
+	qa := q.AbsFloat64()
+	d, nk, tmp := apc.New(wq), apc.New(wq), apc.New(wq)
+	defer d.Close()
+	defer nk.Close()
+	defer tmp.Close()
+	for k := int64(1); ; k++ {
+		// t *= q / (k (ν+k))
+		n.SetInt64(k, 0)
+		nk.Add(nuq, n)
+		d.Mul(nk, n)
+		t.Mul(t, q).Div(t, d)
+		sum.Add(sum, t)
+		if qa < 0.5*d.AbsFloat64() && negligible(t, sum, tmp, wq) {
+			break
+		}
+	}
+	hn := apc.New(wq).Pow(h, nuq)
+	defer hn.Close()
+	return sum.Mul(sum, hn)
+}
+
+// intSeries returns Y_n(z) (sign = -1) or K_n(z) (sign = 1) for an integer n ≥ 0 by
+// their logarithmic series:
+//
+//	Y_n(z) = (2/π) J_n(z) log(z/2) - F/π - S/π
+//	K_n(z) = (-1)^(n+1) I_n(z) log(z/2) + F/2 + (-1)ⁿ S/2
+//
+// with F = (z/2)^-n Σ_{k<n} (n-k-1)!/k! (∓z²/4)^k and
+// S = (z/2)ⁿ Σ (ψ(k+1) + ψ(n+k+1)) (±z²/4)^k / (k! (n+k)!), the upper signs for Y.
+func intSeries(n int, z *apc.Complex, sign int, wp uint) *apc.Complex {
+	zf := z.Complex128()
+	lost := cmplx.Abs(zf) - math.Abs(imag(zf))
+	if sign > 0 {
+		lost = cmplx.Abs(zf) + math.Abs(real(zf))
+	}
+	wq, ok := widen(wp, lost*math.Log2E)
+	if !ok {
+		return nan(wp)
+	}
+	h := apc.New(wq).Div2Si(z, 1)
+	defer h.Close()
+	q := apc.New(wq).Sqr(h)
+	defer q.Close()
+	if sign < 0 {
+		q.Neg(q)
+	}
+	m := apc.New(wq) // integer scratch
+	defer m.Close()
+	hp := apc.New(wq) // powers of z/2
+	defer hp.Close()
+
+	// F, its terms (n-k-1)!/k! (-q)^k
+	f := apc.New(wq).SetInt64(0, 0)
+	defer f.Close()
+	if n > 0 {
+		mq := apc.New(wq).Neg(q)
+		defer mq.Close()
+		t := apc.New(wq).SetInt64(1, 0)
+		defer t.Close()
+		for k := 1; k < n; k++ {
+			t.Mul(t, m.SetInt64(int64(k), 0))
+		}
+		// t = (n-1)!, the k = 0 coefficient
+		f.Set(t)
+		for k := 1; k < n; k++ {
+			// (n-k-1)!/k! = (n-k)!/(k-1)! / ((n-k) k)
+			t.Div(t, m.SetInt64(int64((n-k)*k), 0)).Mul(t, mq)
+			f.Add(f, t)
+		}
+		f.Mul(f, hp.PowInt64(h, -int64(n)))
+	}
+
+	// S with ψ(k+1) + ψ(n+k+1) = -2γ + H_k + H_{n+k}.
+	g := apc.EulerGamma(wq)
+	defer g.Close()
+	hk := apc.New(wq).SetInt64(0, 0)
+	defer hk.Close()
+	hnk := apc.New(wq).SetInt64(0, 0)
+	defer hnk.Close()
+	for k := 1; k <= n; k++ {
+		hnk.Add(hnk, m.SetInt64(int64(k), 0).Inv(m))
+	}
+	t := apc.New(wq).SetInt64(1, 0) // (±z²/4)^k / (k! (n+k)!)
+	defer t.Close()
+	for k := 2; k <= n; k++ {
+		t.Div(t, m.SetInt64(int64(k), 0))
+	}
+	psi := apc.New(wq)
+	defer psi.Close()
+	c := apc.New(wq).Add(hk, hnk)
+	defer c.Close()
+	c.Sub(c, g).Sub(c, g)
+	s := apc.New(wq).Mul(c, t)
+	defer s.Close()
+	tmp := apc.New(wq)
+	defer tmp.Close()
+	qa := q.AbsFloat64()
+	for k := int64(1); ; k++ {
+		t.Mul(t, q).Div(t, m.SetInt64(k*(int64(n)+k), 0))
+		hk.Add(hk, m.SetInt64(k, 0).Inv(m))
+		hnk.Add(hnk, m.SetInt64(int64(n)+k, 0).Inv(m))
+		psi.Add(hk, hnk)
+		psi.Sub(psi, g).Sub(psi, g)
+		psi.Mul(psi, t)
+		s.Add(s, psi)
+		if qa < 0.5*float64(k*(int64(n)+k)) && negligible(psi, s, tmp, wq) {
+			break
+		}
+	}
+	s.Mul(s, hp.PowInt64(h, int64(n)))
+
+	l := apc.New(wq).Log(h)
+	jn := series(m.SetInt64(int64(n), 0), z, sign, wq)
+	defer jn.Close()
+	l.Mul(l, jn)
+	if sign < 0 {
+		// Y_n = (2 J_n log(z/2) - F - S) / π
+		pi := apc.Pi(wq)
+		defer pi.Close()
+		l.Mul2Si(l, 1).Sub(l, f).Sub(l, s)
+		return l.Div(l, pi)
+	}
+	// K_n = (-1)^(n+1) I_n log(z/2) + F/2 + (-1)ⁿ S/2
+	if n%2 == 0 {
+		l.Neg(l)
+	} else {
+		s.Neg(s)
+	}
+	f.Add(f, s).Div2Si(f, 1)
+	return l.Add(l, f)
+}
+
+// nearestInt returns the integer nearest to Re ν.
+func nearestInt(nu *apc.Complex, wp uint) *apc.Real {
+	re := nu.Re()
+	defer re.Close()
+	return apc.NewReal(wp).Round(re)
+}
+
+// reflectGuard returns the bits lost in dividing by sin(νπ) for ν near an integer.
+func reflectGuard(nu *apc.Complex, wp uint) uint {
+	r := nearestInt(nu, wp)
+	defer r.Close()
+	m := apc.New(wp).SetReals(r, nil)
+	defer m.Close()
+	d := m.Sub(nu, m).AbsFloat64()
+	switch {
+	case d == 0:
+		return wp
+	case d < 0.5:
+		return uint(math.Ceil(-math.Log2(math.Pi * d)))
+	}
+	return 0
+}
+
+// hankelAsymptotic returns H¹_ν(z) and H²_ν(z) for Re z ≥ 0 by
+//
+//	H^(1,2)_ν(z) ~ √(2/(πz)) e^(±iω) Σ (±i)^k a_k(ν) / z^k,  ω = z - νπ/2 - π/4,
+//	a_k(ν) = (4ν²-1²)(4ν²-3²)…(4ν²-(2k-1)²) / (k! 8^k),
+//
+// or ok = false if the terms grow too large or start to grow before they fall below
+// 2^-wp.
+func hankelAsymptotic(nu, z *apc.Complex, wp uint) (h1, h2 *apc.Complex, ok bool) {
+	mu := apc.New(wp).Sqr(nu)
+	defer mu.Close()
+	mu.Mul2Si(mu, 2)
+	p, q := apc.New(wp).SetInt64(1, 0), apc.New(wp).SetInt64(0, 0)
+	defer p.Close()
+	defer q.Close()
+	t, d, m, tmp := apc.New(wp).SetInt64(1, 0), apc.New(wp), apc.New(wp), apc.New(wp)
+	defer t.Close()
+	defer d.Close()
+	defer m.Close()
+	defer tmp.Close()
+	limit := 2*z.AbsFloat64() + nu.AbsFloat64() + 8
+	prev := 1.0
+	for k := int64(1); ; k++ {
+		if float64(k) > limit {
+			return nil, nil, false
+		}
+		d.Sub(mu, m.SetInt64((2*k-1)*(2*k-1), 0))
+		t.Mul(t, d).Div(t, z).Div(t, m.SetInt64(8*k, 0))
+		if k%2 == 0 {
+			if k%4 == 2 {
+				p.Sub(p, t)
+			} else {
+				p.Add(p, t)
+			}
+		} else {
+			if k%4 == 3 {
+				q.Sub(q, t)
+			} else {
+				q.Add(q, t)
+			}
+		}
+		if negligible(t, p, tmp, wp) {
+			break
+		}
+		// Terms past 2^(guard/2) cancel away more bits than the guard holds, as they do
+		// when |ν| is close to |z|.
+		ta := t.AbsFloat64()
+		if ta > 1<<(guard/2) || float64(k) > nu.AbsFloat64()+1 && ta > prev {
+			return nil, nil, false
+		}
+		prev = ta
+	}
+	pi := apc.Pi(wp)
+	defer pi.Close()
+	// √(2/(πz))
+	pre := apc.New(wp).Mul(pi, z)
+	defer pre.Close()
+	pre.Div(m.SetInt64(2, 0), pre).Sqrt(pre)
+	// ω = z - (2ν+1)π/4
+	w := apc.New(wp).Mul2Si(nu, 1)
+	defer w.Close()
+	w.Add(w, m.SetInt64(1, 0)).Mul(w, pi).Div2Si(w, 2)
+	w.Sub(z, w)
+	e1 := apc.New(wp).MulI(w, 1)
+	defer e1.Close()
+	e1.Exp(e1)
+	e2 := apc.New(wp).MulI(w, -1)
+	defer e2.Close()
+	e2.Exp(e2)
+	iq := apc.New(wp).MulI(q, 1)
+	defer iq.Close()
+	h1 = apc.New(wp).Add(p, iq)
+	h1.Mul(h1, e1).Mul(h1, pre)
+	h2 = apc.New(wp).Sub(p, iq)
+	h2.Mul(h2, e2).Mul(h2, pre)
+	return h1, h2, true
+}
+
+// hankelRight returns H¹_ν(z), H²_ν(z) for Re z ≥ 0: directly by the asymptotic
+// expansions, or at ν0 = ν - m, Re ν0 ∈ [-1/2, 1/2], and ν0 ± 1 followed by the
+// recurrence C_(μ±1) = (2μ/z) C_μ - C_(μ∓1), which is stable for both while |μ| < |z|.
+func hankelRight(nu, z *apc.Complex, wp uint) (h1, h2 *apc.Complex, ok bool) {
+	if h1, h2, ok = hankelAsymptotic(nu, z, wp); ok {
+		return h1, h2, true
+	}
+	if nu.AbsFloat64() >= 0.9*z.AbsFloat64() {
+		return nil, nil, false
+	}
+	mr := nearestInt(nu, wp)
+	defer mr.Close()
+	m := mr.Int64()
+	if m == 0 {
+		return nil, nil, false
+	}
+	step := int64(1)
+	if m < 0 {
+		step = -1
+	}
+	wq := wp + uint(math.Ceil(math.Log2(float64(1+m*step))))
+	mu := apc.New(wq).SetReals(mr, nil)
+	defer mu.Close()
+	mu.Sub(nu, mu)
+	a1, a2, ok := hankelAsymptotic(mu, z, wq)
+	if !ok {
+		return nil, nil, false
+	}
+	s := apc.New(wq).SetInt64(step, 0)
+	defer s.Close()
+	mu.Add(mu, s)
+	b1, b2, ok := hankelAsymptotic(mu, z, wq)
+	if !ok {
+		a1.Close()
+		a2.Close()
+		return nil, nil, false
+	}
+	// a = C_(μ-step), b = C_μ; the five values trade places, so they are closed by hand.
+	f, t := apc.New(wq), apc.New(wq)
+	for j := step; j != m; j += step {
+		f.Mul2Si(mu, 1).Div(f, z)
+		t.Mul(f, b1).Sub(t, a1)
+		a1, b1, t = b1, t, a1
+		t.Mul(f, b2).Sub(t, a2)
+		a2, b2, t = b2, t, a2
+		mu.Add(mu, s)
+	}
+	f.Close()
+	t.Close()
+	a1.Close()
+	a2.Close()
+	return b1, b2, true
+}
+
+// upper reports whether z is on the upper side of the negative real axis cut, by the
+// sign of its imaginary part.
+func upper(z *apc.Complex) bool {
+	im := z.Im()
+	defer im.Close()
+	return im.Sign() > 0 || im.IsZero() && !im.Signbit()
+}
+
+// hankel returns H¹_ν(z), H²_ν(z). Left of the imaginary axis they come from w = -z by
+//
+//	H¹_ν(we^(iπ)) = -e^(-iνπ) H²_ν(w),                 H²_ν(we^(iπ)) = 2cos(νπ) H²_ν(w) + e^(iνπ) H¹_ν(w),
+//	H¹_ν(we^(-iπ)) = 2cos(νπ) H¹_ν(w) + e^(-iνπ) H²_ν(w), H²_ν(we^(-iπ)) = -e^(iνπ) H¹_ν(w).
+func hankel(nu, z *apc.Complex, wp uint) (h1, h2 *apc.Complex, ok bool) {
+	if reSign(z) >= 0 {
+		return hankelRight(nu, z, wp)
+	}
+	w := apc.New(wp).Neg(z)
+	defer w.Close()
+	g1, g2, ok := hankelRight(nu, w, wp)
+	if !ok {
+		return nil, nil, false
+	}
+	defer g1.Close()
+	defer g2.Close()
+	e := iPow(w.Mul2Si(nu, 1), wp) // e^(iνπ)
+	defer e.Close()
+	einv := apc.New(wp).Inv(e)
+	defer einv.Close()
+	c2 := apc.New(wp).Add(e, einv)
+	defer c2.Close()
+	if upper(z) {
+		h1 = apc.New(wp).Mul(einv, g2)
+		h1.Neg(h1)
+		h2 = apc.New(wp).Mul(c2, g2)
+		h2.Add(h2, e.Mul(e, g1))
+	} else {
+		h2 = apc.New(wp).Mul(e, g1)
+		h2.Neg(h2)
+		h1 = apc.New(wp).Mul(c2, g1)
+		h1.Add(h1, einv.Mul(einv, g2))
+	}
+	return h1, h2, true
+}
+
+// sinPi returns sin(νπ).
+func sinPi(nu *apc.Complex, wp uint) *apc.Complex {
+	pi := apc.Pi(wp)
+	defer pi.Close()
+	x := apc.New(wp).Mul(nu, pi)
+	return x.Sin(x)
+}
+
+// cosPi returns cos(νπ).
+func cosPi(nu *apc.Complex, wp uint) *apc.Complex {
+	pi := apc.Pi(wp)
+	defer pi.Close()
+	x := apc.New(wp).Mul(nu, pi)
+	return x.Cos(x)
+}
+
+// jAt returns J_ν(z) at wp bits, using J_(-n) = (-1)ⁿ J_n for negative integers.
+func jAt(nu, z *apc.Complex, wp uint) *apc.Complex {
+	if isInt(nu) && nu.Sign() < 0 {
+		m := apc.Neg(nu)
+		defer m.Close()
+		r := jAt(m, z, wp)
+		if intOrder(nu)%2 != 0 {
+			r.Neg(r)
+		}
+		return r
+	}
+	if large(z, wp) {
+		if h1, h2, ok := hankel(nu, z, wp); ok {
+			defer h2.Close()
+			return h1.Add(h1, h2).Div2Si(h1, 1)
+		}
+	}
+	return series(nu, z, -1, wp)
+}
+
+// yAt returns Y_ν(z) at wp bits, using Y_(-n) = (-1)ⁿ Y_n for negative integers and
+// Y_ν = (J_ν cos(νπ) - J_(-ν)) / sin(νπ) otherwise.
+func yAt(nu, z *apc.Complex, wp uint) *apc.Complex {
+	m := apc.Neg(nu)
+	defer m.Close()
+	if isInt(nu) && nu.Sign() < 0 {
+		r := yAt(m, z, wp)
+		if intOrder(nu)%2 != 0 {
+			r.Neg(r)
+		}
+		return r
+	}
+	if large(z, wp) {
+		if h1, h2, ok := hankel(nu, z, wp); ok {
+			defer h2.Close()
+			// (H¹ - H²) / 2i
+			return h1.Sub(h1, h2).MulI(h1, -1).Div2Si(h1, 1)
+		}
+	}
+	if isInt(nu) {
+		return intSeries(intOrder(nu), z, -1, wp)
+	}
+	wq := wp + reflectGuard(nu, wp)
+	j := series(nu, z, -1, wq)
+	jm := series(m, z, -1, wq)
+	defer jm.Close()
+	s, c := sinPi(nu, wq), cosPi(nu, wq)
+	defer s.Close()
+	defer c.Close()
+	j.Mul(j, c).Sub(j, jm)
+	return j.Div(j, s)
+}
+
+// iAt returns I_ν(z) at wp bits. For large |z| it is i^(-ν) J_ν(iz), or i^ν J_ν(-iz)
+// for π/2 < arg z ≤ π.
+func iAt(nu, z *apc.Complex, wp uint) *apc.Complex {
+	if isInt(nu) && nu.Sign() < 0 {
+		m := apc.Neg(nu)
+		defer m.Close()
+		return iAt(m, z, wp)
+	}
+	if large(z, wp) {
+		w := apc.New(wp)
+		defer w.Close()
+		e := apc.New(wp)
+		defer e.Close()
+		if reSign(z) < 0 && upper(z) {
+			w.MulI(z, -1)
+			e.Set(nu)
+		} else {
+			w.MulI(z, 1)
+			e.Neg(nu)
+		}
+		if h1, h2, ok := hankel(nu, w, wp); ok {
+			defer h2.Close()
+			ip := iPow(e, wp)
+			defer ip.Close()
+			h1.Add(h1, h2).Div2Si(h1, 1)
+			return h1.Mul(h1, ip)
+		}
+	}
+	return series(nu, z, 1, wp)
+}
+
+// kAt returns K_ν(z) at wp bits. For large |z| it is (π/2) i^(ν+1) H¹_ν(iz), or
+// (π/2) (-i)^(ν+1) H²_ν(-iz) for π/2 < arg z ≤ π; otherwise the logarithmic series for
+// integer ν and K_ν = (π/2) (I_(-ν) - I_ν) / sin(νπ).
+func kAt(nu, z *apc.Complex, wp uint) *apc.Complex {
+	m := apc.Neg(nu)
+	defer m.Close()
+	if isInt(nu) && nu.Sign() < 0 {
+		return kAt(m, z, wp)
+	}
+	if large(z, wp) {
+		w := apc.New(wp).SetInt64(1, 0)
+		defer w.Close()
+		e := apc.New(wp).Add(nu, w)
+		defer e.Close()
+		left := reSign(z) < 0 && upper(z)
+		if left {
+			w.MulI(z, -1)
+			e.Neg(e)
+		} else {
+			w.MulI(z, 1)
+		}
+		if h1, h2, ok := hankel(nu, w, wp); ok {
+			h := h1
+			if left {
+				h, h2 = h2, h1
+			}
+			defer h2.Close()
+			ip := iPow(e, wp)
+			defer ip.Close()
+			pi := apc.Pi(wp)
+			defer pi.Close()
+			h.Mul(h, ip).Mul(h, pi)
+			return h.Div2Si(h, 1)
+		}
+	}
+	if isInt(nu) {
+		return intSeries(intOrder(nu), z, 1, wp)
+	}
+	// I_(-ν) - I_ν cancels down to K ≈ e^(-Re z).
+	wq, ok := widen(wp+reflectGuard(nu, wp), 2*max(real(z.Complex128()), 0)*math.Log2E)
+	if !ok {
+		return nan(wp)
+	}
+	im := series(m, z, 1, wq)
+	ip := series(nu, z, 1, wq)
+	defer ip.Close()
+	im.Sub(im, ip)
+	s := sinPi(nu, wq)
+	defer s.Close()
+	pi := apc.Pi(wq)
+	defer pi.Close()
+	im.Div(im, s).Mul(im, pi)
+	return im.Div2Si(im, 1)
+}
+
+// hankelAt returns H¹_ν(z) (which = 1) or H²_ν(z) (which = 2) at wp bits. Away from the
+// asymptotic region it is J_ν ± iY_ν, where one of them is about e^(-|Im z|) smaller
+// than J and Y.
+func hankelAt(nu, z *apc.Complex, which int, wp uint) *apc.Complex {
+	if large(z, wp) {
+		if h1, h2, ok := hankel(nu, z, wp); ok {
+			if which == 1 {
+				h2.Close()
+				return h1
+			}
+			h1.Close()
+			return h2
+		}
+	}
+	wq, ok := widen(wp, 2*math.Abs(imag(z.Complex128()))*math.Log2E)
+	if !ok {
+		return nan(wp)
+	}
+	j, y := jAt(nu, z, wq), yAt(nu, z, wq)
+	defer y.Close()
+	if which == 1 {
+		y.MulI(y, 1)
+	} else {
+		y.MulI(y, -1)
+	}
+	return j.Add(j, y)
+}
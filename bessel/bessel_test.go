@@ -0,0 +1,252 @@
+package bessel
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	apc "github.com/lukaszgryglicki/apcomplex"
+)
+
+// relClose reports whether |got - want| ≤ 2^(lost-prec) |want|.
+func relClose(got, want *apc.Complex, lost int) bool {
+	tol := want.Abs()
+	tol.Mul(tol, apc.NewReal(64).SetFloat64(math.Ldexp(1, lost-int(want.Prec()))))
+	return apc.Sub(got, want).Abs().Cmp(tol) <= 0
+}
+
+// absClose reports whether |got - want| ≤ 2^(lost-prec) |scale|.
+func absClose(got, want, scale *apc.Complex, lost int) bool {
+	tol := scale.Abs()
+	tol.Mul(tol, apc.NewReal(64).SetFloat64(math.Ldexp(1, lost-int(want.Prec()))))
+	return apc.Sub(got, want).Abs().Cmp(tol) <= 0
+}
+
+func TestAgainstMPFR(t *testing.T) {
+	// MPFR's real J_n and Y_n are correctly rounded: they serve as the table. The
+	// arguments cover the series, the Hankel expansions and the recurrence.
+	const prec = 128
+	for _, n := range []int{0, 1, 5, -3, 20} {
+		for _, xs := range []string{"0.5", "3", "30", "57.25", "120"} {
+			x := apc.MustParseReal(xs, prec)
+			nu := apc.New(prec).SetInt64(int64(n), 0)
+			z := apc.New(prec).SetReals(x, nil)
+			wantJ := apc.New(prec).SetReals(apc.NewReal(prec).Jn(n, x), nil)
+			if got := J(nu, z); !relClose(got, wantJ, 8) {
+				t.Errorf("J_%d(%s) = %v, want %v", n, xs, got, wantJ)
+			}
+			wantY := apc.New(prec).SetReals(apc.NewReal(prec).Yn(n, x), nil)
+			if got := Y(nu, z); !relClose(got, wantY, 8) {
+				t.Errorf("Y_%d(%s) = %v, want %v", n, xs, got, wantY)
+			}
+		}
+	}
+	// Near |ν| = |z| the Hankel expansions cancel badly and the series takes over.
+	for _, n := range []int{100, 300, 1000} {
+		for _, f := range []float64{0.9, 1, 1.1} {
+			x := apc.NewReal(prec).SetFloat64(f * float64(n))
+			nu := apc.New(prec).SetInt64(int64(n), 0)
+			z := apc.New(prec).SetReals(x, nil)
+			wantJ := apc.New(prec).SetReals(apc.NewReal(prec).Jn(n, x), nil)
+			if got := J(nu, z); !relClose(got, wantJ, 8) {
+				t.Errorf("J_%d(%v) = %v, want %v", n, x, got, wantJ)
+			}
+			wantY := apc.New(prec).SetReals(apc.NewReal(prec).Yn(n, x), nil)
+			if got := Y(nu, z); !relClose(got, wantY, 8) {
+				t.Errorf("Y_%d(%v) = %v, want %v", n, x, got, wantY)
+			}
+		}
+	}
+}
+
+func TestKnownValues(t *testing.T) {
+	tol := apc.MustParseReal("1e-30", 64)
+	tests := []struct {
+		name string
+		f    func(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex
+		nu   string
+		z    string
+		want string
+	}{
+		{"I", I, "0", "1", "1.266065877752008335598244625214717537607"},
+		{"I", I, "1", "1", "0.5651591039924850272076960276098633073289"},
+		{"K", K, "0", "1", "0.4210244382407083333356273792126090361405"},
+		{"K", K, "1", "1", "0.6019072301972345747375400015356173392600"},
+		{"K", K, "0", "0.01", "4.721244730161094965135877708399860035266"},
+		{"J", J, "0", "2.404825557695772768621631879326454643124", "0"},
+	}
+	for _, tt := range tests {
+		nu, z := apc.MustParse(tt.nu, 160), apc.MustParse(tt.z, 160)
+		if got := tt.f(nu, z); !got.WithinAbs(apc.MustParse(tt.want, 160), tol) {
+			t.Errorf("%s_%s(%s) = %v, want %s", tt.name, tt.nu, tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestHalfIntegerOrder(t *testing.T) {
+	// Closed forms for ν = 1/2 at points of all the algorithms, on both sides of the
+	// imaginary axis.
+	for _, prec := range []uint{64, 256} {
+		h := apc.MustParse("0.5", prec)
+		pi := apc.Pi(prec)
+		for _, s := range []string{"0.7+0.2i", "3-4i", "-2.5+1i", "40+3i", "-60-25i", "15i", "200"} {
+			z := apc.MustParse(s, prec)
+			// √(2/(πz))
+			r := apc.Sqrt(apc.Div(apc.MustParse("2", prec), apc.Mul(pi, z)))
+			want := apc.Mul(r, apc.Sin(z))
+			if got := J(h, z); !relClose(got, want, 16) {
+				t.Errorf("%d bits: J_1/2(%s) = %v, want %v", prec, s, got, want)
+			}
+			want = apc.Neg(apc.Mul(r, apc.Cos(z)))
+			if got := Y(h, z); !relClose(got, want, 16) {
+				t.Errorf("%d bits: Y_1/2(%s) = %v, want %v", prec, s, got, want)
+			}
+			want = apc.Mul(r, apc.Sinh(z))
+			if got := I(h, z); !relClose(got, want, 16) {
+				t.Errorf("%d bits: I_1/2(%s) = %v, want %v", prec, s, got, want)
+			}
+			// K_1/2(z) = √(π/(2z)) e^-z
+			want = apc.Mul(apc.Sqrt(apc.Div(pi, apc.Mul(apc.MustParse("2", prec), z))), apc.Exp(apc.Neg(z)))
+			if got := K(h, z); !relClose(got, want, 16) {
+				t.Errorf("%d bits: K_1/2(%s) = %v, want %v", prec, s, got, want)
+			}
+		}
+	}
+}
+
+func TestWronskians(t *testing.T) {
+	nus := []string{"0.3+0.4i", "2.5", "-1.7-0.2i", "7", "12.25+1i", "-4"}
+	zs := []string{"0.4+0.1i", "5-2i", "-3.5+2i", "33+1i", "-45-10i", "70"}
+	for _, prec := range []uint{64, 200} {
+		one := apc.MustParse("1", prec)
+		pi := apc.Pi(prec)
+		for _, ns := range nus {
+			nu := apc.MustParse(ns, prec)
+			nu1 := apc.Add(nu, one)
+			for _, s := range zs {
+				z := apc.MustParse(s, prec)
+				// J_(ν+1) Y_ν - J_ν Y_(ν+1) = 2/(πz); the products can be much larger than
+				// the Wronskian, left of the imaginary axis I and K both grow like e^|Re z|.
+				j, y := J(nu, z), Y(nu, z)
+				a := apc.Mul(J(nu1, z), y)
+				lhs := apc.Sub(a, apc.Mul(j, Y(nu1, z)))
+				want := apc.Div(apc.MustParse("2", prec), apc.Mul(pi, z))
+				if !absClose(lhs, want, apc.Add(want, a), 16) {
+					t.Errorf("%d bits, ν = %s, z = %s: W(J, Y) = %v, want %v", prec, ns, s, lhs, want)
+				}
+				// I_ν K_(ν+1) + I_(ν+1) K_ν = 1/z
+				a = apc.Mul(I(nu, z), K(nu1, z))
+				lhs = apc.Add(a, apc.Mul(I(nu1, z), K(nu, z)))
+				if want := apc.Inv(z); !absClose(lhs, want, apc.Add(want, a), 16) {
+					t.Errorf("%d bits, ν = %s, z = %s: W(I, K) = %v, want %v", prec, ns, s, lhs, want)
+				}
+				// H¹ = J + iY, H² = J - iY
+				iy := apc.MulI(y, 1)
+				if got, want := H1(nu, z), apc.Add(j, iy); !absClose(got, want, y, 16) {
+					t.Errorf("%d bits, ν = %s, z = %s: H¹ = %v, J + iY = %v", prec, ns, s, got, want)
+				}
+				if got, want := H2(nu, z), apc.Sub(j, iy); !absClose(got, want, y, 16) {
+					t.Errorf("%d bits, ν = %s, z = %s: H² = %v, J - iY = %v", prec, ns, s, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestContinuity(t *testing.T) {
+	// Around the switch from the series to the Hankel expansions the two agree.
+	const prec = 128
+	wp := prec + guard
+	for _, d := range []float64{-0.5, 0.5} {
+		r := 0.35*float64(wp) + 4 + d
+		for _, ns := range []string{"0", "1.5+0.5i", "9"} {
+			nu := apc.MustParse(ns, prec)
+			z := apc.MustParse(strconv.FormatFloat(r, 'f', 3, 64)+"+2i", prec)
+			j := J(nu, z)
+			ref := J(apc.MustParse(ns, 400), apc.MustParse(strconv.FormatFloat(r, 'f', 3, 64)+"+2i", 400))
+			if !relClose(j, apc.New(prec).Set(ref), 12) {
+				t.Errorf("J_%s(%v) = %v at %d bits, %v at 400", ns, z, j, prec, ref)
+			}
+		}
+	}
+}
+
+func TestSpecialArguments(t *testing.T) {
+	zero := apc.New(64).SetInt64(0, 0)
+	if got := J(zero, zero); !got.Equal(apc.MustParse("1", 64)) {
+		t.Errorf("J_0(0) = %v", got)
+	}
+	if got := I(apc.MustParse("2.5", 64), zero); !got.IsZero() {
+		t.Errorf("I_2.5(0) = %v", got)
+	}
+	if got := Y(zero, zero); !got.Re().IsInf() || got.Re().Sign() > 0 {
+		t.Errorf("Y_0(0) = %v, want -∞", got)
+	}
+	if got := K(apc.MustParse("1", 64), zero); !got.Re().IsInf() {
+		t.Errorf("K_1(0) = %v, want ∞", got)
+	}
+	if got := J(zero, apc.MustParse("nan", 64)); !got.IsNaN() {
+		t.Errorf("J_0(NaN) = %v", got)
+	}
+	// Y_(-1/2)(z) = √(2/(πz)) sin z → 0, while Y_ν → ∓∞ for other negative ν, with
+	// the sign of -cos(νπ).
+	for _, tt := range []struct {
+		nu   string
+		want float64
+	}{{"-0.5", 0}, {"-2.5", 0}, {"-0.25", math.Inf(-1)}, {"-0.75", math.Inf(1)}, {"-1", math.Inf(1)}, {"-2", math.Inf(-1)}} {
+		if got := Y(apc.MustParse(tt.nu, 64), zero).Complex128(); got != complex(tt.want, 0) {
+			t.Errorf("Y_%s(0) = %v, want %v", tt.nu, got, tt.want)
+		}
+	}
+	// H¹ = J + iY is still singular where Y vanishes.
+	if got := H1(apc.MustParse("-0.5", 64), zero); !got.Re().IsInf() {
+		t.Errorf("H¹_-1/2(0) = %v, want ∞", got)
+	}
+	// z^(iy) circles without a limit as z → 0.
+	for _, ns := range []string{"2i", "-0.5i"} {
+		nu := apc.MustParse(ns, 64)
+		for name, f := range map[string]func(nu, z *apc.Complex, mode ...apc.RoundingMode) *apc.Complex{"J": J, "Y": Y, "I": I, "K": K, "H¹": H1} {
+			if got := f(nu, zero); !got.IsNaN() {
+				t.Errorf("%s_%s(0) = %v, want NaN", name, ns, got)
+			}
+		}
+	}
+	if got := J(apc.MustParse("-0.5+1i", 64), zero); !got.Re().IsInf() {
+		t.Errorf("J_(-1/2+i)(0) = %v, want ∞", got)
+	}
+	// Past the float64 range the guard bits come from the exponent of |z|.
+	huge := apc.MustParse("1e400", 64)
+	zb := apc.New(1500).Set(huge)
+	want := apc.Mul(apc.Sqrt(apc.Div(apc.MustParse("2", 1500), apc.Mul(apc.Pi(1500), zb))), apc.Sin(zb))
+	if got := J(apc.MustParse("0.5", 64), huge); !relClose(got, apc.New(64).Set(want), 8) {
+		t.Errorf("J_1/2(1e400) = %v, want %v", got, want)
+	}
+	if got := K(apc.MustParse("0.5", 64), apc.MustParse("-1e400+1i", 64)); !got.IsInf() {
+		t.Errorf("K_1/2(-1e400+i) = %v, want ∞", got)
+	}
+	for _, tt := range [][2]string{{"0.5", "1e5000"}, {"1e400", "2"}, {"1e400", "1e400"}, {"3e6", "1"}, {"1e15", "1e15"}} {
+		if got := J(apc.MustParse(tt[0], 64), apc.MustParse(tt[1], 64)); !got.IsNaN() {
+			t.Errorf("J_%s(%s) = %v, want NaN", tt[0], tt[1], got)
+		}
+	}
+	// The cut: Y_0(-x ± 0i) = Y_0(x) ± 2i J_0(x).
+	x, mx := apc.MustParse("2", 128), apc.MustParse("-2", 128)
+	above, below := Y(zero, mx), Y(zero, apc.Conj(mx))
+	if want := apc.Add(Y(zero, x), apc.MulI(apc.Mul2Si(J(zero, x), 1), 1)); !relClose(above, want, 8) {
+		t.Errorf("Y_0(-2+0i) = %v, want %v", above, want)
+	}
+	if !below.Equal(apc.Conj(above)) {
+		t.Errorf("Y_0(-2-0i) = %v, want %v", below, apc.Conj(above))
+	}
+}
+
+func Example() {
+	nu := apc.MustParse("0.5+i", 64)
+	z := apc.MustParse("3-2i", 64)
+	fmt.Println(J(nu, z).StringFixed(15))
+	fmt.Println(K(apc.MustParse("0", 64), apc.MustParse("1", 64)).StringFixed(15))
+	// Output:
+	// 0.292944700381048+6.974856074250207i
+	// 0.421024438240708+0.000000000000000i
+}
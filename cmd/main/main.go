@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"fmt"
-	"math"
 	"os"
 
 	ap "github.com/lukaszgryglicki/apcomplex"
@@ -14,7 +13,7 @@ func main() {
 	expStr := flag.String("exp", "2-1e-100i", "complex height, e.g. \"2-1e-100i\" or \"(2 -1e-100)\"")
 	// exp := flag.Int("exp", 2048, "integer exponent")
 	prec := flag.Uint("prec", 8192, "precision in bits (both real & imag)")
-	digits := flag.Int("digits", -1, "digits for output; -1 = auto from precision")
+	digits := flag.Int("digits", -1, "digits for output; -1 = shortest that round-trips at -prec")
 	out := flag.String("out", "sci", "output mode: sci|fixed")
 	flag.Parse()
 
@@ -34,16 +33,21 @@ func main() {
 	// Compute z^n
 	res := ap.New(*prec).Pow(z, n)
 
-	// Choose a safe number of digits for printing from the precision.
+	// With no explicit digit count print the shortest strings that parse back exactly.
 	d := *digits
 	if d < 0 {
-		d = int(float64(*prec)*math.Log10(2)) - 5 // ~significant digits; leave a tiny safety margin
-		if d < 1 {
-			d = 1
-		}
-		if d > 1<<20 {
-			d = 1 << 20 // sanity cap
+		fmt.Printf("z = %v\n", z)
+		fmt.Printf("n = %v\n", n)
+		fmt.Printf("precision = %d bits, shortest round-trip digits\n", *prec)
+		switch *out {
+		case "fixed":
+			fmt.Printf("z^n (fixed, shortest): %s\n", res.Text('f', -1))
+		default:
+			fmt.Printf("z^n (scientific, shortest): %s\n", res.Text('e', -1))
 		}
+		fmt.Printf("Re(z^n) (fixed, shortest): %s\n", res.Re().Text('f', -1))
+		fmt.Printf("Im(z^n) (fixed, shortest): %s\n", res.Im().Text('f', -1))
+		return
 	}
 
 	fmt.Printf("z = %s\n", z.StringScientific(d))
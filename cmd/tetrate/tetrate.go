@@ -14,7 +14,7 @@ package main
 //   so T_b(t) = f^{∘t}(1). For integer t, this matches the usual power tower.
 //
 // Method (primary): Koenigs/Schröder linearization at an attracting fixed point z*:
-//   1) Find z* solving z* = b^{z*}; λ = f'(z*) = ln(b)*z*.
+//   1) Find z* solving z* = b^{z*}, z* = -W_0(-ln b)/ln b; λ = f'(z*) = ln(b)*z*.
 //   2) Koenigs map φ(z) = lim_{n→∞} λ^{-n}(f^{∘n}(z) - z*).
 //   3) f^{∘t}(z) = φ^{-1}(λ^{t} φ(z)). We compute φ via iteration, and obtain φ^{-1} by
 //      solving for w: φ(w) = y using Newton and an iterative approximation for φ.
@@ -40,7 +40,6 @@ import (
 	"math"
 	"os"
 	"strconv"
-	"strings"
 
 	ap "github.com/lukaszgryglicki/apcomplex"
 )
@@ -95,6 +94,15 @@ func main() {
 	fmt.Printf("b^(T(h)) (sanity): %s\n", bpow.StringScientific(digits))
 }
 
+// powStep is f(z) = b^z = e^{ln(b)*z}, compiled once and evaluated with ln(b) bound.
+var powStep = ap.MustCompile("exp(lnb*z)", "lnb", "z")
+
+// maxIntHeight and intHeightTol bound the integer-height fallback in tryIntegerHeight.
+var (
+	maxIntHeight = ap.MustParseReal("1e9", 64)
+	intHeightTol = ap.MustParseReal("1e-30", 64)
+)
+
 // tetrate computes T_b(h) = f^{∘h}(1) where f(z)=b^z.
 // Returns result, method description, error.
 func tetrate(b, h *ap.Complex, prec uint) (*ap.Complex, string, error) {
@@ -104,12 +112,15 @@ func tetrate(b, h *ap.Complex, prec uint) (*ap.Complex, string, error) {
 		return ap.MustParse("1", prec), "constant base=1", nil
 	}
 
+	step := powStep.NewEvaluator(&ap.Context{Prec: prec})
+	defer step.Close()
+	lnb := ap.New(prec).Log(b)
 	f := func(z *ap.Complex) *ap.Complex {
-		return ap.New(prec).Exp(ap.New(prec).Mul(ap.New(prec).Log(b), z)) // b^z
+		return step.Eval(ap.New(prec), lnb, z) // b^z
 	}
 
 	// Try Schröder/Koenigs in basin of attraction.
-	zstar, lam, ok := findAttractingFixedPoint(b, f, prec)
+	zstar, lam, ok := findAttractingFixedPoint(b, prec)
 	if ok {
 		res, err := tetrateSchroeder(b, f, zstar, lam, h, prec)
 		if err == nil {
@@ -166,52 +177,14 @@ func tetrateSchroeder(b *ap.Complex, f func(*ap.Complex) *ap.Complex, zstar, lam
 	return w, nil
 }
 
-// findAttractingFixedPoint tries to locate z* = b^{z*} with |λ|<1 where λ = ln(b)*z*.
-func findAttractingFixedPoint(b *ap.Complex, f func(*ap.Complex) *ap.Complex, prec uint) (zstar, lam *ap.Complex, ok bool) {
-	// iterate from 1: u_{n+1} = f(u_n)
-	u := ap.MustParse("1", prec)
-	var last *ap.Complex
-	for i := 0; i < 2000; i++ {
-		last = u
-		u = f(u)
-		if diffSmall(u, last, prec, 20) { // converged
-			zstar = u
-			lnb := ap.New(prec).Log(b)
-			lam = ap.New(prec).Mul(lnb, zstar)
-			if absFloat(lam, prec) < 1 {
-				return zstar, lam, true
-			}
-			break
-		}
-		if absFloat(u, prec) > 1e12 { // likely diverging
-			break
-		}
-	}
-	// Try Newton on g(z)=z - f(z)
-	g := func(z *ap.Complex) *ap.Complex { return ap.New(prec).Sub(z, f(z)) }
-	gp := func(z *ap.Complex) *ap.Complex {
-		// g'(z) = 1 - f'(z) = 1 - ln(b)*b^z
-		one := ap.MustParse("1", prec)
-		lnb := ap.New(prec).Log(b)
-		fz := f(z)
-		fp := ap.New(prec).Mul(lnb, fz)
-		return ap.New(prec).Sub(one, fp)
-	}
-	z0 := ap.MustParse("1", prec)
-	z := z0
-	for i := 0; i < 100; i++ {
-		gz := g(z)
-		gprime := gp(z)
-		step := ap.New(prec).Div(gz, gprime)
-		z = ap.New(prec).Sub(z, step)
-		if diffSmall(gz, ap.MustParse("0", prec), prec, 30) {
-			break
-		}
-	}
-	zstar = z
+// findAttractingFixedPoint locates z* = b^{z*} with |λ|<1 where λ = ln(b)*z*.
+// The fixed points are z* = -W_k(-ln b)/ln b with λ = -W_k(-ln b); the unit disk
+// lies in the range of W_0, so only k = 0 can be attracting.
+func findAttractingFixedPoint(b *ap.Complex, prec uint) (zstar, lam *ap.Complex, ok bool) {
 	lnb := ap.New(prec).Log(b)
-	lam = ap.New(prec).Mul(lnb, zstar)
+	lam = ap.New(prec).Neg(ap.LambertW(0, ap.New(prec).Neg(lnb)))
 	if absFloat(lam, prec) < 1 {
+		zstar = ap.New(prec).Div(lam, lnb)
 		return zstar, lam, true
 	}
 	return nil, nil, false
@@ -261,40 +234,41 @@ func newtonSolvePhiEqWithLnB(f func(*ap.Complex) *ap.Complex, zstar, lam, y, w0
 
 // powerTower computes f^{∘n}(1) for integer n >= 0 (right-associated tower).
 func powerTower(b *ap.Complex, n int, prec uint) *ap.Complex {
+	step := powStep.NewEvaluator(&ap.Context{Prec: prec})
+	defer step.Close()
+	lnb := ap.New(prec).Log(b)
 	x := ap.MustParse("1", prec)
 	for i := 0; i < n; i++ {
-		x = ap.New(prec).Exp(ap.New(prec).Mul(ap.New(prec).Log(b), x))
+		step.Eval(x, lnb, x)
 	}
 	return x
 }
 
 // tryIntegerHeight returns (n, true) if h is a real integer within small tolerance.
 func tryIntegerHeight(h *ap.Complex, prec uint) (int, bool) {
-	// Check imag ~ 0 and real ~ integer
-	im := h.ImagStringFixed(0)
-	if strings.TrimSpace(im) != "0" { return 0, false }
-	reStr := h.RealStringFixed(0)
-	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(reStr, "+")))
-	if err != nil { return 0, false }
-	return n, true
+	// Compare h against the nearest integer on the real axis.
+	re := h.Re()
+	defer re.Close()
+	n := ap.NewReal(prec).Round(re)
+	defer n.Close()
+	if !n.IsInt() || n.CmpAbs(maxIntHeight) > 0 { return 0, false }
+	m := ap.New(prec).SetReals(n, nil)
+	defer m.Close()
+	if !h.WithinAbs(m, intHeightTol) { return 0, false }
+	return int(n.Int64()), true
 }
 
 // Helpers ------------------------------------------------------------
 
 func diffSmall(a, b *ap.Complex, prec uint, digits int) bool {
-	d := ap.New(prec).Sub(a, b)
-	mag := absFloat(d, prec)
-	// threshold ~ 10^{-digits}
-	thr := math.Pow10(-digits) // Go 1.20+: beware Pow10 only for ints; implement via Pow(10,-digits)
-	thr = math.Pow(10, float64(-digits))
-	return mag < thr
+	// threshold ~ 10^{-digits}, compared at full precision
+	thr := ap.MustParseReal("1e-"+strconvI(digits), 64)
+	defer thr.Close()
+	return a.WithinAbs(b, thr)
 }
 
 func absFloat(a *ap.Complex, prec uint) float64 {
-	tmp := ap.New(prec)
-	s := tmp.AbsStringFixed(a, 30)
-	val, _ := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(s, "+")), 64)
-	return val
+	return a.AbsFloat64()
 }
 
 func isApproximatelyOne(b *ap.Complex, prec uint) bool {
@@ -0,0 +1,143 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_total_cmp orders x and y by IEEE 754 totalOrder (-NaN < -Inf < ... < -0 < +0 < ... < +Inf < +NaN).
+static int apc_total_cmp(mpfr_srcptr x, mpfr_srcptr y) {
+    int le = mpfr_total_order_p(x, y), ge = mpfr_total_order_p(y, x);
+    return le && ge ? 0 : (le ? -1 : 1);
+}
+
+// apc_within_ulps reports whether |x - y| <= ulps * ulp at precision prec, where the ulp
+// is taken from the larger of the two exponents.
+static int apc_within_ulps(mpfr_srcptr x, mpfr_srcptr y, unsigned long ulps, mpfr_prec_t prec) {
+    if (mpfr_nan_p(x) || mpfr_nan_p(y)) return 0;
+    if (mpfr_equal_p(x, y)) return 1;
+    if (mpfr_inf_p(x) || mpfr_inf_p(y)) return 0;
+    mpfr_exp_t e;
+    if (mpfr_zero_p(x)) e = mpfr_get_exp(y);
+    else if (mpfr_zero_p(y)) e = mpfr_get_exp(x);
+    else e = mpfr_get_exp(x) > mpfr_get_exp(y) ? mpfr_get_exp(x) : mpfr_get_exp(y);
+    mpfr_prec_t p = mpfr_get_prec(x) > mpfr_get_prec(y) ? mpfr_get_prec(x) : mpfr_get_prec(y);
+    mpfr_t d;
+    mpfr_init2(d, p + 2);
+    mpfr_sub(d, x, y, MPFR_RNDA);
+    mpfr_abs(d, d, MPFR_RNDA);
+    int ok = mpfr_cmp_ui_2exp(d, ulps, e - prec) <= 0;
+    mpfr_clear(d);
+    return ok;
+}
+*/
+import "C"
+
+// Exact comparisons
+
+// Equal reports whether c and b have equal real and imaginary parts (NaN is never equal).
+// Signed zeros compare equal.
+func (c *Complex) Equal(b *Complex) bool {
+	return C.mpfr_equal_p(c.re(), b.re()) != 0 && C.mpfr_equal_p(c.im(), b.im()) != 0
+}
+
+// CmpParts compares c and b part by part (mpc_cmp), returning -1, 0 or +1 for each.
+// Parts involving NaN compare as 0; use IsNaN first when that matters.
+func (c *Complex) CmpParts(b *Complex) (re, im int) {
+	r, i := inexParts(C.mpc_cmp(&c.z[0], &b.z[0]))
+	return int(r), int(i)
+}
+
+// CmpAbs compares |c| and |b| exactly, returning -1, 0 or +1.
+func (c *Complex) CmpAbs(b *Complex) int {
+	switch v := C.mpc_cmp_abs(&c.z[0], &b.z[0]); {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp orders c and b lexicographically by real part, then imaginary part, using IEEE 754
+// totalOrder for each part, so it is a total order (NaNs and signed zeros included):
+// -1 if c < b, 0 if c and b are identical, +1 if c > b.
+func (c *Complex) Cmp(b *Complex) int {
+	if r := C.apc_total_cmp(c.re(), b.re()); r != 0 {
+		return int(r)
+	}
+	return int(C.apc_total_cmp(c.im(), b.im()))
+}
+
+// Cmp is the package-level form of (*Complex).Cmp, usable as slices.SortFunc(xs, apcomplex.Cmp).
+func Cmp(a, b *Complex) int { return a.Cmp(b) }
+
+// Predicates
+func (c *Complex) IsZero() bool { return C.mpfr_zero_p(c.re()) != 0 && C.mpfr_zero_p(c.im()) != 0 }
+
+// IsNaN reports whether either part is NaN.
+func (c *Complex) IsNaN() bool { return C.mpfr_nan_p(c.re()) != 0 || C.mpfr_nan_p(c.im()) != 0 }
+
+// IsInf reports whether either part is infinite (the C99 notion of a complex infinity).
+func (c *Complex) IsInf() bool { return C.mpfr_inf_p(c.re()) != 0 || C.mpfr_inf_p(c.im()) != 0 }
+
+// IsFinite reports whether both parts are finite numbers.
+func (c *Complex) IsFinite() bool {
+	return C.mpfr_number_p(c.re()) != 0 && C.mpfr_number_p(c.im()) != 0
+}
+
+// IsReal reports whether the imaginary part is zero and the real part is not NaN.
+func (c *Complex) IsReal() bool { return C.mpfr_zero_p(c.im()) != 0 && C.mpfr_nan_p(c.re()) == 0 }
+
+// IsImag reports whether the real part is zero and the imaginary part is not NaN.
+func (c *Complex) IsImag() bool { return C.mpfr_zero_p(c.re()) != 0 && C.mpfr_nan_p(c.im()) == 0 }
+
+// Sign returns the sign of c in the lexicographic order: the sign of the real part, or of the
+// imaginary part when the real part is zero. NaN parts count as 0.
+func (c *Complex) Sign() int {
+	if s := C.mpfr_sgn(c.re()); s != 0 {
+		return int(s)
+	}
+	return int(C.mpfr_sgn(c.im()))
+}
+
+// Approximate equality
+
+// WithinAbs reports whether |c - b| <= tol.
+func (c *Complex) WithinAbs(b *Complex, tol *Real) bool {
+	d := c.distance(b)
+	defer d.Close()
+	return C.mpfr_lessequal_p(d.ptr(), tol.ptr()) != 0
+}
+
+// WithinRel reports whether |c - b| <= tol * max(|c|, |b|).
+func (c *Complex) WithinRel(b *Complex, tol *Real) bool {
+	d := c.distance(b)
+	defer d.Close()
+	m := c.Abs()
+	defer m.Close()
+	if ab := b.Abs(); ab.Cmp(m) > 0 {
+		m.Set(ab)
+		ab.Close()
+	}
+	m.Mul(m, tol, RNDU)
+	return C.mpfr_lessequal_p(d.ptr(), m.ptr()) != 0
+}
+
+// WithinULP reports whether each part of b is within ulps units in the last place of the
+// corresponding part of c, measured at c's precision.
+func (c *Complex) WithinULP(b *Complex, ulps uint) bool {
+	p := C.mpfr_prec_t(c.prec)
+	return C.apc_within_ulps(c.re(), b.re(), C.ulong(ulps), p) != 0 &&
+		C.apc_within_ulps(c.im(), b.im(), C.ulong(ulps), p) != 0
+}
+
+// distance returns |c - b| rounded up, at the larger of the two precisions.
+func (c *Complex) distance(b *Complex) *Real {
+	p := c.prec
+	if b.prec > p {
+		p = b.prec
+	}
+	diff := New(p).Sub(c, b, RNDAA)
+	defer diff.Close()
+	return NewReal(p).SetMode(RNDU).AbsOf(diff)
+}
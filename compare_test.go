@@ -0,0 +1,71 @@
+package apcomplex
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExactComparisons(t *testing.T) {
+	a := tp("1.5-2i")
+	b := tp("1.5+3i")
+	if a.Equal(b) || !a.Equal(tp("1.5-2i")) {
+		t.Fatalf("Equal mismatch")
+	}
+	if re, im := a.CmpParts(b); re != 0 || im != -1 {
+		t.Fatalf("CmpParts = %d,%d", re, im)
+	}
+	if a.CmpAbs(b) != -1 || tp("3+4i").CmpAbs(tp("-5")) != 0 {
+		t.Fatalf("CmpAbs mismatch")
+	}
+	nan := New(64) // fresh values are NaN
+	if nan.Equal(nan) || !nan.IsNaN() || nan.IsFinite() {
+		t.Fatalf("NaN predicates")
+	}
+	if !tp("0").IsZero() || !tp("2").IsReal() || tp("2+1i").IsReal() || !tp("-3i").IsImag() {
+		t.Fatalf("zero/real/imag predicates")
+	}
+	inf := New(64).SetFloat64(1, 0)
+	inf.Div(inf, tp("0"))
+	if !inf.IsInf() {
+		t.Fatalf("1/0 should be infinite, got %s", inf.StringScientific(3))
+	}
+	if tp("-0+5i").Sign() != 1 || tp("-2+5i").Sign() != -1 || tp("0").Sign() != 0 {
+		t.Fatalf("Sign mismatch")
+	}
+}
+
+func TestTotalOrderSort(t *testing.T) {
+	xs := []*Complex{tp("2"), tp("1+1i"), New(64), tp("1-1i"), tp("-3+7i"), tp("1")}
+	slices.SortFunc(xs, Cmp)
+	var got []string
+	for _, x := range xs[:len(xs)-1] {
+		got = append(got, x.StringFixed(0))
+	}
+	want := []string{"-3+7i", "1-1i", "1+0i", "1+1i", "2+0i"}
+	if !slices.Equal(got, want) || !xs[len(xs)-1].IsNaN() {
+		t.Fatalf("sorted = %v (last %s)", got, xs[len(xs)-1].StringFixed(0))
+	}
+	if tp("-0").Cmp(tp("0")) != -1 {
+		t.Fatalf("-0 must sort before +0 in the total order")
+	}
+}
+
+func TestApproximateEquality(t *testing.T) {
+	one := MustParse("1", 128)
+	third := Div(one, MustParse("3", 128))
+	back := Mul(third, MustParse("3", 128))
+	if !back.WithinULP(one, 1) || !back.WithinAbs(one, MustParseReal("1e-35", 64)) {
+		t.Fatalf("3*(1/3) should be within 1 ulp of 1: %s", back.StringScientific(45))
+	}
+	a := MustParse("1e100+1e100i", 128)
+	b := Add(a, MustParse("1e80", 128))
+	if !a.WithinRel(b, MustParseReal("1e-19", 64)) || a.WithinRel(b, MustParseReal("1e-21", 64)) {
+		t.Fatalf("relative tolerance misjudged")
+	}
+	if a.WithinAbs(b, MustParseReal("1e79", 64)) {
+		t.Fatalf("absolute tolerance misjudged")
+	}
+	if a.WithinULP(b, 1<<20) {
+		t.Fatalf("1e80 apart is far more than 2^20 ulps at 128 bits")
+	}
+}
@@ -0,0 +1,160 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_const_compute sets x to constant k (numbered as Constant) rounded to nearest. It
+// widens the exponent range for the computation, so that a caller's narrow range cannot
+// turn the cached value into an infinity or zero, and restores the range and flags.
+static void apc_const_compute(mpfr_ptr x, int k) {
+    mpfr_exp_t emin = mpfr_get_emin(), emax = mpfr_get_emax();
+    mpfr_flags_t flags = mpfr_flags_save();
+    mpfr_set_emin(mpfr_get_emin_min());
+    mpfr_set_emax(mpfr_get_emax_max());
+    switch (k) {
+    case 0:
+        mpfr_const_pi(x, MPFR_RNDN);
+        break;
+    case 1:
+        mpfr_set_ui(x, 1, MPFR_RNDN);
+        mpfr_exp(x, x, MPFR_RNDN);
+        break;
+    case 2:
+        mpfr_const_euler(x, MPFR_RNDN);
+        break;
+    case 3:
+        mpfr_const_catalan(x, MPFR_RNDN);
+        break;
+    case 4:
+        mpfr_const_log2(x, MPFR_RNDN);
+        break;
+    }
+    mpfr_set_emin(emin);
+    mpfr_set_emax(emax);
+    mpfr_flags_restore(flags, MPFR_FLAGS_ALL);
+}
+*/
+import "C"
+
+import "sync"
+
+// Constant identifies a mathematical constant for SetConst.
+type Constant uint8
+
+const (
+	ConstPi         Constant = iota // π
+	ConstE                          // e, the base of the natural logarithm
+	ConstEulerGamma                 // Euler–Mascheroni constant γ
+	ConstCatalan                    // Catalan's constant G
+	ConstLog2                       // natural logarithm of 2
+)
+
+func (k Constant) String() string {
+	switch k {
+	case ConstPi:
+		return "pi"
+	case ConstE:
+		return "e"
+	case ConstEulerGamma:
+		return "gamma"
+	case ConstCatalan:
+		return "catalan"
+	case ConstLog2:
+		return "log2"
+	}
+	return "Constant(?)"
+}
+
+// constGuard is how many bits beyond the requested precision a constant is computed
+// with, so that smaller requests can be rounded correctly from the cached value.
+const constGuard = 32
+
+// constCache holds the most precise value of one constant computed so far, rounded to
+// nearest. Values at or below its precision are rounded from it; a higher precision
+// recomputes it.
+type constCache struct {
+	mu   sync.Mutex
+	x    C.mpfr_t
+	prec uint // 0 until first computed
+}
+
+// constCaches are allocated on the heap. An mpfr_t holds a pointer, so the cgo pointer
+// check inspects the memory &cc.x[0] points to; for memory inside a global variable it
+// cannot find the object's bounds and panics, as it does not for heap objects.
+var constCaches = func() (cs [ConstLog2 + 1]*constCache) {
+	for i := range cs {
+		cs[i] = new(constCache)
+	}
+	return cs
+}()
+
+// get sets x to k correctly rounded with rnd and returns the ternary value. The cache
+// is computed under the full exponent range; only the final rounding into x follows the
+// caller's range and flags.
+func (k Constant) get(x *C.__mpfr_struct, rnd C.mpfr_rnd_t) C.int {
+	if int(k) >= len(constCaches) {
+		panic("apcomplex: unknown constant " + k.String())
+	}
+	p := C.mpfr_prec_t(C.mpfr_get_prec(x))
+	if rnd == C.MPFR_RNDN {
+		p++ // so that the ternary value is right too
+	}
+	cc := constCaches[k]
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	for {
+		// The cached value is within half an ulp, so 2^(EXP-prec) bounds its error.
+		if cc.prec != 0 && C.mpfr_can_round(&cc.x[0], C.mpfr_exp_t(cc.prec), C.MPFR_RNDN, rnd, p) != 0 {
+			return C.mpfr_set(x, &cc.x[0], rnd)
+		}
+		want := uint(p) + constGuard
+		if want <= cc.prec {
+			want = 2 * cc.prec
+		}
+		if cc.prec == 0 {
+			C.mpfr_init2(&cc.x[0], C.mpfr_prec_t(want))
+		} else {
+			C.mpfr_set_prec(&cc.x[0], C.mpfr_prec_t(want))
+		}
+		cc.prec = want
+		C.apc_const_compute(&cc.x[0], C.int(k))
+	}
+}
+
+// SetConst sets r to the constant k, correctly rounded, and returns r. Constants are
+// cached process-wide at the highest precision requested so far (plus guard bits), so
+// repeated requests cost a rounding.
+func (r *Real) SetConst(k Constant, mode ...Rnd) *Real {
+	r.inex = k.get(r.ptr(), r.rnd(mode))
+	return r
+}
+
+// SetConst sets c to the real constant k, correctly rounded, and returns c.
+func (c *Complex) SetConst(k Constant, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	tr := k.get(c.re(), m.Re().mpfr())
+	C.mpfr_set_zero(c.im(), 1)
+	c.inex = inexOf(tr, 0)
+	return c
+}
+
+// Pi returns π at the given precision (0 means DefaultPrec), rounded to nearest.
+func Pi(prec uint) *Complex { return New(prec).SetConst(ConstPi) }
+
+// E returns e at the given precision, rounded to nearest.
+func E(prec uint) *Complex { return New(prec).SetConst(ConstE) }
+
+// EulerGamma returns the Euler–Mascheroni constant γ = 0.5772… at the given precision,
+// rounded to nearest.
+func EulerGamma(prec uint) *Complex { return New(prec).SetConst(ConstEulerGamma) }
+
+// Catalan returns Catalan's constant G = 0.9159… at the given precision, rounded to
+// nearest.
+func Catalan(prec uint) *Complex { return New(prec).SetConst(ConstCatalan) }
+
+// Log2 returns log 2 at the given precision, rounded to nearest.
+func Log2(prec uint) *Complex { return New(prec).SetConst(ConstLog2) }
+
+// I returns the imaginary unit at the given precision.
+func I(prec uint) *Complex { return New(prec).SetInt64(0, 1) }
@@ -0,0 +1,113 @@
+package apcomplex
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConstantDigits(t *testing.T) {
+	tests := []struct {
+		f    func(uint) *Complex
+		want string
+	}{
+		{Pi, "3.14159265358979323846264338327950288419716939937510"},
+		{E, "2.71828182845904523536028747135266249775724709369995"},
+		{EulerGamma, "0.57721566490153286060651209008240243104215933593992"},
+		{Catalan, "0.91596559417721901505460351493238411077414937428167"},
+		{Log2, "0.69314718055994530941723212145817656807550013436025"},
+	}
+	for _, tt := range tests {
+		z := tt.f(200)
+		if got := z.Re().Text('f', 55); !strings.HasPrefix(got, tt.want[:48]) {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+		if !z.Im().IsZero() || z.Prec() != 200 {
+			t.Errorf("%v: imaginary part or precision wrong", z)
+		}
+	}
+	if z := I(64); !z.Equal(MustParse("i", 64)) || !z.IsExact() {
+		t.Errorf("I = %v", z)
+	}
+}
+
+func TestConstantRounding(t *testing.T) {
+	tests := []struct {
+		k    Constant
+		want float64
+	}{
+		{ConstPi, math.Pi},
+		{ConstE, math.E},
+		{ConstLog2, math.Ln2},
+		{ConstEulerGamma, 0.5772156649015329},
+		{ConstCatalan, 0.915965594177219},
+	}
+	for _, tt := range tests {
+		// Interleave precisions so that some requests are rounded from the cache.
+		for _, prec := range []uint{2000, 53, 24, 300} {
+			down := NewReal(prec).SetConst(tt.k, RNDD)
+			up := NewReal(prec).SetConst(tt.k, RNDU)
+			near := NewReal(prec).SetConst(tt.k)
+			if down.Acc() != Below || up.Acc() != Above || near.Acc() == Exact {
+				t.Errorf("%v at %d bits: accuracy %v %v %v", tt.k, prec, down.Acc(), up.Acc(), near.Acc())
+			}
+			lo, hi := New(prec).SetReals(down, nil), New(prec).SetReals(up, nil)
+			if !lo.WithinULP(hi, 1) || down.Cmp(up) == 0 {
+				t.Errorf("%v at %d bits: %v and %v are not adjacent", tt.k, prec, down, up)
+			}
+			if near.Cmp(down) != 0 && near.Cmp(up) != 0 {
+				t.Errorf("%v at %d bits: nearest %v outside [%v, %v]", tt.k, prec, near, down, up)
+			}
+			if prec == 53 && near.Float64() != tt.want {
+				t.Errorf("%v at 53 bits = %v, want %v", tt.k, near.Float64(), tt.want)
+			}
+		}
+	}
+}
+
+func TestConstantCache(t *testing.T) {
+	Pi(100)
+	before := constCaches[ConstPi].prec
+	if before < 101 {
+		t.Fatalf("cached π has %d bits after a 100-bit request", before)
+	}
+	Pi(64)
+	if constCaches[ConstPi].prec != before {
+		t.Errorf("a lower precision recomputed π")
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for p := uint(2); p < 400; p += 7 {
+				want := New(p + uint(g))
+				want.Acos(New(p+uint(g)).SetInt64(-1, 0))
+				if got := Pi(p + uint(g)); !got.WithinULP(want, 1) {
+					t.Errorf("Pi(%d) = %v, acos(-1) = %v", p+uint(g), got, want)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if constCaches[ConstPi].prec < 400 {
+		t.Errorf("cached π has %d bits after a 400-bit request", constCaches[ConstPi].prec)
+	}
+}
+
+func TestConstantNarrowRange(t *testing.T) {
+	// The cache is filled under the full exponent range, so a request whose result
+	// overflows the caller's range still ends; only that result is infinite.
+	ctx := &Context{Prec: 12345, Emax: 1}
+	z, err := Eval("pi", ctx)
+	if err != nil || !z.IsInf() {
+		t.Fatalf("pi with emax 1 = %v, %v", z, err)
+	}
+	if z, err := Eval("pi", &Context{Prec: 12345, Emax: 2}); err != nil || !z.Equal(Pi(12345)) {
+		t.Errorf("pi with emax 2 = %v, %v", z, err)
+	}
+	if constCaches[ConstPi].prec < 12345 {
+		t.Errorf("cached π has %d bits", constCaches[ConstPi].prec)
+	}
+}
@@ -0,0 +1,239 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+
+// Range checks need the component pointers (mpc_realref/mpc_imagref are macros).
+static int apc_check_range(mpc_ptr z, int inex, mpc_rnd_t rnd) {
+    int tr = mpfr_check_range(mpc_realref(z), MPC_INEX_RE(inex), MPC_RND_RE(rnd));
+    int ti = mpfr_check_range(mpc_imagref(z), MPC_INEX_IM(inex), MPC_RND_IM(rnd));
+    return MPC_INEX(tr, ti);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Flags is a set of MPFR exception flags raised while evaluating an operation.
+type Flags uint
+
+// Exception flags (values match MPFR_FLAGS_*).
+const (
+	FlagUnderflow Flags = C.MPFR_FLAGS_UNDERFLOW
+	FlagOverflow  Flags = C.MPFR_FLAGS_OVERFLOW
+	FlagNaN       Flags = C.MPFR_FLAGS_NAN
+	FlagInexact   Flags = C.MPFR_FLAGS_INEXACT
+	FlagERange    Flags = C.MPFR_FLAGS_ERANGE
+	FlagDivByZero Flags = C.MPFR_FLAGS_DIVBY0
+)
+
+func (f Flags) String() string {
+	if f == 0 {
+		return "none"
+	}
+	names := []struct {
+		f Flags
+		s string
+	}{
+		{FlagUnderflow, "underflow"},
+		{FlagOverflow, "overflow"},
+		{FlagNaN, "nan"},
+		{FlagInexact, "inexact"},
+		{FlagERange, "erange"},
+		{FlagDivByZero, "divbyzero"},
+	}
+	var out []string
+	for _, n := range names {
+		if f&n.f != 0 {
+			out = append(out, n.s)
+		}
+	}
+	return strings.Join(out, "|")
+}
+
+// Context bundles the settings used to allocate values and run operations: precision,
+// rounding mode, exponent range and the set of exception flags that are turned into errors.
+//
+// A Context holds no mutable state, so the same Context may be shared by many goroutines,
+// and different goroutines may use different Contexts at the same time. A nil *Context
+// uses DefaultPrec, RNDNN, MPFR's current exponent range and no traps.
+type Context struct {
+	Prec  uint         // precision in bits; 0 means DefaultPrec
+	Mode  RoundingMode // rounding mode of allocated values and results
+	Emin  int64        // smallest allowed exponent; 0 keeps MPFR's current limit
+	Emax  int64        // largest allowed exponent; 0 keeps MPFR's current limit
+	Traps Flags        // flags reported as a *ContextError
+}
+
+// ContextError reports trapped exception flags raised by a Context operation.
+type ContextError struct {
+	Op    string
+	Flags Flags
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("apcomplex: %s raised %s", e.Op, e.Flags)
+}
+
+func (ctx *Context) prec() uint {
+	if ctx == nil || ctx.Prec == 0 {
+		return DefaultPrec
+	}
+	return ctx.Prec
+}
+
+func (ctx *Context) mode() RoundingMode {
+	if ctx == nil {
+		return RNDNN
+	}
+	return ctx.Mode
+}
+
+// New allocates a zero value with the context's precision and rounding mode.
+func (ctx *Context) New() *Complex {
+	z := New(ctx.prec()).SetMode(ctx.mode())
+	C.mpc_set_ui(&z.z[0], 0, z.mode.mpc())
+	return z
+}
+
+// Parse parses a complex literal (see Parse) into a new value of this context.
+func (ctx *Context) Parse(s string) (*Complex, error) {
+	z := ctx.New()
+	var perr error
+	err := ctx.run("parse", z, func() { perr = z.SetString(s) })
+	if perr != nil {
+		z.Close()
+		return nil, perr
+	}
+	return z, err
+}
+
+// run evaluates f, which stores its result into z, under the context's exponent range and
+// reports trapped flags. The OS thread is locked because MPFR keeps the exponent range
+// and flags in thread-local state.
+func (ctx *Context) run(op string, z *Complex, f func()) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	saved := C.mpfr_flags_save()
+	emin, emax := C.mpfr_get_emin(), C.mpfr_get_emax()
+	defer func() {
+		C.mpfr_set_emin(emin)
+		C.mpfr_set_emax(emax)
+		C.mpfr_flags_restore(saved, C.MPFR_FLAGS_ALL)
+	}()
+	ranged := ctx != nil && (ctx.Emin != 0 || ctx.Emax != 0)
+	if ranged {
+		if ctx.Emin != 0 && C.mpfr_set_emin(C.mpfr_exp_t(ctx.Emin)) != 0 {
+			return fmt.Errorf("apcomplex: emin %d out of range", ctx.Emin)
+		}
+		if ctx.Emax != 0 && C.mpfr_set_emax(C.mpfr_exp_t(ctx.Emax)) != 0 {
+			return fmt.Errorf("apcomplex: emax %d out of range", ctx.Emax)
+		}
+	}
+	C.mpfr_clear_flags()
+	f()
+	if ranged {
+		z.inex = C.apc_check_range(&z.z[0], z.inex, z.mode.mpc())
+	}
+	raised := Flags(C.mpfr_flags_test(C.MPFR_FLAGS_ALL))
+	if ctx != nil && raised&ctx.Traps != 0 {
+		return &ContextError{Op: op, Flags: raised & ctx.Traps}
+	}
+	return nil
+}
+
+// unary and binary allocate a result in the context and evaluate op into it. The result is
+// returned even when a trap fires, together with the *ContextError.
+func (ctx *Context) unary(op string, a *Complex, f func(z, a *Complex) *Complex) (*Complex, error) {
+	z := ctx.New()
+	err := ctx.run(op, z, func() { f(z, a) })
+	return z, err
+}
+
+func (ctx *Context) binary(op string, a, b *Complex, f func(z, a, b *Complex) *Complex) (*Complex, error) {
+	z := ctx.New()
+	err := ctx.run(op, z, func() { f(z, a, b) })
+	return z, err
+}
+
+// Algebraic ops
+func (ctx *Context) Set(a *Complex) (*Complex, error) {
+	return ctx.unary("set", a, func(z, a *Complex) *Complex { return z.Set(a) })
+}
+func (ctx *Context) Add(a, b *Complex) (*Complex, error) {
+	return ctx.binary("add", a, b, func(z, a, b *Complex) *Complex { return z.Add(a, b) })
+}
+func (ctx *Context) Sub(a, b *Complex) (*Complex, error) {
+	return ctx.binary("sub", a, b, func(z, a, b *Complex) *Complex { return z.Sub(a, b) })
+}
+func (ctx *Context) Mul(a, b *Complex) (*Complex, error) {
+	return ctx.binary("mul", a, b, func(z, a, b *Complex) *Complex { return z.Mul(a, b) })
+}
+func (ctx *Context) Div(a, b *Complex) (*Complex, error) {
+	return ctx.binary("div", a, b, func(z, a, b *Complex) *Complex { return z.Div(a, b) })
+}
+func (ctx *Context) Pow(a, b *Complex) (*Complex, error) {
+	return ctx.binary("pow", a, b, func(z, a, b *Complex) *Complex { return z.Pow(a, b) })
+}
+func (ctx *Context) Neg(a *Complex) (*Complex, error) {
+	return ctx.unary("neg", a, func(z, a *Complex) *Complex { return z.Neg(a) })
+}
+func (ctx *Context) Conj(a *Complex) (*Complex, error) {
+	return ctx.unary("conj", a, func(z, a *Complex) *Complex { return z.Conj(a) })
+}
+func (ctx *Context) Inv(a *Complex) (*Complex, error) {
+	return ctx.unary("inv", a, func(z, a *Complex) *Complex { return z.Inv(a) })
+}
+
+// Elementary/transcendental
+func (ctx *Context) Sqrt(a *Complex) (*Complex, error) {
+	return ctx.unary("sqrt", a, func(z, a *Complex) *Complex { return z.Sqrt(a) })
+}
+func (ctx *Context) Exp(a *Complex) (*Complex, error) {
+	return ctx.unary("exp", a, func(z, a *Complex) *Complex { return z.Exp(a) })
+}
+func (ctx *Context) Log(a *Complex) (*Complex, error) {
+	return ctx.unary("log", a, func(z, a *Complex) *Complex { return z.Log(a) })
+}
+func (ctx *Context) Sin(a *Complex) (*Complex, error) {
+	return ctx.unary("sin", a, func(z, a *Complex) *Complex { return z.Sin(a) })
+}
+func (ctx *Context) Cos(a *Complex) (*Complex, error) {
+	return ctx.unary("cos", a, func(z, a *Complex) *Complex { return z.Cos(a) })
+}
+func (ctx *Context) Tan(a *Complex) (*Complex, error) {
+	return ctx.unary("tan", a, func(z, a *Complex) *Complex { return z.Tan(a) })
+}
+func (ctx *Context) Asin(a *Complex) (*Complex, error) {
+	return ctx.unary("asin", a, func(z, a *Complex) *Complex { return z.Asin(a) })
+}
+func (ctx *Context) Acos(a *Complex) (*Complex, error) {
+	return ctx.unary("acos", a, func(z, a *Complex) *Complex { return z.Acos(a) })
+}
+func (ctx *Context) Atan(a *Complex) (*Complex, error) {
+	return ctx.unary("atan", a, func(z, a *Complex) *Complex { return z.Atan(a) })
+}
+func (ctx *Context) Sinh(a *Complex) (*Complex, error) {
+	return ctx.unary("sinh", a, func(z, a *Complex) *Complex { return z.Sinh(a) })
+}
+func (ctx *Context) Cosh(a *Complex) (*Complex, error) {
+	return ctx.unary("cosh", a, func(z, a *Complex) *Complex { return z.Cosh(a) })
+}
+func (ctx *Context) Tanh(a *Complex) (*Complex, error) {
+	return ctx.unary("tanh", a, func(z, a *Complex) *Complex { return z.Tanh(a) })
+}
+func (ctx *Context) Asinh(a *Complex) (*Complex, error) {
+	return ctx.unary("asinh", a, func(z, a *Complex) *Complex { return z.Asinh(a) })
+}
+func (ctx *Context) Acosh(a *Complex) (*Complex, error) {
+	return ctx.unary("acosh", a, func(z, a *Complex) *Complex { return z.Acosh(a) })
+}
+func (ctx *Context) Atanh(a *Complex) (*Complex, error) {
+	return ctx.unary("atanh", a, func(z, a *Complex) *Complex { return z.Atanh(a) })
+}
@@ -0,0 +1,93 @@
+package apcomplex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestContextAllocation(t *testing.T) {
+	ctx := &Context{Prec: 96, Mode: RNDZZ}
+	z, err := ctx.Parse("1.25-3i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z.Prec() != 96 || z.Mode() != RNDZZ {
+		t.Fatalf("Parse ignored context: prec=%d mode=%v", z.Prec(), z.Mode())
+	}
+	w, err := ctx.Mul(z, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Prec() != 96 || !equalApprox(w, tp("-7.4375-7.5i"), 1e-25) {
+		t.Fatalf("ctx.Mul = %s (prec %d)", w.StringFixed(10), w.Prec())
+	}
+	var nilCtx *Context
+	if v := nilCtx.New(); v.Prec() != DefaultPrec {
+		t.Fatalf("nil context should use DefaultPrec, got %d", v.Prec())
+	}
+	if _, err := ctx.Parse("nope"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestContextTraps(t *testing.T) {
+	ctx := &Context{Prec: 64, Traps: FlagInexact}
+	one, _ := ctx.Parse("1")
+	three, _ := ctx.Parse("3")
+	q, err := ctx.Div(one, three)
+	var ce *ContextError
+	if !errors.As(err, &ce) || ce.Flags&FlagInexact == 0 || ce.Op != "div" {
+		t.Fatalf("expected inexact trap from div, got %v", err)
+	}
+	if q == nil {
+		t.Fatalf("result must be returned with the trap error")
+	}
+	if _, err := ctx.Add(one, three); err != nil {
+		t.Fatalf("1+3 is exact, got %v", err)
+	}
+}
+
+func TestContextExponentRange(t *testing.T) {
+	ctx := &Context{Prec: 64, Emax: 100, Traps: FlagOverflow}
+	big, err := ctx.Parse("1e25") // ~2^83, within range
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctx.Mul(big, big); err == nil {
+		t.Fatalf("expected overflow with emax=100")
+	}
+	// The default range must be untouched afterwards.
+	if _, err := (&Context{Prec: 64, Traps: FlagOverflow}).Mul(big, big); err != nil {
+		t.Fatalf("exponent range leaked out of context: %v", err)
+	}
+}
+
+// Contexts with different settings used concurrently must not interfere.
+func TestContextConcurrent(t *testing.T) {
+	narrow := &Context{Prec: 64, Emax: 50, Traps: FlagOverflow}
+	wide := &Context{Prec: 64, Traps: FlagOverflow}
+	x := MustParse("1e10", 64)
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 32; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := narrow.Mul(x, x); err == nil {
+				errs <- errors.New("narrow context did not overflow")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := wide.Mul(x, x); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,216 @@
+package apcomplex
+
+/*
+#include <stdlib.h>
+#include <gmp.h>
+#include <mpc.h>
+#include <mpfr.h>
+
+// Big-endian magnitude transfer between GMP integers and Go byte slices (mpz_sgn is a macro).
+static void apc_mpz_import(mpz_ptr z, const void *b, size_t n, int neg) {
+    mpz_import(z, n, 1, 1, 1, 0, b);
+    if (neg) mpz_neg(z, z);
+}
+static size_t apc_mpz_nbytes(mpz_srcptr z) {
+    return mpz_sgn(z) == 0 ? 0 : (mpz_sizeinbase(z, 2) + 7) / 8;
+}
+static void apc_mpz_export(void *b, mpz_srcptr z) { mpz_export(b, NULL, 1, 1, 1, 0, z); }
+static int apc_mpz_sgn(mpz_srcptr z) { return mpz_sgn(z); }
+*/
+import "C"
+
+import (
+	"math/big"
+	"unsafe"
+)
+
+// setMpz stores x into the initialized GMP integer z.
+func setMpz(z *C.__mpz_struct, x *big.Int) {
+	b := x.Bytes()
+	if len(b) == 0 {
+		C.mpz_set_ui(z, 0)
+		return
+	}
+	neg := C.int(0)
+	if x.Sign() < 0 {
+		neg = 1
+	}
+	C.apc_mpz_import(z, unsafe.Pointer(&b[0]), C.size_t(len(b)), neg)
+}
+
+// mpzInt converts a GMP integer into a new *big.Int.
+func mpzInt(z *C.__mpz_struct) *big.Int {
+	out := new(big.Int)
+	n := C.apc_mpz_nbytes(z)
+	if n == 0 {
+		return out
+	}
+	buf := make([]byte, int(n))
+	C.apc_mpz_export(unsafe.Pointer(&buf[0]), z)
+	out.SetBytes(buf)
+	if C.apc_mpz_sgn(z) < 0 {
+		out.Neg(out)
+	}
+	return out
+}
+
+// mpfrMantExp splits a regular (finite, non-zero) MPFR number into x = mant * 2^exp,
+// where mant is an integer with at most prec(x) bits.
+func mpfrMantExp(x *C.__mpfr_struct) (mant *big.Int, exp int64) {
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	e := C.mpfr_get_z_2exp(&z[0], x)
+	return mpzInt(&z[0]), int64(e)
+}
+
+// setMpfrMantExp sets x = mant * 2^exp rounded with r and returns the ternary value.
+func setMpfrMantExp(x *C.__mpfr_struct, mant *big.Int, exp int64, r Rnd) C.int {
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	setMpz(&z[0], mant)
+	return C.mpfr_set_z_2exp(x, &z[0], C.mpfr_exp_t(exp), r.mpfr())
+}
+
+// mpfrToBigFloat converts x exactly into a *big.Float of the same precision.
+// It returns nil for NaN, which big.Float cannot represent.
+func mpfrToBigFloat(x *C.__mpfr_struct) *big.Float {
+	f := new(big.Float).SetPrec(uint(C.mpfr_get_prec(x)))
+	neg := C.mpfr_signbit(x) != 0
+	switch {
+	case C.mpfr_nan_p(x) != 0:
+		return nil
+	case C.mpfr_inf_p(x) != 0:
+		return f.SetInf(neg)
+	case C.mpfr_zero_p(x) != 0:
+		if neg {
+			return f.Neg(f)
+		}
+		return f
+	}
+	mant, exp := mpfrMantExp(x)
+	f.SetInt(mant)
+	return f.SetMantExp(f, int(exp))
+}
+
+// setMpfrBigFloat sets x = f (nil means 0) rounded with r and returns the ternary value.
+func setMpfrBigFloat(x *C.__mpfr_struct, f *big.Float, r Rnd) C.int {
+	sign := C.int(1)
+	if f != nil && f.Signbit() {
+		sign = -1
+	}
+	switch {
+	case f == nil || f.Sign() == 0:
+		C.mpfr_set_zero(x, sign)
+		return 0
+	case f.IsInf():
+		C.mpfr_set_inf(x, sign)
+		return 0
+	}
+	// f = m * 2^exp with 0.5 <= |m| < 1; scale m to an integer of MinPrec bits.
+	m := new(big.Float)
+	exp := f.MantExp(m)
+	bits := int(m.MinPrec())
+	m.SetMantExp(m, bits)
+	mant, _ := m.Int(nil)
+	return setMpfrMantExp(x, mant, int64(exp-bits), r)
+}
+
+// mpfrToBigRat converts a finite x exactly into a *big.Rat; nil for NaN and infinities.
+func mpfrToBigRat(x *C.__mpfr_struct) *big.Rat {
+	if C.mpfr_number_p(x) == 0 {
+		return nil
+	}
+	if C.mpfr_zero_p(x) != 0 {
+		return new(big.Rat)
+	}
+	mant, exp := mpfrMantExp(x)
+	if exp >= 0 {
+		return new(big.Rat).SetInt(mant.Lsh(mant, uint(exp)))
+	}
+	den := new(big.Int).Lsh(big.NewInt(1), uint(-exp))
+	return new(big.Rat).SetFrac(mant, den)
+}
+
+// SetComplex128 sets c = z (rounded to c's precision) and returns c.
+func (c *Complex) SetComplex128(z complex128, mode ...RoundingMode) *Complex {
+	return c.SetFloat64(real(z), imag(z), mode...)
+}
+
+// SetFloat64 sets c = re + i*im and returns c.
+func (c *Complex) SetFloat64(re, im float64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_set_d_d(&c.z[0], C.double(re), C.double(im), c.rnd(mode))
+	return c
+}
+
+// SetInt64 sets c = re + i*im and returns c.
+func (c *Complex) SetInt64(re, im int64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_set_si_si(&c.z[0], C.long(re), C.long(im), c.rnd(mode))
+	return c
+}
+
+// SetBigInt sets c = re + i*im (a nil part means 0) and returns c.
+func (c *Complex) SetBigInt(re, im *big.Int, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	set := func(x *C.__mpfr_struct, v *big.Int, r Rnd) C.int {
+		if v == nil {
+			v = new(big.Int)
+		}
+		var z C.mpz_t
+		C.mpz_init(&z[0])
+		defer C.mpz_clear(&z[0])
+		setMpz(&z[0], v)
+		return C.mpfr_set_z(x, &z[0], r.mpfr())
+	}
+	tr := set(c.re(), re, m.Re())
+	ti := set(c.im(), im, m.Im())
+	c.inex = inexOf(tr, ti)
+	return c
+}
+
+// SetBigRat sets c = re + i*im (a nil part means 0), correctly rounded, and returns c.
+func (c *Complex) SetBigRat(re, im *big.Rat, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	set := func(x *C.__mpfr_struct, v *big.Rat, r Rnd) C.int {
+		if v == nil {
+			v = new(big.Rat)
+		}
+		var q C.mpq_t
+		C.mpq_init(&q[0])
+		defer C.mpq_clear(&q[0])
+		setMpz(&q[0]._mp_num, v.Num())
+		setMpz(&q[0]._mp_den, v.Denom())
+		return C.mpfr_set_q(x, &q[0], r.mpfr())
+	}
+	tr := set(c.re(), re, m.Re())
+	ti := set(c.im(), im, m.Im())
+	c.inex = inexOf(tr, ti)
+	return c
+}
+
+// SetBigFloat sets c = re + i*im (a nil part means 0) and returns c. The mantissas are
+// transferred directly, so no decimal round trip is involved.
+func (c *Complex) SetBigFloat(re, im *big.Float, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	tr := setMpfrBigFloat(c.re(), re, m.Re())
+	ti := setMpfrBigFloat(c.im(), im, m.Im())
+	c.inex = inexOf(tr, ti)
+	return c
+}
+
+// Complex128 returns the nearest complex128 (each part rounded with c's rounding mode).
+func (c *Complex) Complex128() complex128 {
+	re := C.mpfr_get_d(c.re(), c.mode.Re().mpfr())
+	im := C.mpfr_get_d(c.im(), c.mode.Im().mpfr())
+	return complex(float64(re), float64(im))
+}
+
+// Real returns the real part as an exact *big.Float with c's precision, or nil if it is NaN.
+func (c *Complex) Real() *big.Float { return mpfrToBigFloat(c.re()) }
+
+// Imag returns the imaginary part as an exact *big.Float with c's precision, or nil if it is NaN.
+func (c *Complex) Imag() *big.Float { return mpfrToBigFloat(c.im()) }
+
+// BigRat returns both parts as exact rationals. A part that is NaN or infinite yields nil.
+func (c *Complex) BigRat() (re, im *big.Rat) { return mpfrToBigRat(c.re()), mpfrToBigRat(c.im()) }
@@ -0,0 +1,80 @@
+package apcomplex
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestComplex128RoundTrip(t *testing.T) {
+	for _, v := range []complex128{0, 1.5 - 2.25i, complex(math.Pi, -math.E), complex(math.Inf(1), math.SmallestNonzeroFloat64)} {
+		z := New(53).SetComplex128(v)
+		if got := z.Complex128(); got != v {
+			t.Fatalf("complex128 round trip: got %v want %v", got, v)
+		}
+		if !z.IsExact() {
+			t.Fatalf("53-bit SetComplex128(%v) should be exact", v)
+		}
+	}
+	if got := New(64).SetInt64(-7, 1<<40).Complex128(); got != complex(-7, 1<<40) {
+		t.Fatalf("SetInt64: got %v", got)
+	}
+}
+
+func TestBigIntAndRat(t *testing.T) {
+	x := new(big.Int).Lsh(big.NewInt(1), 1024)
+	x.Add(x, big.NewInt(1))
+	z := New(2048).SetBigInt(x, new(big.Int).Neg(x))
+	if z.StringFixed(0) != x.String()+"-"+x.String()+"i" {
+		t.Fatalf("SetBigInt lost digits: %s", z.StringFixed(0))
+	}
+	re, im := z.BigRat()
+	if !re.IsInt() || re.Num().Cmp(x) != 0 || im.Num().CmpAbs(x) != 0 || im.Sign() >= 0 {
+		t.Fatalf("BigRat mismatch: %v %v", re, im)
+	}
+
+	third := big.NewRat(1, 3)
+	lo := New(64).SetBigRat(third, nil, RNDDD)
+	hi := New(64).SetBigRat(third, nil, RNDUU)
+	if a, _ := lo.Acc(); a != Below {
+		t.Fatalf("1/3 rounded down should be Below, got %v", a)
+	}
+	lr, _ := lo.BigRat()
+	hr, _ := hi.BigRat()
+	if lr.Cmp(third) >= 0 || hr.Cmp(third) <= 0 {
+		t.Fatalf("rational enclosure failed: %v <= 1/3 <= %v", lr, hr)
+	}
+	q := big.NewRat(-3, 8)
+	if r, _ := New(16).SetBigRat(q, q).BigRat(); r.Cmp(q) != 0 {
+		t.Fatalf("exact dyadic rational changed: %v", r)
+	}
+}
+
+func TestBigFloatRoundTrip(t *testing.T) {
+	re, _, err := big.ParseFloat("3.14159265358979323846264338327950288419716939937510582097494459", 10, 300, big.ToNearestEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := new(big.Float).SetPrec(300).SetMantExp(big.NewFloat(-0.75), -5000)
+	z := New(300).SetBigFloat(re, im)
+	if !z.IsExact() {
+		t.Fatalf("SetBigFloat at equal precision must be exact")
+	}
+	if z.Real().Cmp(re) != 0 || z.Imag().Cmp(im) != 0 {
+		t.Fatalf("big.Float round trip mismatch: %v %v", z.Real(), z.Imag())
+	}
+	if z.Real().Prec() != 300 {
+		t.Fatalf("Real() precision = %d", z.Real().Prec())
+	}
+
+	w := New(64).SetBigFloat(new(big.Float).SetInf(true), new(big.Float).Neg(new(big.Float)))
+	if !w.Real().IsInf() || w.Real().Sign() >= 0 || !w.Imag().Signbit() {
+		t.Fatalf("special values not preserved: %v %v", w.Real(), w.Imag())
+	}
+	if r, _ := w.BigRat(); r != nil {
+		t.Fatalf("infinite part must yield nil rat")
+	}
+	if New(64).Real() != nil { // fresh values are NaN
+		t.Fatalf("NaN part must yield nil big.Float")
+	}
+}
@@ -0,0 +1,158 @@
+package apcomplex
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestErfKnown(t *testing.T) {
+	tol := MustParseReal("1e-19", 64)
+	tests := []struct {
+		name string
+		f    func(*Complex, ...RoundingMode) *Complex
+		z    string
+		want string
+	}{
+		{"erf", Erf, "1+i", "1.3161512816979476448802710+0.1904534692378346862841i"},
+		{"erfc", Erfc, "1+i", "-0.3161512816979476448802710-0.1904534692378346862841i"},
+		{"erfi", Erfi, "1", "1.6504257587975428760253377"},
+		{"w", Faddeeva, "i", "0.4275835761558070044107503"},
+		{"dawson", Dawson, "1", "0.5380795069127684191327974"},
+		{"C", FresnelC, "1", "0.7798934003768228294742064"},
+		{"S", FresnelS, "1", "0.4382591473903547660767566"},
+	}
+	for _, tt := range tests {
+		got := tt.f(MustParse(tt.z, 128))
+		if !got.WithinAbs(MustParse(tt.want, 128), tol) {
+			t.Errorf("%s(%s) = %v, want %s", tt.name, tt.z, got, tt.want)
+		}
+	}
+	// Real arguments are correctly rounded by MPFR.
+	x := MustParse("0.5", 100)
+	if got, want := Erf(x), NewReal(100).Erf(x.Re()); got.Re().Cmp(want) != 0 || !got.Im().IsZero() {
+		t.Errorf("erf(0.5) = %v, want %v", got, want)
+	}
+	specials := []struct {
+		f    func(*Complex, ...RoundingMode) *Complex
+		z    string
+		want float64
+	}{
+		{Erf, "-inf", -1}, {Erfc, "-inf", 2}, {Erfi, "inf", math.Inf(1)},
+		{Faddeeva, "inf", 0}, {Dawson, "-inf", 0}, {FresnelS, "-inf", -0.5},
+	}
+	for i, tt := range specials {
+		if got := tt.f(MustParse(tt.z, 64)).Complex128(); got != complex(tt.want, 0) {
+			t.Errorf("%d: f(%s) = %v, want %v", i, tt.z, got, tt.want)
+		}
+	}
+	if got := Erf(MustParse("1+infi", 64)); !got.IsNaN() {
+		t.Errorf("erf(1+∞i) = %v", got)
+	}
+}
+
+// absClose reports whether |got - want| ≤ 2^(lost-prec) max(|scale|, 1).
+func absClose(got, want, scale *Complex, lost int) bool {
+	tol := scale.Abs()
+	if tol.Cmp(NewReal(64).SetFloat64(1)) < 0 {
+		tol.SetFloat64(1)
+	}
+	tol.Mul(tol, NewReal(64).SetFloat64(math.Ldexp(1, lost-int(want.Prec()))))
+	return Sub(got, want).Abs().Cmp(tol) <= 0
+}
+
+func TestErfIdentities(t *testing.T) {
+	// The points cover the series, the continued fraction and the asymptotic expansion
+	// at both precisions.
+	points := []string{"0.3+0.2i", "2-1i", "5+3i", "12+5i", "-7+0.5i", "0.5+9i", "30-30i", "-3-8i", "1e-20+1e-20i"}
+	for _, prec := range []uint{64, 300} {
+		one := MustParse("1", prec)
+		for _, s := range points {
+			z := MustParse(s, prec)
+			erf, erfc := Erf(z), Erfc(z)
+			if !absClose(Add(erf, erfc), one, erf, 8) {
+				t.Errorf("%d bits: erf(%s) + erfc(%[2]s) = %v", prec, s, Add(erf, erfc))
+			}
+			if got, want := Erf(Conj(z)), Conj(erf); !got.Equal(want) {
+				t.Errorf("%d bits: erf(conj %s) = %v, want %v", prec, s, got, want)
+			}
+			// erfi(z) = -i erf(iz)
+			if got, want := Erfi(z), MulI(Erf(MulI(z, 1)), -1); !got.Equal(want) {
+				t.Errorf("%d bits: erfi(%s) = %v, want %v", prec, s, got, want)
+			}
+			// w(z) = e^(-z²) erfc(-iz)
+			mz2 := Neg(Sqr(z))
+			w := Faddeeva(z)
+			if want := Mul(Exp(mz2), Erfc(MulI(z, -1))); !relClose(w, want, 12) {
+				t.Errorf("%d bits: w(%s) = %v, want %v", prec, s, w, want)
+			}
+			// F(z) = (i√π/2)(e^(-z²) - w(z))
+			f := Dawson(z)
+			want := Mul(MulI(Div(Sqrt(Pi(prec)), MustParse("2", prec)), 1), Sub(Exp(mz2), w))
+			if !absClose(f, want, Exp(mz2), 12) {
+				t.Errorf("%d bits: F(%s) = %v, want %v", prec, s, f, want)
+			}
+		}
+	}
+	// The same values from different algorithms at different precisions.
+	for _, s := range points {
+		lo, hi := MustParse(s, 64), MustParse(s, 400)
+		for name, f := range map[string]func(*Complex, ...RoundingMode) *Complex{"erfc": Erfc, "w": Faddeeva} {
+			want := New(64).Set(f(hi))
+			if got := f(lo); !relClose(got, want, 4) {
+				t.Errorf("%s(%s) = %v at 64 bits, %v at 400", name, s, got, want)
+			}
+		}
+	}
+}
+
+func TestErfcTail(t *testing.T) {
+	// erfc(x + iy) far right is tiny but keeps its relative accuracy against MPFR.
+	x := MustParse("27", 128)
+	got, want := Erfc(Add(x, MustParse("1e-30i", 128))), Erfc(x)
+	if !relClose(New(128).SetReals(got.Re(), nil), want, 16) {
+		t.Errorf("erfc(27 + 1e-30i) = %v, want %v", got, want)
+	}
+	// w(iy) = erfcx(y) = e^(y²) erfc(y)
+	y := MustParse("6.5", 200)
+	w := Faddeeva(MulI(y, 1))
+	if want := Mul(Exp(Sqr(y)), Erfc(y)); !relClose(w, want, 8) || !w.Im().IsZero() {
+		t.Errorf("w(6.5i) = %v, want %v", w, want)
+	}
+}
+
+func TestFresnel(t *testing.T) {
+	for _, s := range []string{"1", "0.7+0.2i", "-2.5+1i", "40", "6-6i"} {
+		z := MustParse(s, 160)
+		sz, cz := Fresnel(z)
+		if !sz.Equal(FresnelS(z)) || !cz.Equal(FresnelC(z)) {
+			t.Errorf("Fresnel(%s) = %v, %v; separately %v, %v", s, sz, cz, FresnelS(z), FresnelC(z))
+		}
+		// C(iz) = iC(z), S(iz) = -iS(z)
+		iz := MulI(z, 1)
+		if got, want := FresnelC(iz), MulI(cz, 1); !absClose(got, want, cz, 10) {
+			t.Errorf("C(i·%s) = %v, want %v", s, got, want)
+		}
+		if got, want := FresnelS(iz), MulI(sz, -1); !absClose(got, want, sz, 10) {
+			t.Errorf("S(i·%s) = %v, want %v", s, got, want)
+		}
+	}
+	// C(x) = 1/2 + sin(πx²/2)/(πx) + O(x⁻³), S(x) = 1/2 - cos(πx²/2)/(πx) + O(x⁻³);
+	// at x = 1000.5, πx²/2 = π/8 mod 2π.
+	sx, cx := Fresnel(MustParse("1000.5", 128))
+	if d := math.Abs(cx.Re().Float64() - 0.5 - math.Sin(math.Pi/8)/(math.Pi*1000.5)); d > 1e-9 {
+		t.Errorf("C(1000.5) = %v", cx)
+	}
+	if d := math.Abs(sx.Re().Float64() - 0.5 + math.Cos(math.Pi/8)/(math.Pi*1000.5)); d > 1e-9 {
+		t.Errorf("S(1000.5) = %v", sx)
+	}
+}
+
+func ExampleErf() {
+	z := MustParse("1+i", 64)
+	fmt.Println(Erf(z).StringFixed(15))
+	fmt.Println(Faddeeva(z).StringFixed(15))
+	// Output:
+	// 1.316151281697948+0.190453469237835i
+	// 0.304744205256913+0.208218938202832i
+}
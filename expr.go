@@ -0,0 +1,589 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ParseError reports a malformed expression or literal and where in the input it is.
+type ParseError struct {
+	Input  string // the text being parsed
+	Offset int    // byte offset of the offending token in Input
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("apcomplex: %s at offset %d in %q", e.Msg, e.Offset, e.Input)
+}
+
+// Eval parses and evaluates an arithmetic expression at the precision, rounding mode
+// and exponent range of ctx (nil uses the defaults), for example
+//
+//	exp(i*pi/3) + 2^(1/3)
+//	sqrt(-2)*(1e-100+3i)
+//
+// Expressions are made of decimal numbers (a trailing i makes a number imaginary), the
+// constants pi, e and i, the operators + - * / and ^ (or **, right associative and
+// binding tighter than unary minus), parentheses, and calls of the functions listed by
+// Functions. Syntax errors are reported as a *ParseError; trapped exceptions as a
+// *ContextError, together with the result.
+//
+// The functions are those of the package with one or two complex arguments; the order
+// of polygamma(n, z) and the optional branch of lambertw(k, z) are given as integers.
+// Left out are the variants that only change how an argument is passed (MulI, Mul2Si,
+// PowInt64 and the like, all spelled with the operators), Fma, Sum and Dot, the
+// functions of a Real such as RiemannSiegelZ, the Airy zeros, which take only an index,
+// and the bessel package, which imports this one.
+func Eval(expr string, ctx *Context) (*Complex, error) {
+	n, err := parseExpr(expr, nil)
+	if err != nil {
+		return nil, err
+	}
+	z := ctx.New()
+	err = ctx.run("eval", z, func() {
+		v := n.eval(z.prec, z.mode, nil)
+		defer v.Close()
+		z.Set(v)
+		z.inex = v.inex // the rounding happened in the last operation, not in the copy
+	})
+	return z, err
+}
+
+// Functions returns the names of the functions Eval and Compile understand (see Eval
+// for those left out).
+func Functions() []string {
+	names := make([]string, 0, len(exprFuncs))
+	for name := range exprFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exprFunc is a function callable from expressions. Implementations store the result in
+// z, which may alias an argument. A function with both forms, such as lambertw with an
+// optional branch, is called with one or two arguments.
+type exprFunc struct {
+	unary  func(z, a *Complex)
+	binary func(z, a, b *Complex)
+}
+
+// form returns the form of f taking nargs arguments, or nil if f has none.
+func (f *exprFunc) form(nargs int) *exprFunc {
+	switch {
+	case nargs == 1 && f.unary != nil:
+		if f.binary == nil {
+			return f
+		}
+		return &exprFunc{unary: f.unary}
+	case nargs == 2 && f.binary != nil:
+		if f.unary == nil {
+			return f
+		}
+		return &exprFunc{binary: f.binary}
+	}
+	return nil
+}
+
+// arity describes the numbers of arguments f takes.
+func (f *exprFunc) arity() string {
+	switch {
+	case f.unary != nil && f.binary != nil:
+		return "1 or 2"
+	case f.binary != nil:
+		return "2"
+	}
+	return "1"
+}
+
+func unaryFunc(f func(z, a *Complex, mode ...RoundingMode) *Complex) *exprFunc {
+	return &exprFunc{unary: func(z, a *Complex) { f(z, a) }}
+}
+
+var exprFuncs = map[string]*exprFunc{
+	"sqrt":         unaryFunc((*Complex).Sqrt),
+	"exp":          unaryFunc((*Complex).Exp),
+	"log":          unaryFunc((*Complex).Log),
+	"ln":           unaryFunc((*Complex).Log),
+	"log10":        unaryFunc((*Complex).Log10),
+	"sqr":          unaryFunc((*Complex).Sqr),
+	"gamma":        unaryFunc((*Complex).Gamma),
+	"lgamma":       unaryFunc((*Complex).LogGamma),
+	"digamma":      unaryFunc((*Complex).Digamma),
+	"zeta":         unaryFunc((*Complex).Zeta),
+	"dirichleteta": unaryFunc((*Complex).DirichletEta),
+	"erf":          unaryFunc((*Complex).Erf),
+	"erfc":         unaryFunc((*Complex).Erfc),
+	"erfi":         unaryFunc((*Complex).Erfi),
+	"faddeeva":     unaryFunc((*Complex).Faddeeva),
+	"dawson":       unaryFunc((*Complex).Dawson),
+	"fresnelc":     unaryFunc((*Complex).FresnelC),
+	"fresnels":     unaryFunc((*Complex).FresnelS),
+	"ai":           unaryFunc((*Complex).Ai),
+	"bi":           unaryFunc((*Complex).Bi),
+	"aiprime":      unaryFunc((*Complex).AiPrime),
+	"biprime":      unaryFunc((*Complex).BiPrime),
+	"lambertw":     lambertwFunc,
+	"sin":          unaryFunc((*Complex).Sin),
+	"cos":          unaryFunc((*Complex).Cos),
+	"tan":          unaryFunc((*Complex).Tan),
+	"asin":         unaryFunc((*Complex).Asin),
+	"acos":         unaryFunc((*Complex).Acos),
+	"atan":         unaryFunc((*Complex).Atan),
+	"sinh":         unaryFunc((*Complex).Sinh),
+	"cosh":         unaryFunc((*Complex).Cosh),
+	"tanh":         unaryFunc((*Complex).Tanh),
+	"asinh":        unaryFunc((*Complex).Asinh),
+	"acosh":        unaryFunc((*Complex).Acosh),
+	"atanh":        unaryFunc((*Complex).Atanh),
+	"neg":          unaryFunc((*Complex).Neg),
+	"conj":         unaryFunc((*Complex).Conj),
+	"inv":          unaryFunc((*Complex).Inv),
+	"proj":         unaryFunc((*Complex).Proj),
+	"eta":          unaryFunc((*Complex).Eta),
+	"abs":          {unary: func(z, a *Complex) { z.setRealPart(a, 'a') }},
+	"arg":          {unary: func(z, a *Complex) { z.setRealPart(a, 'g') }},
+	"norm":         {unary: func(z, a *Complex) { z.setRealPart(a, 'n') }},
+	"re":           {unary: func(z, a *Complex) { z.inex = C.mpc_set_fr(&z.z[0], a.re(), z.mode.mpc()) }},
+	"im":           {unary: func(z, a *Complex) { z.inex = C.mpc_set_fr(&z.z[0], a.im(), z.mode.mpc()) }},
+	"pow":          {binary: func(z, a, b *Complex) { z.Pow(a, b) }},
+	"agm":          {binary: func(z, a, b *Complex) { z.Agm(a, b) }},
+	"hurwitz":      {binary: func(z, a, b *Complex) { z.HurwitzZeta(a, b) }},
+	"polygamma": {binary: func(z, a, b *Complex) {
+		if n, ok := exprInt(a); ok && n >= 0 {
+			z.Polygamma(uint(n), b)
+		} else {
+			z.SetFloat64(math.NaN(), math.NaN())
+		}
+	}},
+}
+
+// lambertwFunc is lambertw(z) on the principal branch and lambertw(k, z) on branch k.
+var lambertwFunc = &exprFunc{
+	unary: func(z, a *Complex) { z.LambertW(0, a) },
+	binary: func(z, k, a *Complex) {
+		if k, ok := exprInt(k); ok {
+			z.LambertW(k, a)
+		} else {
+			z.SetFloat64(math.NaN(), math.NaN())
+		}
+	},
+}
+
+// exprInt returns a as an int if it is a real integer that fits one. Functions with an
+// integer parameter, polygamma's order and lambertw's branch, return NaN for any other
+// value.
+func exprInt(a *Complex) (int, bool) {
+	if C.mpfr_zero_p(a.im()) == 0 || C.mpfr_integer_p(a.re()) == 0 || C.mpfr_fits_slong_p(a.re(), C.MPFR_RNDN) == 0 {
+		return 0, false
+	}
+	n := int64(C.mpfr_get_si(a.re(), C.MPFR_RNDN))
+	return int(n), int64(int(n)) == n
+}
+
+// setRealPart sets z = f(a) + 0i for the real-valued MPC function f selected by which
+// ('a' abs, 'g' arg, 'n' norm), going through a temporary so that z may alias a.
+func (z *Complex) setRealPart(a *Complex, which byte) {
+	var t C.mpfr_t
+	C.mpfr_init2(&t[0], C.mpfr_prec_t(z.prec))
+	defer C.mpfr_clear(&t[0])
+	r := z.mode.Re().mpfr()
+	var tr C.int
+	switch which {
+	case 'a':
+		tr = C.mpc_abs(&t[0], &a.z[0], r)
+	case 'g':
+		tr = C.mpc_arg(&t[0], &a.z[0], r)
+	default:
+		tr = C.mpc_norm(&t[0], &a.z[0], r)
+	}
+	C.mpc_set_fr(&z.z[0], &t[0], z.mode.mpc()) // exact: same precision
+	z.inex = inexOf(tr, 0)
+}
+
+// exprConsts are the named constants; they are evaluated at the requested precision.
+var exprConsts = map[string]func(z *Complex){
+	"pi": func(z *Complex) { z.SetConst(ConstPi) },
+	"e":  func(z *Complex) { z.SetConst(ConstE) },
+	"i":  func(z *Complex) { z.SetInt64(0, 1) },
+}
+
+// exprKind identifies an expression tree node.
+type exprKind uint8
+
+const (
+	exprNum    exprKind = iota // real literal (text)
+	exprImag                   // imaginary literal (text, without the i)
+	exprConst                  // named constant (text)
+	exprVar                    // variable (index)
+	exprNeg                    // -args[0]
+	exprBinary                 // args[0] op args[1]
+	exprCall                   // fn(args...)
+)
+
+type exprNode struct {
+	kind  exprKind
+	pos   int    // offset of the node's token in the input
+	text  string // literal digits or constant name
+	op    byte   // '+', '-', '*', '/' or '^' for exprBinary
+	index int    // variable index for exprVar
+	fn    *exprFunc
+	args  []*exprNode
+}
+
+// eval evaluates n into a new value of precision prec and rounding mode mode, with
+// vars holding the values of the variables. The values of the arguments are closed
+// once n is computed.
+func (n *exprNode) eval(prec uint, mode RoundingMode, vars []*Complex) *Complex {
+	args := make([]*Complex, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(prec, mode, vars)
+		defer args[i].Close()
+	}
+	z := New(prec).SetMode(mode)
+	switch n.kind {
+	case exprNum:
+		z.SetBase(n.text, "0", 10)
+	case exprImag:
+		z.SetBase("0", n.text, 10)
+	case exprConst:
+		exprConsts[n.text](z)
+	case exprVar:
+		z.Set(vars[n.index])
+	case exprNeg:
+		z.Neg(args[0])
+	case exprBinary:
+		z.binaryOp(n.op, args[0], args[1])
+	case exprCall:
+		if n.fn.binary != nil {
+			n.fn.binary(z, args[0], args[1])
+		} else {
+			n.fn.unary(z, args[0])
+		}
+	}
+	return z
+}
+
+func (z *Complex) binaryOp(op byte, a, b *Complex) {
+	switch op {
+	case '+':
+		z.Add(a, b)
+	case '-':
+		z.Sub(a, b)
+	case '*':
+		z.Mul(a, b)
+	case '/':
+		z.Div(a, b)
+	case '^':
+		z.Pow(a, b)
+	}
+}
+
+// Lexer
+
+type tokKind uint8
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokImag
+	tokIdent
+	tokOp // one of + - * / ^ ( ) , with "**" reported as '^'
+)
+
+type token struct {
+	kind tokKind
+	pos  int
+	text string
+	op   byte
+}
+
+type exprLexer struct {
+	in  string
+	pos int
+}
+
+func isIdentByte(b byte, first bool) bool {
+	return b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || !first && '0' <= b && b <= '9'
+}
+
+func isDigit(b byte) bool { return '0' <= b && b <= '9' }
+
+func (l *exprLexer) errorf(pos int, format string, args ...any) *ParseError {
+	return &ParseError{Input: l.in, Offset: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *exprLexer) next() (token, error) {
+	for l.pos < len(l.in) && strings.IndexByte(" \t\r\n", l.in[l.pos]) >= 0 {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos == len(l.in) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	c := l.in[l.pos]
+	switch {
+	case isDigit(c) || c == '.':
+		return l.number()
+	case isIdentByte(c, true):
+		for l.pos < len(l.in) && isIdentByte(l.in[l.pos], false) {
+			l.pos++
+		}
+		return token{kind: tokIdent, pos: start, text: l.in[start:l.pos]}, nil
+	case strings.IndexByte("+-*/^(),", c) >= 0:
+		l.pos++
+		if c == '*' && l.pos < len(l.in) && l.in[l.pos] == '*' {
+			l.pos++
+			c = '^'
+		}
+		return token{kind: tokOp, pos: start, op: c}, nil
+	}
+	return token{}, l.errorf(start, "unexpected character %q", c)
+}
+
+// number scans digits[.digits][(e|E)[+|-]digits] with an optional imaginary suffix i.
+func (l *exprLexer) number() (token, error) {
+	start := l.pos
+	digits := 0
+	for l.pos < len(l.in) && isDigit(l.in[l.pos]) {
+		l.pos++
+		digits++
+	}
+	if l.pos < len(l.in) && l.in[l.pos] == '.' {
+		l.pos++
+		for l.pos < len(l.in) && isDigit(l.in[l.pos]) {
+			l.pos++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return token{}, l.errorf(start, "malformed number")
+	}
+	if l.pos < len(l.in) && (l.in[l.pos] == 'e' || l.in[l.pos] == 'E') {
+		p := l.pos + 1
+		if p < len(l.in) && (l.in[p] == '+' || l.in[p] == '-') {
+			p++
+		}
+		if p < len(l.in) && isDigit(l.in[p]) {
+			for p < len(l.in) && isDigit(l.in[p]) {
+				p++
+			}
+			l.pos = p
+		}
+	}
+	tok := token{kind: tokNum, pos: start, text: l.in[start:l.pos]}
+	if l.pos < len(l.in) && (l.in[l.pos] == 'i' || l.in[l.pos] == 'I') &&
+		(l.pos+1 == len(l.in) || !isIdentByte(l.in[l.pos+1], false)) {
+		l.pos++
+		tok.kind = tokImag
+	}
+	if l.pos < len(l.in) && (isIdentByte(l.in[l.pos], false) || l.in[l.pos] == '.') {
+		return token{}, l.errorf(l.pos, "malformed number")
+	}
+	return tok, nil
+}
+
+// Parser
+
+type exprParser struct {
+	lex  exprLexer
+	tok  token
+	vars []string
+}
+
+// parseExpr parses a whole expression; identifiers in vars become variables.
+func parseExpr(in string, vars []string) (*exprNode, error) {
+	p := &exprParser{lex: exprLexer{in: in}, vars: vars}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	n, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected()
+	}
+	return n, nil
+}
+
+func (p *exprParser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *exprParser) isOp(op byte) bool { return p.tok.kind == tokOp && p.tok.op == op }
+
+func (p *exprParser) unexpected() *ParseError {
+	switch p.tok.kind {
+	case tokEOF:
+		return p.lex.errorf(p.tok.pos, "unexpected end of expression")
+	case tokOp:
+		return p.lex.errorf(p.tok.pos, "unexpected %q", p.tok.op)
+	}
+	return p.lex.errorf(p.tok.pos, "unexpected %q", p.tok.text)
+}
+
+// sum := product {('+'|'-') product}
+func (p *exprParser) sum() (*exprNode, error) {
+	n, err := p.product()
+	for err == nil && (p.isOp('+') || p.isOp('-')) {
+		op := p.tok
+		if err = p.advance(); err != nil {
+			break
+		}
+		var r *exprNode
+		if r, err = p.product(); err == nil {
+			n = &exprNode{kind: exprBinary, pos: op.pos, op: op.op, args: []*exprNode{n, r}}
+		}
+	}
+	return n, err
+}
+
+// product := unary {('*'|'/') unary}
+func (p *exprParser) product() (*exprNode, error) {
+	n, err := p.unary()
+	for err == nil && (p.isOp('*') || p.isOp('/')) {
+		op := p.tok
+		if err = p.advance(); err != nil {
+			break
+		}
+		var r *exprNode
+		if r, err = p.unary(); err == nil {
+			n = &exprNode{kind: exprBinary, pos: op.pos, op: op.op, args: []*exprNode{n, r}}
+		}
+	}
+	return n, err
+}
+
+// unary := ('-'|'+') unary | power
+func (p *exprParser) unary() (*exprNode, error) {
+	if p.isOp('-') || p.isOp('+') {
+		op := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.unary()
+		if err != nil || op.op == '+' {
+			return n, err
+		}
+		// Negated literals are folded so that -4 is -4+0i, not -(4+0i) = -4-0i, which
+		// would land on the other side of branch cuts such as sqrt's.
+		if n.kind == exprNum || n.kind == exprImag {
+			if strings.HasPrefix(n.text, "-") {
+				n.text = n.text[1:]
+			} else {
+				n.text = "-" + n.text
+			}
+			return n, nil
+		}
+		return &exprNode{kind: exprNeg, pos: op.pos, args: []*exprNode{n}}, nil
+	}
+	return p.power()
+}
+
+// power := primary ['^' unary]
+func (p *exprParser) power() (*exprNode, error) {
+	n, err := p.primary()
+	if err != nil || !p.isOp('^') {
+		return n, err
+	}
+	op := p.tok
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	r, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{kind: exprBinary, pos: op.pos, op: '^', args: []*exprNode{n, r}}, nil
+}
+
+// primary := number | imag | name | name '(' sum {',' sum} ')' | '(' sum ')'
+func (p *exprParser) primary() (*exprNode, error) {
+	t := p.tok
+	switch {
+	case t.kind == tokNum || t.kind == tokImag:
+		kind := exprNum
+		if t.kind == tokImag {
+			kind = exprImag
+		}
+		return &exprNode{kind: kind, pos: t.pos, text: t.text}, p.advance()
+	case t.kind == tokIdent:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.isOp('(') {
+			return p.call(t)
+		}
+		return p.name(t)
+	case p.isOp('('):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(')') {
+			return nil, p.lex.errorf(p.tok.pos, "missing ')' for '(' at offset %d", t.pos)
+		}
+		return n, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *exprParser) name(t token) (*exprNode, error) {
+	for i, v := range p.vars {
+		if v == t.text {
+			return &exprNode{kind: exprVar, pos: t.pos, index: i}, nil
+		}
+	}
+	if _, ok := exprConsts[t.text]; ok {
+		return &exprNode{kind: exprConst, pos: t.pos, text: t.text}, nil
+	}
+	if _, ok := exprFuncs[t.text]; ok {
+		return nil, p.lex.errorf(t.pos, "function %s needs arguments", t.text)
+	}
+	return nil, p.lex.errorf(t.pos, "unknown name %q", t.text)
+}
+
+func (p *exprParser) call(t token) (*exprNode, error) {
+	fn, ok := exprFuncs[t.text]
+	if !ok {
+		return nil, p.lex.errorf(t.pos, "unknown function %q", t.text)
+	}
+	n := &exprNode{kind: exprCall, pos: t.pos, text: t.text, fn: fn}
+	for {
+		if err := p.advance(); err != nil { // skip '(' or ','
+			return nil, err
+		}
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		n.args = append(n.args, arg)
+		if !p.isOp(',') {
+			break
+		}
+	}
+	if !p.isOp(')') {
+		return nil, p.lex.errorf(p.tok.pos, "missing ')' in call of %s", t.text)
+	}
+	if n.fn = fn.form(len(n.args)); n.fn == nil {
+		return nil, p.lex.errorf(t.pos, "%s takes %s argument(s), got %d", t.text, fn.arity(), len(n.args))
+	}
+	return n, p.advance()
+}
@@ -0,0 +1,136 @@
+package apcomplex
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEvalValues(t *testing.T) {
+	ctx := &Context{Prec: 200}
+	tests := []struct {
+		expr, want string
+	}{
+		{"1+2*3", "7"},
+		{"(1+2)*3", "9"},
+		{"2^3^2", "512"},
+		{"-2^2", "-4"},
+		{"2**-1", "0.5"},
+		{"- -3", "3"},
+		{"i*i", "-1"},
+		{"3i - 1.5e1", "-15+3i"},
+		{"sqrt(-4)", "2i"},
+		{"abs(3+4i)", "5"},
+		{"norm(3-4i)", "25"},
+		{"re(2-5i) + im(2-5i)", "-3"},
+		{"conj(1+2i)*inv(2)", "0.5-1i"},
+		{"pow(2, 10)", "1024"},
+		{"proj(1+2i) + eta(i) - gamma(0.25)/(2*pi^0.75)", "1+2i"},
+		{"log(e)", "1"},
+		{"cos(pi)", "-1"},
+		{"gamma(5) + digamma(1) + lgamma(1)", "23.422784335098467139393487909917597568957840664060076401194"},
+		{"polygamma(1, 1) - pi^2/6 + polygamma(0, 2) - digamma(2)", "0"},
+		{"erf(1+i) + erfc(1+i) + erfi(0)", "1"},
+		{"faddeeva(i) - exp(1)*erfc(1) + dawson(1) - sqrt(pi)/2*exp(-1)*erfi(1)", "0"},
+		{"fresnelc(1+i) + i*fresnels(1+i) - (1+i)/2*erf((1-i)*sqrt(pi)/2*(1+i))", "0"},
+		{"lambertw(1) * exp(lambertw(1))", "1"},
+		{"bi(0) / ai(0)", "1.7320508075688772935274463415058723669428052538103806280558"},
+		{"ai(1+i)*biprime(1+i) - aiprime(1+i)*bi(1+i)", "0.31830988618379067153776752674502872406891929148091289749533"},
+		{"zeta(2) - hurwitz(2, 1) + zeta(-1)", "-0.083333333333333333333333333333333333333333333333333333333333"},
+		{"dirichleteta(1) - log(2) + dirichleteta(2) - pi^2/12", "0"},
+		{"exp(i*pi/3)", "0.5+0.86602540378443864676372317075293618347140262690519031402790i"},
+		{"sqrt(-2)*(1e-100+3i)", "-4.2426406871192851464050661726290942357090156261308442195300+1.4142135623730950488016887242096980785696718753769480731767e-100i"},
+	}
+	tol := MustParseReal("1e-55", 64)
+	for _, tt := range tests {
+		got, err := Eval(tt.expr, ctx)
+		if err != nil {
+			t.Fatalf("Eval(%q): %v", tt.expr, err)
+		}
+		if got.Prec() != 200 {
+			t.Errorf("Eval(%q) at %d bits", tt.expr, got.Prec())
+		}
+		if want := MustParse(tt.want, 200); !got.WithinRel(want, tol) && !got.WithinAbs(want, tol) {
+			t.Errorf("Eval(%q) = %v, want %s", tt.expr, got, tt.want)
+		}
+	}
+	// The optional first argument of lambertw selects the branch.
+	for _, k := range []int{-1, 0, 3} {
+		got, err := Eval(fmt.Sprintf("lambertw(%d, -0.2)", k), ctx)
+		if want := LambertW(k, MustParse("-0.2", 200)); err != nil || !got.Equal(want) {
+			t.Errorf("lambertw(%d, -0.2) = %v, want %v", k, got, want)
+		}
+		got, err = MustCompile("lambertw(k, z)", "k", "z").Eval(ctx, MustParse(fmt.Sprint(k), 200), MustParse("-0.2", 200))
+		if want := LambertW(k, MustParse("-0.2", 200)); err != nil || !got.Equal(want) {
+			t.Errorf("compiled lambertw(%d, -0.2) = %v, want %v", k, got, want)
+		}
+	}
+	// 2^(1/3) cubed is 2.
+	z, _ := Eval("(2^(1/3))^3", ctx)
+	if !z.WithinAbs(MustParse("2", 200), tol) {
+		t.Errorf("cube of 2^(1/3) = %v", z)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		offset int
+	}{
+		{"", 0},
+		{"1+", 2},
+		{"2*(3+4", 6},
+		{"sin(1", 5},
+		{"foo(1)", 0},
+		{"1 + bar", 4},
+		{"pow(1)", 0},
+		{"lambertw(1, 2, 3)", 0},
+		{"sqrt", 0},
+		{"1 $ 2", 2},
+		{"1.2.3", 3},
+		{"2e", 1},
+		{"3 4", 2},
+		{"(1))", 3},
+	}
+	for _, tt := range tests {
+		_, err := Eval(tt.expr, nil)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Eval(%q): got %v, want a *ParseError", tt.expr, err)
+			continue
+		}
+		if pe.Offset != tt.offset || pe.Input != tt.expr {
+			t.Errorf("Eval(%q): %v, want offset %d", tt.expr, pe, tt.offset)
+		}
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	_, err := Eval("1/0", &Context{Prec: 64, Traps: FlagDivByZero | FlagNaN | FlagOverflow})
+	var ce *ContextError
+	if !errors.As(err, &ce) {
+		t.Fatalf("1/0 with traps: %v", err)
+	}
+	z, err := Eval("1/3", &Context{Prec: 24, Mode: RNDUU})
+	if err != nil || z.Prec() != 24 || z.Mode() != RNDUU {
+		t.Fatalf("1/3: %v %v", z, err)
+	}
+	if re, _ := z.Acc(); re != Above {
+		t.Errorf("1/3 rounded up reports %v", re)
+	}
+	// Integer parameters that are not integers give NaN.
+	for _, expr := range []string{"polygamma(1.5, 2)", "polygamma(-1, 2)", "polygamma(1+i, 2)", "lambertw(0.5, 1)"} {
+		if z, err := Eval(expr, nil); err != nil || !z.IsNaN() {
+			t.Errorf("%s = %v, %v; want NaN", expr, z, err)
+		}
+	}
+	for _, name := range []string{"sqrt", "abs", "pow", "atanh"} {
+		found := false
+		for _, f := range Functions() {
+			found = found || f == name
+		}
+		if !found {
+			t.Errorf("Functions() misses %s", name)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package apcomplex
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_mpfr_fmt formats x with the single conversion %.*R<conv> (or %R<conv> when
+// prec < 0) into a malloc'ed string.
+static char* apc_mpfr_fmt(mpfr_srcptr x, char conv, int prec) {
+    char spec[8] = "%.*R?";
+    char spec0[8] = "%R?";
+    spec[4] = conv;
+    spec0[2] = conv;
+    int n = prec < 0 ? mpfr_snprintf(NULL, 0, spec0, x) : mpfr_snprintf(NULL, 0, spec, prec, x);
+    if (n < 0) return NULL;
+    char *buf = (char*)malloc((size_t)n + 1);
+    if (!buf) return NULL;
+    int m = prec < 0 ? mpfr_snprintf(buf, (size_t)n + 1, spec0, x) : mpfr_snprintf(buf, (size_t)n + 1, spec, prec, x);
+    if (m < 0) {
+        free(buf);
+        return NULL;
+    }
+    return buf;
+}
+
+// apc_mpfr_digits_rnd returns the n most significant digits of |x| (x regular) in the
+// given base, rounded with rnd (n == 0: enough to read x back), and stores the exponent
+// such that |x| ~ 0.DIGITS * base^exp.
+static char* apc_mpfr_digits_rnd(mpfr_srcptr x, int base, size_t n, long *exp, mpfr_rnd_t rnd) {
+    mpfr_exp_t e;
+    char *s = mpfr_get_str(NULL, &e, base, n, x, rnd);
+    if (!s) return NULL;
+    char *p = s[0] == '-' ? s + 1 : s;
+    size_t len = strlen(p);
+    char *out = (char*)malloc(len + 1);
+    if (out) memcpy(out, p, len + 1);
+    mpfr_free_str(s);
+    *exp = (long)e;
+    return out;
+}
+
+// apc_mpfr_digits is apc_mpfr_digits_rnd rounding to nearest.
+static char* apc_mpfr_digits(mpfr_srcptr x, int base, size_t n, long *exp) {
+    return apc_mpfr_digits_rnd(x, base, n, exp, MPFR_RNDN);
+}
+
+// apc_mpfr_roundtrips reports whether 0.DIGITS * 10^exp (digits of |x|) reads back,
+// rounded to nearest at x's precision, as exactly |x|.
+static int apc_mpfr_roundtrips(mpfr_srcptr x, const char *digits, long exp) {
+    size_t n = strlen(digits);
+    char *s = (char*)malloc(n + 32);
+    if (!s) return 0;
+    snprintf(s, n + 32, "0.%s@%ld", digits, exp);
+    mpfr_t y;
+    mpfr_init2(y, mpfr_get_prec(x));
+    mpfr_set_str(y, s, 10, MPFR_RNDN);
+    free(s);
+    int ok = mpfr_cmpabs(x, y) == 0;
+    mpfr_clear(y);
+    return ok;
+}
+
+// apc_mpfr_fits returns n decimal digits of |x| (x regular) that read back as x: the
+// nearest n-digit string if it does, else the one on the other side of |x|. When
+// neither does it returns NULL and sets *none; NULL alone means out of memory.
+static char* apc_mpfr_fits(mpfr_srcptr x, size_t n, long *exp, int *none) {
+    static const mpfr_rnd_t rnds[3] = {MPFR_RNDN, MPFR_RNDZ, MPFR_RNDA};
+    *none = 0;
+    for (int i = 0; i < 3; i++) {
+        char *d = apc_mpfr_digits_rnd(x, 10, n, exp, rnds[i]);
+        if (!d) return NULL;
+        if (apc_mpfr_roundtrips(x, d, *exp)) return d;
+        free(d);
+    }
+    *none = 1;
+    return NULL;
+}
+
+// apc_mpfr_shortest returns the shortest decimal digits of |x| (x regular) that read
+// back as x, with the exponent as for apc_mpfr_digits. Whether the nearest n-digit
+// string reads back is not monotone in n: at a power of two the rounding interval
+// below x is half the width of the one above, so the nearest n-digit string may fall
+// in the upper half while the nearest (n+1)-digit one misses the lower half. Whether
+// some n-digit string reads back is monotone, since the n-digit strings are among the
+// (n+1)-digit ones, and the candidates are the strings rounded toward and away from
+// zero. A binary search on that finds the shortest length below the
+// mpfr_get_str_ndigits bound, which always reads back.
+static char* apc_mpfr_shortest(mpfr_srcptr x, long *exp) {
+    size_t lo = 1, hi = mpfr_get_str_ndigits(10, mpfr_get_prec(x));
+    int none;
+    while (lo < hi) {
+        size_t mid = lo + (hi - lo) / 2;
+        long e;
+        char *d = apc_mpfr_fits(x, mid, &e, &none);
+        if (!d && !none) return NULL;
+        free(d);
+        if (d) hi = mid; else lo = mid + 1;
+    }
+    return apc_mpfr_fits(x, hi, exp, &none);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// String formats c as Text('g', -1): the shortest "a+bi" that Parse reads back, at
+// c's precision, as exactly c. It is canonical: equal values give equal strings.
+func (c *Complex) String() string { return c.Text('g', -1) }
+
+// Text formats c as "a+bi", where both parts are formatted by (*Real).Text with the
+// given format and precision. The imaginary part always carries a sign.
+func (c *Complex) Text(format byte, prec int) string {
+	if !c.init {
+		return "(invalid)"
+	}
+	re := mpfrText(c.re(), format, prec)
+	im := mpfrText(c.im(), format, prec)
+	if !strings.HasPrefix(im, "-") {
+		im = "+" + im
+	}
+	return re + im + "i"
+}
+
+// Format implements fmt.Formatter. It accepts the verbs of big.Float:
+//
+//	'e', 'E', 'f', 'F', 'g', 'G'  decimal, as for float64
+//	'x', 'X'                      normalized C99 hex float, e.g. 0x1.8p+3
+//	'b'                           decimal mantissa and binary exponent, e.g. 3p+1
+//	'v', 's'                      like 'g'
+//
+// A missing precision prints the shortest digits that read the value back exactly ('b'
+// ignores the precision). The '+' and ' ' flags control the sign of the real part;
+// width, '-' and '0' pad the whole "a+bi" string.
+func (c *Complex) Format(s fmt.State, verb rune) {
+	formatState(s, verb, "*apcomplex.Complex", c.Text)
+}
+
+// Scan implements fmt.Scanner: it reads one space-free complex literal (see Parse), so
+// the MPC form must be written as "(a,b)". A zero Complex is initialized with
+// DefaultPrec; it is not freed automatically, so Close it when done.
+func (c *Complex) Scan(s fmt.ScanState, ch rune) error {
+	tok, err := scanToken(s)
+	if err != nil {
+		return err
+	}
+	c.lazyInit(0)
+	return c.SetString(tok)
+}
+
+// String formats r as Text('g', -1), the shortest decimal that reads back as r.
+func (r *Real) String() string { return r.Text('g', -1) }
+
+// Text formats r according to format and prec as (*big.Float).Text does: 'e', 'E',
+// 'f', 'g' and 'G' are decimal, 'x' and 'X' hex float, and 'b' prints the integer
+// mantissa and binary exponent. For the decimal formats a negative prec selects the
+// shortest digits that read back as r at its precision; for 'x' it prints all bits.
+func (r *Real) Text(format byte, prec int) string {
+	if !r.init {
+		return "(invalid)"
+	}
+	return mpfrText(r.ptr(), format, prec)
+}
+
+// Format implements fmt.Formatter with the same verbs and flags as (*Complex).Format.
+func (r *Real) Format(s fmt.State, verb rune) {
+	formatState(s, verb, "*apcomplex.Real", r.Text)
+}
+
+// Scan implements fmt.Scanner for a single real literal.
+func (r *Real) Scan(s fmt.ScanState, ch rune) error {
+	tok, err := scanToken(s)
+	if err != nil {
+		return err
+	}
+	r.lazyInit()
+	return r.SetString(tok)
+}
+
+// String formats s like (*Complex).String.
+func (s *Safe) String() string {
+	s.mu.RLock()
+	out := s.c.String()
+	s.mu.RUnlock()
+	return out
+}
+
+// Format implements fmt.Formatter like (*Complex).Format.
+func (s *Safe) Format(st fmt.State, verb rune) {
+	s.mu.RLock()
+	s.c.Format(st, verb)
+	s.mu.RUnlock()
+}
+
+// StringBase formats c as "a+bi" with both parts in the given base (2..62) using
+// digits significant digits, or as many as needed to read c back if digits <= 0.
+// Parts are written as "d.ddd@e", meaning d.ddd * base^e, which ParseBase and SetBase
+// (and MPFR's mpfr_strtofr) accept. Digits are rounded to nearest.
+func (c *Complex) StringBase(base, digits int) string {
+	if !c.init {
+		return "(invalid)"
+	}
+	re := mpfrStringBase(c.re(), base, digits)
+	im := mpfrStringBase(c.im(), base, digits)
+	if !strings.HasPrefix(im, "-") {
+		im = "+" + im
+	}
+	return re + im + "i"
+}
+
+// StringBase formats r in the given base; see (*Complex).StringBase.
+func (r *Real) StringBase(base, digits int) string {
+	if !r.init {
+		return "(invalid)"
+	}
+	return mpfrStringBase(r.ptr(), base, digits)
+}
+
+func mpfrStringBase(x *C.__mpfr_struct, base, digits int) string {
+	if base < 2 || base > 62 {
+		panic("apcomplex: StringBase base out of range 2..62")
+	}
+	switch {
+	case C.mpfr_nan_p(x) != 0:
+		return "@NaN@"
+	case C.mpfr_inf_p(x) != 0:
+		if C.mpfr_signbit(x) != 0 {
+			return "-@Inf@"
+		}
+		return "@Inf@"
+	}
+	sign := ""
+	if C.mpfr_signbit(x) != 0 {
+		sign = "-"
+	}
+	if C.mpfr_zero_p(x) != 0 {
+		return sign + "0"
+	}
+	if digits < 0 {
+		digits = 0
+	}
+	var exp C.long
+	p := C.apc_mpfr_digits(x, C.int(base), C.size_t(digits), &exp)
+	if p == nil {
+		return "<oom>"
+	}
+	d := goStringFree(p)
+	if digits == 0 {
+		d = strings.TrimRight(d, "0")
+	}
+	out := sign + d[:1]
+	if len(d) > 1 {
+		out += "." + d[1:]
+	}
+	if e := int(exp) - 1; e != 0 {
+		out += "@" + strconv.Itoa(e)
+	}
+	return out
+}
+
+// mpfrText formats a single MPFR value; see (*Real).Text.
+func mpfrText(x *C.__mpfr_struct, format byte, prec int) string {
+	upper := format == 'E' || format == 'G' || format == 'X'
+	switch {
+	case C.mpfr_nan_p(x) != 0:
+		return caseOf("nan", upper)
+	case C.mpfr_inf_p(x) != 0:
+		if C.mpfr_signbit(x) != 0 {
+			return caseOf("-inf", upper)
+		}
+		return caseOf("inf", upper)
+	}
+	switch format {
+	case 'b':
+		return mpfrTextBinary(x)
+	case 'x', 'X':
+		return caseOf(mpfrTextHex(x, prec), upper)
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		if prec < 0 {
+			return mpfrTextShortest(x, format)
+		}
+		conv := C.char(format)
+		if format == 'F' {
+			conv = 'f'
+		}
+		return goStringFree(C.apc_mpfr_fmt(x, conv, C.int(prec)))
+	}
+	return "%" + string(format)
+}
+
+// mpfrTextBinary formats a finite x as mantissa "p" exponent, the mantissa being a
+// decimal integer of prec(x) bits.
+func mpfrTextBinary(x *C.__mpfr_struct) string {
+	if C.mpfr_zero_p(x) != 0 {
+		if C.mpfr_signbit(x) != 0 {
+			return "-0"
+		}
+		return "0"
+	}
+	mant, exp := mpfrMantExp(x)
+	if exp >= 0 {
+		return mant.String() + "p+" + strconv.FormatInt(exp, 10)
+	}
+	return mant.String() + "p" + strconv.FormatInt(exp, 10)
+}
+
+// mpfrTextHex formats a finite x as a normalized C99 hex float, "0x1.8p+3", with prec
+// hex digits after the point (rounded to nearest), or all significant ones if prec < 0.
+func mpfrTextHex(x *C.__mpfr_struct, prec int) string {
+	sign := ""
+	if C.mpfr_signbit(x) != 0 {
+		sign = "-"
+	}
+	if C.mpfr_zero_p(x) != 0 {
+		if prec > 0 {
+			return sign + "0x0." + strings.Repeat("0", prec) + "p+0"
+		}
+		return sign + "0x0p+0"
+	}
+	if prec >= 0 {
+		var t C.mpfr_t
+		C.mpfr_init2(&t[0], C.mpfr_prec_t(1+4*prec))
+		defer C.mpfr_clear(&t[0])
+		C.mpfr_set(&t[0], x, C.MPFR_RNDN)
+		x = &t[0]
+	}
+	mant, exp := mpfrMantExp(x)
+	mant.Abs(mant)
+	bits := mant.BitLen()
+	exp += int64(bits - 1)
+	frac := mant.SetBit(mant, bits-1, 0)
+	nd := (bits - 1 + 3) / 4
+	if prec >= 0 {
+		nd = prec
+	}
+	frac.Lsh(frac, uint(4*nd-(bits-1)))
+	hex := ""
+	if nd > 0 {
+		hex = frac.Text(16)
+		hex = strings.Repeat("0", nd-len(hex)) + hex
+	}
+	if prec < 0 {
+		hex = strings.TrimRight(hex, "0")
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	b.WriteString("0x1")
+	if hex != "" {
+		b.WriteByte('.')
+		b.WriteString(hex)
+	}
+	b.WriteByte('p')
+	if exp >= 0 {
+		b.WriteByte('+')
+	}
+	b.WriteString(strconv.FormatInt(exp, 10))
+	return b.String()
+}
+
+// mpfrTextShortest formats a finite x with the fewest decimal digits that read back
+// (Parse at the same precision, round to nearest) as exactly x, in 'e', 'f' or 'g' layout.
+func mpfrTextShortest(x *C.__mpfr_struct, format byte) string {
+	sign := ""
+	if C.mpfr_signbit(x) != 0 {
+		sign = "-"
+	}
+	if C.mpfr_zero_p(x) != 0 {
+		return sign + layoutDigits("0", 1, format)
+	}
+	var exp C.long
+	p := C.apc_mpfr_shortest(x, &exp)
+	if p == nil {
+		return "<oom>"
+	}
+	digits := strings.TrimRight(goStringFree(p), "0")
+	return sign + layoutDigits(digits, int(exp), format)
+}
+
+// layoutDigits lays out the decimal digits d of 0.d * 10^exp like strconv does for
+// the shortest representation of a float64.
+func layoutDigits(d string, exp int, format byte) string {
+	switch format {
+	case 'g', 'G':
+		if x := exp - 1; x < -4 || x >= 6 {
+			format -= 'g' - 'e'
+		} else {
+			format = 'f'
+		}
+	case 'F':
+		format = 'f'
+	}
+	if format == 'f' {
+		switch {
+		case exp <= 0:
+			return "0." + strings.Repeat("0", -exp) + d
+		case exp >= len(d):
+			return d + strings.Repeat("0", exp-len(d))
+		}
+		return d[:exp] + "." + d[exp:]
+	}
+	var b strings.Builder
+	b.WriteByte(d[0])
+	if len(d) > 1 {
+		b.WriteByte('.')
+		b.WriteString(d[1:])
+	}
+	b.WriteByte(format) // 'e' or 'E'
+	x := exp - 1
+	if x < 0 {
+		b.WriteByte('-')
+		x = -x
+	} else {
+		b.WriteByte('+')
+	}
+	if x < 10 {
+		b.WriteByte('0')
+	}
+	b.WriteString(strconv.Itoa(x))
+	return b.String()
+}
+
+func caseOf(s string, upper bool) string {
+	if upper {
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
+// formatState implements Format for the types of this package on top of their Text.
+func formatState(s fmt.State, verb rune, typ string, text func(byte, int) string) {
+	prec, hasPrec := s.Precision()
+	if !hasPrec {
+		prec = -1
+	}
+	var format byte
+	switch verb {
+	case 'e', 'E', 'f', 'g', 'G', 'x', 'X', 'b':
+		format = byte(verb)
+	case 'F':
+		format = 'f'
+	case 'v', 's':
+		format = 'g'
+	default:
+		fmt.Fprintf(s, "%%!%c(%s=%s)", verb, typ, text('g', -1))
+		return
+	}
+	out := text(format, prec)
+
+	sign := ""
+	if strings.HasPrefix(out, "-") {
+		sign, out = "-", out[1:]
+	} else if s.Flag('+') {
+		sign = "+"
+	} else if s.Flag(' ') {
+		sign = " "
+	}
+	width, _ := s.Width()
+	pad := width - len(sign) - len(out)
+	switch {
+	case pad <= 0:
+		fmt.Fprint(s, sign, out)
+	case s.Flag('-'):
+		fmt.Fprint(s, sign, out, strings.Repeat(" ", pad))
+	case s.Flag('0'):
+		fmt.Fprint(s, sign, strings.Repeat("0", pad), out)
+	default:
+		fmt.Fprint(s, strings.Repeat(" ", pad), sign, out)
+	}
+}
+
+// scanToken reads the next space-delimited token from s.
+func scanToken(s fmt.ScanState) (string, error) {
+	s.SkipSpace()
+	tok, err := s.Token(true, func(r rune) bool { return !unicode.IsSpace(r) })
+	if err != nil {
+		return "", err
+	}
+	if len(tok) == 0 {
+		return "", fmt.Errorf("apcomplex: expected a number")
+	}
+	return string(tok), nil
+}
@@ -0,0 +1,234 @@
+package apcomplex
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFormatVerbs(t *testing.T) {
+	z := MustParse("1.5-2560i", 64)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "1.5-2560i"},
+		{"%.3e", "1.500e+00-2.560e+03i"},
+		{"%.4G", "1.5-2560i"},
+		{"%.1f", "1.5-2560.0i"},
+		{"%x", "0x1.8p+0-0x1.4p+11i"},
+		{"%.2X", "0X1.80P+0-0X1.40P+11i"},
+		{"%+.1e", "+1.5e+00-2.6e+03i"},
+		{"% .1e", " 1.5e+00-2.6e+03i"},
+		{"%20.1e|", "    1.5e+00-2.6e+03i|"},
+		{"%-20.1e|", "1.5e+00-2.6e+03i    |"},
+		{"%+020.1e|", "+0001.5e+00-2.6e+03i|"},
+		{"%d", "%!d(*apcomplex.Complex=1.5-2560i)"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, z); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := fmt.Sprint(MustParse("3-4i", 64)); got != "3-4i" {
+		t.Errorf("Sprint = %q", got)
+	}
+	if got := MustParse("0.1", 53).String(); got != "0.1+0i" {
+		t.Errorf("String = %q", got)
+	}
+	if got := fmt.Sprintf("%v", New(64)); got != "nan+nani" {
+		t.Errorf("NaN formats as %q", got)
+	}
+}
+
+func TestFormatBinaryMatchesBigFloat(t *testing.T) {
+	for _, s := range []string{"1.5", "-0.1", "12345678901234567890", "0"} {
+		r := MustParseReal(s, 64)
+		if got, want := fmt.Sprintf("%b", r), r.BigFloat().Text('b', 0); got != want {
+			t.Errorf("%%b of %s = %q, big.Float gives %q", s, got, want)
+		}
+	}
+}
+
+func TestFormatReadBack(t *testing.T) {
+	// Without a precision every verb prints enough digits to recover the value.
+	z := Div(MustParse("1+2i", 200), MustParse("3-7i", 200))
+	for _, verb := range []string{"%v", "%e", "%f", "%x"} {
+		s := fmt.Sprintf(verb, z)
+		base := 10
+		if verb == "%x" {
+			base = 16
+		}
+		back, err := ParseBase(s, base, 200)
+		if err != nil || !back.Equal(z) {
+			t.Errorf("%s: %q does not read back (%v)", verb, s, err)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	var a, b Complex
+	defer a.Close()
+	defer b.Close()
+	n, err := fmt.Sscan("  1.25-2e3i (3,4)", &a, &b)
+	if err != nil || n != 2 {
+		t.Fatalf("Sscan: n=%d err=%v", n, err)
+	}
+	if !a.Equal(MustParse("1.25-2000i", 64)) || !b.Equal(MustParse("3+4i", 64)) {
+		t.Fatalf("scanned %v and %v", &a, &b)
+	}
+	if a.Prec() != DefaultPrec {
+		t.Fatalf("zero value scanned at %d bits, want DefaultPrec", a.Prec())
+	}
+
+	r := NewReal(64)
+	if _, err := fmt.Sscanf("x=-2.5", "x=%v", r); err != nil || r.Float64() != -2.5 {
+		t.Fatalf("Sscanf real: %v, %v", r, err)
+	}
+	if _, err := fmt.Sscan("bogus", &a); err == nil {
+		t.Fatalf("expected an error for an invalid literal")
+	}
+}
+
+func TestStringShortestRoundTrip(t *testing.T) {
+	if got := MustParse("0.1-2.5e30i", 64).String(); got != "0.1-2.5e+30i" {
+		t.Errorf("String = %q, want 0.1-2.5e+30i", got)
+	}
+	if got := MustParse("1234567+0.0001i", 64).Text('f', -1); got != "1234567+0.0001i" {
+		t.Errorf("Text('f', -1) = %q", got)
+	}
+	for _, prec := range []uint{2, 24, 53, 64, 113, 200, 1000} {
+		one := New(prec).SetInt64(1, 0)
+		z := New(prec).SetInt64(1, -7)
+		for i := 0; i < 40; i++ {
+			z.Mul(z, MustParse("1.0123456789-0.314159i", prec))
+			z.Div(z, Add(one, MustParse("1e-3", prec)))
+			s := z.String()
+			back, err := Parse(s, prec)
+			if err != nil || back.Cmp(z) != 0 {
+				t.Fatalf("prec %d: %q does not read back (%v)", prec, s, err)
+			}
+			// At 53 bits the parts are float64s, for which strconv is the reference.
+			if prec == 53 {
+				c := z.Complex128()
+				want := strconv.FormatFloat(real(c), 'g', -1, 64)
+				if im := strconv.FormatFloat(imag(c), 'g', -1, 64); im[0] == '-' {
+					want += im + "i"
+				} else {
+					want += "+" + im + "i"
+				}
+				if s != want {
+					t.Fatalf("prec 53: %q, strconv shortest gives %q", s, want)
+				}
+			}
+		}
+	}
+	for _, s := range []string{"-0+0i", "0-0i", "nan+1i", "-inf+infi"} {
+		z := MustParse(s, 64)
+		if back := MustParse(z.String(), 64); back.Cmp(z) != 0 {
+			t.Errorf("%s: %q does not read back", s, z.String())
+		}
+	}
+}
+
+// shorterReadsBack reports whether some decimal with fewer significant digits than
+// x.String() reads back as x: the candidates are the nearest shorter string and its
+// two neighbours at that length.
+func shorterReadsBack(x *Real) bool {
+	s := strings.TrimLeft(x.String(), "-")
+	mant, _, _ := strings.Cut(s, "e")
+	n := len(strings.Trim(strings.ReplaceAll(mant, ".", ""), "0"))
+	if n <= 1 {
+		return false
+	}
+	mant, es, _ := strings.Cut(strings.TrimLeft(x.Text('e', n-2), "-"), "e")
+	m, _ := new(big.Int).SetString(strings.ReplaceAll(mant, ".", ""), 10)
+	exp, _ := strconv.Atoi(es)
+	for _, d := range []int64{-1, 0, 1} {
+		lit := fmt.Sprintf("%se%d", new(big.Int).Add(m, big.NewInt(d)), exp-(n-2))
+		if MustParseReal(lit, x.Prec()).CmpAbs(x) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStringShortestPowersOfTwo(t *testing.T) {
+	// Below a power of two the rounding interval is half as wide as above it, so the
+	// nearest string of some length can read back while the nearest one digit longer
+	// does not.
+	for k := -1021; k <= 1023; k++ {
+		x := NewReal(53).SetFloat64(math.Ldexp(1, k))
+		if got, want := x.String(), strconv.FormatFloat(math.Ldexp(1, k), 'g', -1, 64); got != want {
+			t.Errorf("2^%d at 53 bits: %q, strconv shortest gives %q", k, got, want)
+		}
+	}
+	for _, prec := range []uint{3, 17, 31, 99} {
+		for k := -300; k <= 300; k++ {
+			x := NewReal(prec).SetFloat64(math.Ldexp(1, k))
+			if back := MustParseReal(x.String(), prec); back.Cmp(x) != 0 || shorterReadsBack(x) {
+				t.Errorf("2^%d at %d bits: %q is not the shortest literal reading back", k, prec, x.String())
+			}
+		}
+	}
+}
+
+func TestHexFloat(t *testing.T) {
+	tests := []struct {
+		s    string
+		prec int
+		want string
+	}{
+		{"12", -1, "0x1.8p+3"},
+		{"-0.1", -1, "-0x1.999999999999999ap-4"},
+		{"-0.1", 3, "-0x1.99ap-4"},
+		{"1.96875", 1, "0x1.0p+1"},
+		{"1.96875", 0, "0x1p+1"},
+		{"0", 2, "0x0.00p+0"},
+		{"-0", -1, "-0x0p+0"},
+	}
+	for _, tt := range tests {
+		if got := MustParseReal(tt.s, 64).Text('x', tt.prec); got != tt.want {
+			t.Errorf("Text(%s, 'x', %d) = %q, want %q", tt.s, tt.prec, got, tt.want)
+		}
+	}
+	// Exact for every precision, and readable by MPFR in base 16.
+	for _, s := range []string{"3.25-1e-300i", "1e300+7i"} {
+		z := MustParse(s, 100)
+		back, err := ParseBase(z.Text('x', -1), 16, 100)
+		if err != nil || back.Cmp(z) != 0 {
+			t.Errorf("%s: hex %q does not read back (%v)", s, z.Text('x', -1), err)
+		}
+	}
+}
+
+func TestStringBase(t *testing.T) {
+	z := MustParse("12-0.1i", 64)
+	tests := []struct {
+		base, digits int
+		want         string
+	}{
+		{2, 5, "1.1000@3-1.1010@-4i"},
+		{10, 0, "1.2@1-1.00000000000000000001@-1i"},
+		{16, 4, "c.000-1.99a@-1i"},
+		{36, 0, "c-3.llllllllllllu@-1i"},
+	}
+	for _, tt := range tests {
+		if got := z.StringBase(tt.base, tt.digits); got != tt.want {
+			t.Errorf("StringBase(%d, %d) = %q, want %q", tt.base, tt.digits, got, tt.want)
+		}
+	}
+	for base := 2; base <= 62; base++ {
+		s := z.StringBase(base, 0)
+		back, err := ParseBase(s, base, 64)
+		if err != nil || back.Cmp(z) != 0 {
+			t.Fatalf("base %d: %q does not read back (%v)", base, s, err)
+		}
+	}
+	if got := New(64).StringBase(7, 0); got != "@NaN@+@NaN@i" {
+		t.Errorf("NaN in base 7 = %q", got)
+	}
+}
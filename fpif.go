@@ -0,0 +1,222 @@
+package apcomplex
+
+/*
+#include <stdlib.h>
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// The interchange form of a Complex is a header followed by both parts in MPFR's
+// portable floating-point interchange format (mpfr_fpif_export), which does not
+// depend on the word size or endianness of the machine:
+//
+//	version  1 byte  (fpifVersion)
+//	prec     8 bytes big-endian
+//	re       4-byte big-endian length, then the fpif bytes
+//	im       4-byte big-endian length, then the fpif bytes
+const fpifVersion = 1
+
+// maxFpifPart bounds the length of one encoded part accepted by ReadFrom.
+const maxFpifPart = 1 << 30
+
+// WriteTo writes c to w in the portable interchange form and implements io.WriterTo.
+func (c *Complex) WriteTo(w io.Writer) (int64, error) {
+	if !c.init {
+		return 0, errors.New("apcomplex: not initialized")
+	}
+	re, err := fpifExport(c.re())
+	if err != nil {
+		return 0, err
+	}
+	im, err := fpifExport(c.im())
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 0, 17+len(re)+len(im))
+	buf = append(buf, fpifVersion)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(c.prec))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(re)))
+	buf = append(buf, re...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(im)))
+	buf = append(buf, im...)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadFrom reads exactly one value written by WriteTo from r into c, which adopts the
+// encoded precision (a zero Complex is initialized with it). Unlike most io.ReaderFrom
+// implementations it stops after that value, so several values can be read in turn.
+// It returns io.EOF if r is at its end before the value starts.
+func (c *Complex) ReadFrom(r io.Reader) (int64, error) {
+	var hdr [9]byte
+	n, err := io.ReadFull(r, hdr[:])
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	if hdr[0] != fpifVersion {
+		return total, fmt.Errorf("apcomplex: unsupported interchange version %d", hdr[0])
+	}
+	prec, err := checkPrec(binary.BigEndian.Uint64(hdr[1:]))
+	if err != nil {
+		return total, err
+	}
+	re, n64, err := readFpifPart(r)
+	total += n64
+	if err != nil {
+		return total, err
+	}
+	im, n64, err := readFpifPart(r)
+	total += n64
+	if err != nil {
+		return total, err
+	}
+	if err := c.prepare(prec); err != nil {
+		return total, err
+	}
+	if err := fpifImport(c.re(), re, prec); err != nil {
+		return total, err
+	}
+	if err := fpifImport(c.im(), im, prec); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+func fpifExport(x *C.__mpfr_struct) ([]byte, error) {
+	var p *C.char
+	var n C.size_t
+	if !fpifWrite(x, &p, &n) {
+		return nil, errors.New("apcomplex: mpfr_fpif_export failed")
+	}
+	defer C.free(unsafe.Pointer(p))
+	return C.GoBytes(unsafe.Pointer(p), C.int(n)), nil
+}
+
+// fpifImport sets x from one fpif record and restores x's precision to prec, which
+// the record would otherwise override.
+func fpifImport(x *C.__mpfr_struct, b []byte, prec uint) error {
+	if len(b) == 0 {
+		return errors.New("apcomplex: empty interchange part")
+	}
+	if _, err := fpifPrec(b); err != nil {
+		return err
+	}
+	p := C.CBytes(b)
+	defer C.free(p)
+	if !fpifRead(x, p, len(b)) {
+		return errors.New("apcomplex: invalid interchange part")
+	}
+	if uint(C.mpfr_get_prec(x)) != prec {
+		C.mpfr_prec_round(x, C.mpfr_prec_t(prec), C.MPFR_RNDN)
+	}
+	return nil
+}
+
+// fpifPrec decodes the precision at the start of an fpif record, which older MPFR
+// releases pass to mpfr_set_prec unchecked, aborting when it is out of range. Small
+// precisions take one byte, prec+7; larger ones a byte giving the length 1 to 8 of a
+// little-endian count, prec-249.
+func fpifPrec(b []byte) (uint, error) {
+	if b[0] > 7 {
+		return checkPrec(uint64(b[0]) - 7)
+	}
+	n := int(b[0]) + 1
+	if len(b) <= n {
+		return 0, errors.New("apcomplex: truncated interchange part")
+	}
+	var p uint64
+	for i := n; i > 0; i-- {
+		p = p<<8 | uint64(b[i])
+	}
+	if p > math.MaxUint64-249 {
+		return 0, errors.New("apcomplex: interchange precision out of range")
+	}
+	return checkPrec(p + 249)
+}
+
+func readFpifPart(r io.Reader) ([]byte, int64, error) {
+	var l [4]byte
+	n, err := io.ReadFull(r, l[:])
+	if err != nil {
+		return nil, int64(n), noEOF(err)
+	}
+	size := binary.BigEndian.Uint32(l[:])
+	if size > maxFpifPart {
+		return nil, int64(n), fmt.Errorf("apcomplex: interchange part of %d bytes too large", size)
+	}
+	b := make([]byte, size)
+	m, err := io.ReadFull(r, b)
+	return b, int64(n + m), noEOF(err)
+}
+
+// noEOF reports a stream ending inside a value as io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Encoder writes a stream of values in the portable interchange form.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder { return &Encoder{w: w} }
+
+// Encode writes the given values in order.
+func (e *Encoder) Encode(zs ...*Complex) error {
+	for _, z := range zs {
+		if _, err := z.WriteTo(e.w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decoder reads a stream of values written by an Encoder. It reads no further than the
+// values it returns, so r may be followed by other data; wrap an unbuffered r in a
+// bufio.Reader for speed.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder { return &Decoder{r: r} }
+
+// Decode reads the next value. It returns io.EOF at the clean end of the stream.
+func (d *Decoder) Decode() (*Complex, error) {
+	z := New(1)
+	if _, err := z.ReadFrom(d.r); err != nil {
+		z.Close()
+		return nil, err
+	}
+	return z, nil
+}
+
+// DecodeAll reads values until the end of the stream.
+func (d *Decoder) DecodeAll() ([]*Complex, error) {
+	var out []*Complex
+	for {
+		z, err := d.Decode()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, z)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+
+package apcomplex
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <mpfr.h>
+
+// mpfr_fpif_export/import work on FILE streams; memory streams let Go own the bytes.
+static int apc_fpif_export(mpfr_ptr x, char **buf, size_t *n) {
+    *buf = NULL;
+    *n = 0;
+    FILE *f = open_memstream(buf, n);
+    if (!f) return -1;
+    int r = mpfr_fpif_export(f, x);
+    if (fclose(f) != 0) r = -1;
+    if (r != 0) {
+        free(*buf);
+        *buf = NULL;
+    }
+    return r;
+}
+
+// apc_fpif_import reads x from the n bytes at buf and fails unless all are consumed.
+static int apc_fpif_import(mpfr_ptr x, void *buf, size_t n) {
+    FILE *f = fmemopen(buf, n, "rb");
+    if (!f) return -1;
+    int r = mpfr_fpif_import(x, f);
+    if (r == 0 && (ftell(f) != (long)n)) r = -1;
+    fclose(f);
+    return r;
+}
+*/
+import "C"
+
+import "unsafe"
+
+// fpifWrite exports x into a malloc'd buffer that the caller frees.
+func fpifWrite(x *C.__mpfr_struct, buf **C.char, n *C.size_t) bool {
+	return C.apc_fpif_export(x, buf, n) == 0
+}
+
+// fpifRead imports x from exactly the n bytes at buf.
+func fpifRead(x *C.__mpfr_struct, buf unsafe.Pointer, n int) bool {
+	return C.apc_fpif_import(x, buf, C.size_t(n)) == 0
+}
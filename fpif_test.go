@@ -0,0 +1,98 @@
+package apcomplex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	for _, z := range marshalSamples() {
+		var buf bytes.Buffer
+		n, err := z.WriteTo(&buf)
+		if err != nil || n != int64(buf.Len()) {
+			t.Fatalf("WriteTo: n=%d len=%d err=%v", n, buf.Len(), err)
+		}
+		data := buf.Bytes()
+		var back Complex
+		m, err := back.ReadFrom(bytes.NewReader(data))
+		if err != nil || m != n || !sameValue(&back, z) {
+			t.Errorf("%v: read back %v (%d of %d bytes, %v)", z, &back, m, n, err)
+		}
+		back.Close()
+		for i := 1; i < len(data); i++ {
+			if _, err := New(8).ReadFrom(bytes.NewReader(data[:i])); err != io.ErrUnexpectedEOF {
+				t.Fatalf("%v: truncated to %d bytes: %v", z, i, err)
+			}
+		}
+	}
+	if _, err := New(8).ReadFrom(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("empty input: %v, want io.EOF", err)
+	}
+	if _, err := New(8).ReadFrom(bytes.NewReader([]byte{9, 0, 0})); err == nil {
+		t.Errorf("unknown version accepted")
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	in := marshalSamples()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(in[:3]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(in[3:]...); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("trailer")
+
+	dec := NewDecoder(&buf)
+	first, err := dec.Decode()
+	if err != nil || !sameValue(first, in[0]) {
+		t.Fatalf("Decode: %v, %v", first, err)
+	}
+	var rest []*Complex
+	for range in[1:] {
+		z, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rest = append(rest, z)
+	}
+	for i, z := range rest {
+		if !sameValue(z, in[i+1]) {
+			t.Errorf("value %d: got %v, want %v", i+1, z, in[i+1])
+		}
+	}
+	if buf.String() != "trailer" {
+		t.Errorf("decoder read past its values: %q left", buf.String())
+	}
+
+	buf.Reset()
+	NewEncoder(&buf).Encode(in...)
+	all, err := NewDecoder(&buf).DecodeAll()
+	if err != nil || len(all) != len(in) {
+		t.Fatalf("DecodeAll: %d values, %v", len(all), err)
+	}
+}
+
+func TestReadFromBadPartPrecision(t *testing.T) {
+	var buf bytes.Buffer
+	MustParse("1+2i", 53).WriteTo(&buf)
+	data := buf.Bytes()
+	reLen := binary.BigEndian.Uint32(data[9:])
+	im := data[13+reLen:]
+	for _, re := range [][]byte{
+		{7, 255, 255, 255, 255, 255, 255, 255, 255, 0}, // 2⁶⁴+248 bits
+		{7, 0, 0, 0, 0, 0, 0, 0, 128, 0},               // 2⁶³+249 bits
+		{3, 1, 2},                                      // cut inside the precision
+	} {
+		bad := append([]byte(nil), data[:9]...)
+		bad = binary.BigEndian.AppendUint32(bad, uint32(len(re)))
+		bad = append(append(bad, re...), im...)
+		if _, err := New(8).ReadFrom(bytes.NewReader(bad)); err == nil {
+			t.Errorf("part % x accepted", re)
+		}
+	}
+}
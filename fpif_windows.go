@@ -0,0 +1,57 @@
+package apcomplex
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <mpfr.h>
+
+// The Windows C runtime has neither open_memstream nor fmemopen, so the records pass
+// through a temporary file instead.
+static int apc_fpif_export(mpfr_ptr x, char **buf, size_t *n) {
+    *buf = NULL;
+    *n = 0;
+    FILE *f = tmpfile();
+    if (!f) return -1;
+    int r = mpfr_fpif_export(f, x);
+    long size = r == 0 ? ftell(f) : -1;
+    if (size < 0 || fseek(f, 0, SEEK_SET) != 0) r = -1;
+    if (r == 0) {
+        *buf = malloc(size > 0 ? size : 1);
+        if (!*buf || fread(*buf, 1, size, f) != (size_t)size) r = -1;
+    }
+    fclose(f);
+    if (r != 0) {
+        free(*buf);
+        *buf = NULL;
+    } else {
+        *n = size;
+    }
+    return r;
+}
+
+// apc_fpif_import reads x from the n bytes at buf and fails unless all are consumed.
+static int apc_fpif_import(mpfr_ptr x, void *buf, size_t n) {
+    FILE *f = tmpfile();
+    if (!f) return -1;
+    int r = -1;
+    if (fwrite(buf, 1, n, f) == n && fseek(f, 0, SEEK_SET) == 0) {
+        r = mpfr_fpif_import(x, f);
+        if (r == 0 && (ftell(f) != (long)n)) r = -1;
+    }
+    fclose(f);
+    return r;
+}
+*/
+import "C"
+
+import "unsafe"
+
+// fpifWrite exports x into a malloc'd buffer that the caller frees.
+func fpifWrite(x *C.__mpfr_struct, buf **C.char, n *C.size_t) bool {
+	return C.apc_fpif_export(x, buf, n) == 0
+}
+
+// fpifRead imports x from exactly the n bytes at buf.
+func fpifRead(x *C.__mpfr_struct, buf unsafe.Pointer, n int) bool {
+	return C.apc_fpif_import(x, buf, C.size_t(n)) == 0
+}
@@ -0,0 +1,613 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_pi_mul sets rop to π·k for a regular k, correctly rounded, and returns the ternary
+// value.
+static int apc_pi_mul(mpfr_ptr rop, mpfr_srcptr k, mpfr_rnd_t rnd) {
+    mpfr_prec_t prec = mpfr_get_prec(rop), wp;
+    mpfr_t y;
+    int ret;
+
+    mpfr_init2(y, MPFR_PREC_MIN);
+    for (wp = prec + 32;; wp += wp / 2) {
+        mpfr_set_prec(y, wp);
+        mpfr_const_pi(y, MPFR_RNDN);
+        mpfr_mul(y, y, k, MPFR_RNDN);
+        // two roundings of relative error 2^-wp each; π·k is irrational, so this ends
+        if (mpfr_can_round(y, wp - 2, MPFR_RNDN, rnd, prec + (rnd == MPFR_RNDN))) {
+            ret = mpfr_set(rop, y, rnd);
+            break;
+        }
+    }
+    mpfr_clear(y);
+    return ret;
+}
+*/
+import "C"
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// The Gamma family over the complex plane. MPC has none of these, so they are evaluated
+// here with the Stirling series at a working precision with guard bits: an argument left
+// of Re z = 1/2 is first reflected, and one still too close to the origin is shifted
+// right with the recurrence until the series reaches the working precision.
+//
+// Gamma, LogGamma and Digamma of a real argument are correctly rounded: MPFR supplies
+// the real values and apc_pi_mul the imaginary part of LogGamma on the negative axis.
+// Polygamma and all complex arguments are rounded once from the working precision,
+// whose guard bits leave an error of about an ulp that is not proven. The poles 0, -1,
+// -2, … give +∞ + 0i.
+
+// gammaGuard is the number of guard bits of the working precision.
+const gammaGuard = 32
+
+// gammaWorkPrec returns the working precision for a result at c's precision from a.
+// When the value is an exponential of a Stirling sum (Gamma), the sum's absolute error
+// becomes the relative one, so its magnitude, about |a| log|a| < 2^(e + log₂ e) for
+// |a| < 2^e, costs e further bits beyond those of log|a|.
+func gammaWorkPrec(c, a *Complex, exponential bool) uint {
+	wp := max(c.prec, a.prec) + gammaGuard
+	if e := magExp(a); e > 0 {
+		if exponential {
+			wp += uint(e)
+		}
+		wp += uint(bits.Len(uint(e)))
+	}
+	return wp
+}
+
+// magExp returns the binary exponent of the larger part of c, so that |c| < 2^(magExp+1),
+// or math.MinInt if c is zero. Non-finite parts are ignored.
+func magExp(c *Complex) int {
+	e := math.MinInt
+	for _, x := range []*C.__mpfr_struct{c.re(), c.im()} {
+		if C.mpfr_regular_p(x) != 0 {
+			e = max(e, int(C.mpfr_get_exp(x)))
+		}
+	}
+	return e
+}
+
+// stirlingRadius is how large |w| must be for the Stirling series of ψ⁽ⁿ⁾ (n = 0 also
+// for log Γ) to reach wp bits: its smallest term is about e^(-2π|w|) relative.
+func stirlingRadius(wp, n uint) float64 { return 0.15*float64(wp) + float64(n) + 8 }
+
+// bernoulliCache holds B_2, B_4, … at the highest precision requested so far. The
+// values are shared and must not be modified.
+var bernoulliCache struct {
+	mu   sync.Mutex
+	prec uint
+	b    []*Real // b[k] = B_2k; b[0] is unused
+	t    *Real   // (2k)!/(2π)^2k for the last k in b
+	pi2  *Real   // 4π²
+}
+
+// bernoulli2k returns the Bernoulli number B_2k (k ≥ 1) with at least prec bits, from
+// B_2k = (-1)^(k+1) 2 (2k)! ζ(2k) / (2π)^2k.
+func bernoulli2k(k int, prec uint) *Real {
+	bc := &bernoulliCache
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if prec > bc.prec || bc.b == nil {
+		// The running factorial loses about log2(k) bits.
+		bc.prec = max(prec+16, bc.prec)
+		bc.b = []*Real{nil}
+		bc.t = NewReal(bc.prec).SetFloat64(1)
+		pi := NewReal(bc.prec).SetConst(ConstPi)
+		bc.pi2 = NewReal(bc.prec).Sqr(pi)
+		C.mpfr_mul_2si(bc.pi2.ptr(), bc.pi2.ptr(), 2, C.MPFR_RNDN)
+	}
+	for j := len(bc.b); j <= k; j++ {
+		C.mpfr_mul_ui(bc.t.ptr(), bc.t.ptr(), C.ulong(2*j*(2*j-1)), C.MPFR_RNDN)
+		bc.t.Div(bc.t, bc.pi2, RNDN)
+		b := NewReal(bc.prec)
+		C.mpfr_zeta_ui(b.ptr(), C.ulong(2*j), C.MPFR_RNDN)
+		b.Mul(b, bc.t, RNDN)
+		C.mpfr_mul_2si(b.ptr(), b.ptr(), 1, C.MPFR_RNDN)
+		if j%2 == 0 {
+			b.Neg(b)
+		}
+		bc.b = append(bc.b, b)
+	}
+	return bc.b[k]
+}
+
+// shiftCount returns how many unit steps take z (Re z ≥ 1/2) out to the Stirling radius.
+func shiftCount(z *Complex, r float64) int {
+	v := z.Complex128()
+	if math.Hypot(real(v), imag(v)) >= r {
+		return 0
+	}
+	return int(math.Ceil(r - real(v)))
+}
+
+// risingProduct returns z(z+1)…(z+m-1) at wp bits together with the sum of the
+// arguments of the factors, which picks the branch of its logarithm.
+func risingProduct(z *Complex, m int, wp uint) (p *Complex, args float64) {
+	p = New(wp).SetInt64(1, 0)
+	f := New(wp)
+	defer f.Close()
+	for k := 0; k < m; k++ {
+		C.mpc_add_ui(&f.z[0], &z.z[0], C.ulong(k), C.MPC_RNDNN)
+		args += f.ArgFloat64()
+		p.Mul(p, f, RNDNN)
+	}
+	return p, args
+}
+
+// stirlingLnGamma sets s = log Γ(w) by the Stirling series
+//
+//	(w - 1/2) log w - w + log(2π)/2 + Σ B_2k / (2k(2k-1) w^(2k-1)),
+//
+// summed until the terms fall below 2^-wp relative to s. |w| must be at least the
+// Stirling radius and Re w > 0.
+func stirlingLnGamma(s, w *Complex, wp uint) {
+	t, inv, inv2 := New(wp), New(wp).Inv(w, RNDNN), New(wp)
+	defer t.Close()
+	defer inv.Close()
+	defer inv2.Close()
+	inv2.Sqr(inv, RNDNN)
+	l2pi := NewReal(wp).SetConst(ConstPi)
+	defer l2pi.Close()
+	C.mpfr_mul_2si(l2pi.ptr(), l2pi.ptr(), 1, C.MPFR_RNDN)
+	l2pi.Log(l2pi, RNDN)
+	C.mpfr_div_2si(l2pi.ptr(), l2pi.ptr(), 1, C.MPFR_RNDN)
+
+	t.Log(w, RNDNN)
+	C.mpc_sub_fr(&s.z[0], &w.z[0], half(wp).ptr(), C.MPC_RNDNN)
+	s.Mul(s, t, RNDNN).Sub(s, w, RNDNN)
+	C.mpc_add_fr(&s.z[0], &s.z[0], l2pi.ptr(), C.MPC_RNDNN)
+	for k := 1; ; k++ {
+		C.mpc_mul_fr(&t.z[0], &inv.z[0], bernoulli2k(k, wp).ptr(), C.MPC_RNDNN)
+		C.mpc_div_ui(&t.z[0], &t.z[0], C.ulong(2*k*(2*k-1)), C.MPC_RNDNN)
+		s.Add(s, t, RNDNN)
+		if magExp(t) < magExp(s)-int(wp) || k > int(wp) {
+			return
+		}
+		inv.Mul(inv, inv2, RNDNN)
+	}
+}
+
+// stirlingPolygamma sets s = ψ⁽ⁿ⁾(w) by the asymptotic series
+//
+//	ψ(w) = log w - 1/(2w) - Σ B_2k / (2k w^2k)
+//	ψ⁽ⁿ⁾(w) = (-1)^(n+1) [(n-1)!/wⁿ + n!/(2w^(n+1)) + Σ B_2k (2k+n-1)! / ((2k)! w^(2k+n))]
+//
+// under the same conditions as stirlingLnGamma.
+func stirlingPolygamma(s, w *Complex, n uint, wp uint) {
+	t, p, inv, inv2 := New(wp), New(wp), New(wp).Inv(w, RNDNN), New(wp)
+	defer t.Close()
+	defer p.Close()
+	defer inv.Close()
+	defer inv2.Close()
+	inv2.Sqr(inv, RNDNN)
+	f := NewReal(wp) // (2k+n-1)!/(2k)!, 1/(2k) for n = 0
+	defer f.Close()
+	if n == 0 {
+		s.Log(w, RNDNN)
+		C.mpc_div_2si(&t.z[0], &inv.z[0], 1, C.MPC_RNDNN)
+		s.Sub(s, t, RNDNN)
+		p.Set(inv2, RNDNN)
+	} else {
+		C.mpfr_fac_ui(f.ptr(), C.ulong(n-1), C.MPFR_RNDN)
+		p.PowUint64(inv, uint64(n), RNDNN)
+		C.mpc_mul_fr(&s.z[0], &p.z[0], f.ptr(), C.MPC_RNDNN)
+		// n!/(2w^(n+1)) = (n-1)!/wⁿ · n/(2w)
+		C.mpc_mul_ui(&t.z[0], &s.z[0], C.ulong(n), C.MPC_RNDNN)
+		t.Mul(t, inv, RNDNN)
+		C.mpc_div_2si(&t.z[0], &t.z[0], 1, C.MPC_RNDNN)
+		s.Add(s, t, RNDNN)
+		p.Mul(p, inv2, RNDNN)
+	}
+	sum := New(wp)
+	defer sum.Close()
+	sum.SetInt64(0, 0)
+	for k := uint(1); ; k++ {
+		if n == 0 {
+			f.SetInt64(int64(2 * k))
+			C.mpfr_ui_div(f.ptr(), 1, f.ptr(), C.MPFR_RNDN)
+		} else {
+			C.mpfr_mul_ui(f.ptr(), f.ptr(), C.ulong((2*k+n-1)*(2*k+n-2)), C.MPFR_RNDN)
+			C.mpfr_div_ui(f.ptr(), f.ptr(), C.ulong(2*k*(2*k-1)), C.MPFR_RNDN)
+		}
+		C.mpc_mul_fr(&t.z[0], &p.z[0], bernoulli2k(int(k), wp).ptr(), C.MPC_RNDNN)
+		C.mpc_mul_fr(&t.z[0], &t.z[0], f.ptr(), C.MPC_RNDNN)
+		sum.Add(sum, t, RNDNN)
+		if magExp(t) < magExp(s)-int(wp) || k > wp {
+			break
+		}
+		p.Mul(p, inv2, RNDNN)
+	}
+	if n == 0 {
+		s.Sub(s, sum, RNDNN)
+	} else {
+		s.Add(s, sum, RNDNN)
+		if n%2 == 0 {
+			s.Neg(s, RNDNN)
+		}
+	}
+}
+
+// half returns 1/2 at wp bits.
+func half(wp uint) *Real { return NewReal(wp).SetFloat64(0.5) }
+
+// reduceUnit sets t = z - m for the integer m with Re t in (-1/2, 1/2], exactly, and
+// returns m. wp must be at least the precision of z. Re t = -1/2 is avoided because
+// the argument of sin(πt) there is ±π depending on how π was rounded.
+func reduceUnit(t, z *Complex, wp uint) *Real {
+	m := NewReal(wp)
+	C.mpfr_rint(m.ptr(), z.re(), C.MPFR_RNDN)
+	t.Set(z, RNDNN)
+	C.mpfr_sub(t.re(), t.re(), m.ptr(), C.MPFR_RNDN)
+	if C.mpfr_cmp_d(t.re(), -0.5) == 0 {
+		C.mpfr_add_ui(t.re(), t.re(), 1, C.MPFR_RNDN)
+		C.mpfr_sub_ui(m.ptr(), m.ptr(), 1, C.MPFR_RNDN)
+	}
+	return m
+}
+
+// isOdd reports whether the integer m is odd.
+func isOdd(m *Real) bool {
+	h := NewReal(m.prec)
+	defer h.Close()
+	C.mpfr_div_2ui(h.ptr(), m.ptr(), 1, C.MPFR_RNDN)
+	return !h.IsInt()
+}
+
+// isPole reports whether a is one of the poles 0, -1, -2, … of Gamma.
+func isPole(a *Complex) bool {
+	return C.mpfr_zero_p(a.im()) != 0 && C.mpfr_integer_p(a.re()) != 0 && C.mpfr_sgn(a.re()) <= 0
+}
+
+// setGammaSpecial handles the arguments that need no series: NaN and infinite parts
+// and the poles. At +∞ the functions are +∞ (inf) or 0 (higher polygammas). It
+// reports whether c has been set.
+func (c *Complex) setGammaSpecial(a *Complex, inf bool) bool {
+	switch {
+	case isPole(a):
+		C.mpfr_set_inf(c.re(), 1)
+		C.mpfr_set_zero(c.im(), 1)
+	case C.mpfr_number_p(a.re()) != 0 && C.mpfr_number_p(a.im()) != 0:
+		return false
+	case C.mpfr_inf_p(a.re()) != 0 && C.mpfr_sgn(a.re()) > 0 && C.mpfr_zero_p(a.im()) != 0:
+		if inf {
+			C.mpfr_set_inf(c.re(), 1)
+		} else {
+			C.mpfr_set_zero(c.re(), 1)
+		}
+		C.mpfr_set_zero(c.im(), 1)
+	default:
+		C.mpc_set_nan(&c.z[0])
+	}
+	c.inex = 0
+	return true
+}
+
+// setRealAxis sets c = f(Re a) + 0i for real a, keeping the sign of a's zero imaginary
+// part.
+func (c *Complex) setRealAxis(a *Complex, m RoundingMode, f func(x, y *C.__mpfr_struct, r C.mpfr_rnd_t) C.int) {
+	sign := C.int(1)
+	if C.mpfr_signbit(a.im()) != 0 {
+		sign = -1
+	}
+	tr := f(c.re(), a.re(), m.Re().mpfr())
+	C.mpfr_set_zero(c.im(), sign)
+	c.inex = inexOf(tr, 0)
+}
+
+// Gamma sets c = Γ(a) and returns c.
+func (c *Complex) Gamma(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setGammaSpecial(a, true) {
+		return c
+	}
+	if C.mpfr_zero_p(a.im()) != 0 {
+		c.setRealAxis(a, m, func(x, y *C.__mpfr_struct, r C.mpfr_rnd_t) C.int { return C.mpfr_gamma(x, y, r) })
+		return c
+	}
+	wp := gammaWorkPrec(c, a, true)
+	s := New(wp)
+	defer s.Close()
+	if C.mpfr_cmp_d(a.re(), 0.5) < 0 {
+		// Γ(z) = π / (sin(πz) Γ(1-z)), sin(πz) = (-1)^m sin(πt) for t = z - m
+		t, g := New(wp), New(wp)
+		defer t.Close()
+		defer g.Close()
+		odd := isOdd(reduceUnit(t, a, wp))
+		C.mpc_ui_ui_sub(&g.z[0], 1, 0, &a.z[0], C.MPC_RNDNN)
+		gammaRight(s, g, wp)
+		pi := NewReal(wp).SetConst(ConstPi)
+		defer pi.Close()
+		C.mpc_mul_fr(&t.z[0], &t.z[0], pi.ptr(), C.MPC_RNDNN)
+		t.Sin(t, RNDNN)
+		s.Mul(s, t, RNDNN)
+		C.mpc_fr_div(&s.z[0], pi.ptr(), &s.z[0], C.MPC_RNDNN)
+		if odd {
+			s.Neg(s, RNDNN)
+		}
+	} else {
+		gammaRight(s, a, wp)
+	}
+	c.inex = C.mpc_set(&c.z[0], &s.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// gammaRight sets s = Γ(z) = exp(log Γ(z+m)) / (z(z+1)…(z+m-1)) for Re z ≥ 1/2.
+func gammaRight(s, z *Complex, wp uint) {
+	w := New(wp)
+	defer w.Close()
+	m := shiftCount(z, stirlingRadius(wp, 0))
+	C.mpc_add_ui(&w.z[0], &z.z[0], C.ulong(m), C.MPC_RNDNN)
+	stirlingLnGamma(s, w, wp)
+	s.Exp(s, RNDNN)
+	if m > 0 {
+		p, _ := risingProduct(z, m, wp)
+		defer p.Close()
+		s.Div(s, p, RNDNN)
+	}
+}
+
+// LogGamma sets c = log Γ(a) and returns c. It is the analytic continuation of the real
+// log Γ from the positive axis, not the principal logarithm of Γ(a): its imaginary part
+// grows without bound and it is continuous everywhere except across the negative real
+// axis. There the sign of the zero imaginary part of a selects the side, as for Log, so
+// for real x < 0 the imaginary part is -π⌈-x⌉ (+π⌈-x⌉ for a zero with the sign bit set).
+func (c *Complex) LogGamma(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setGammaSpecial(a, true) {
+		return c
+	}
+	if C.mpfr_zero_p(a.im()) != 0 {
+		c.setRealLogGamma(a, m)
+		return c
+	}
+	wp := gammaWorkPrec(c, a, false)
+	s, z := New(wp), New(wp)
+	defer s.Close()
+	defer z.Close()
+	// log Γ(conj z) = conj log Γ(z): work in the upper half-plane.
+	lower := C.mpfr_sgn(a.im()) < 0
+	z.Set(a, RNDNN)
+	if lower {
+		z.Conj(z, RNDNN)
+	}
+	if C.mpfr_cmp_d(z.re(), 0.5) < 0 {
+		// log Γ(z) = log π - L(z) - log Γ(1-z), where L is the logarithm of sin(πz)
+		// continuous in the upper half-plane and real on Re z = 1/2. With t = z - m,
+		// L(z) = Log sin(πt) - iπm.
+		t, g := New(wp), New(wp)
+		defer t.Close()
+		defer g.Close()
+		k := reduceUnit(t, z, wp)
+		defer k.Close()
+		C.mpc_ui_ui_sub(&g.z[0], 1, 0, &z.z[0], C.MPC_RNDNN)
+		lnGammaRight(s, g, wp)
+		pi := NewReal(wp).SetConst(ConstPi)
+		defer pi.Close()
+		C.mpc_mul_fr(&t.z[0], &t.z[0], pi.ptr(), C.MPC_RNDNN)
+		t.Sin(t, RNDNN).Log(t, RNDNN)
+		s.Add(s, t, RNDNN)
+		k.Mul(k, pi, RNDN)
+		C.mpfr_sub(s.im(), s.im(), k.ptr(), C.MPFR_RNDN)
+		pi.Log(pi, RNDN)
+		C.mpc_fr_sub(&s.z[0], pi.ptr(), &s.z[0], C.MPC_RNDNN)
+	} else {
+		lnGammaRight(s, z, wp)
+	}
+	if lower {
+		s.Conj(s, RNDNN)
+	}
+	c.inex = C.mpc_set(&c.z[0], &s.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// lnGammaRight sets s = log Γ(z) = log Γ(z+m) - log(z(z+1)…(z+m-1)) for Re z ≥ 1/2,
+// the logarithm of the product taken on the branch that sums the factors' logarithms.
+func lnGammaRight(s, z *Complex, wp uint) {
+	w := New(wp)
+	defer w.Close()
+	m := shiftCount(z, stirlingRadius(wp, 0))
+	C.mpc_add_ui(&w.z[0], &z.z[0], C.ulong(m), C.MPC_RNDNN)
+	stirlingLnGamma(s, w, wp)
+	if m == 0 {
+		return
+	}
+	p, args := risingProduct(z, m, wp)
+	defer p.Close()
+	p.Log(p, RNDNN)
+	if turns := math.Round((args - p.Im().Float64()) / (2 * math.Pi)); turns != 0 {
+		tau := NewReal(wp).SetConst(ConstPi)
+		defer tau.Close()
+		C.mpfr_mul_si(tau.ptr(), tau.ptr(), C.long(2*turns), C.MPFR_RNDN)
+		C.mpfr_add(p.im(), p.im(), tau.ptr(), C.MPFR_RNDN)
+	}
+	s.Sub(s, p, RNDNN)
+}
+
+// setRealLogGamma sets c = log Γ(x) for real x, the real part by MPFR's lgamma and the
+// imaginary part ∓π⌈-x⌉ correctly rounded by apc_pi_mul.
+func (c *Complex) setRealLogGamma(a *Complex, m RoundingMode) {
+	neg := C.mpfr_sgn(a.re()) < 0
+	below := C.mpfr_signbit(a.im()) != 0
+	// ⌈-x⌉ is exact with one more bit than x.
+	k := NewReal(a.prec + 1)
+	defer k.Close()
+	C.mpfr_neg(k.ptr(), a.re(), C.MPFR_RNDN)
+	C.mpfr_ceil(k.ptr(), k.ptr())
+	var sign C.int
+	tr := C.mpfr_lgamma(c.re(), &sign, a.re(), m.Re().mpfr())
+	if !neg {
+		if below {
+			C.mpfr_set_zero(c.im(), -1)
+		} else {
+			C.mpfr_set_zero(c.im(), 1)
+		}
+		c.inex = inexOf(tr, 0)
+		return
+	}
+	if !below {
+		C.mpfr_neg(k.ptr(), k.ptr(), C.MPFR_RNDN)
+	}
+	ti := C.apc_pi_mul(c.im(), k.ptr(), m.Im().mpfr())
+	c.inex = inexOf(tr, ti)
+}
+
+// Digamma sets c = ψ(a) = Γ'(a)/Γ(a) and returns c.
+func (c *Complex) Digamma(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setGammaSpecial(a, true) {
+		return c
+	}
+	if C.mpfr_zero_p(a.im()) != 0 {
+		c.setRealAxis(a, m, func(x, y *C.__mpfr_struct, r C.mpfr_rnd_t) C.int { return C.mpfr_digamma(x, y, r) })
+		return c
+	}
+	return c.polygamma(0, a, m)
+}
+
+// Polygamma sets c = ψ⁽ⁿ⁾(a), the n-th derivative of the digamma function, and returns
+// c. Polygamma(0, a) is Digamma(a).
+func (c *Complex) Polygamma(n uint, a *Complex, mode ...RoundingMode) *Complex {
+	if n == 0 {
+		return c.Digamma(a, mode...)
+	}
+	m := RoundingMode(c.rnd(mode))
+	if c.setGammaSpecial(a, false) {
+		return c
+	}
+	// On the real axis the result is real; keep the sign of the zero as elsewhere.
+	onAxis := C.mpfr_zero_p(a.im()) != 0
+	sign := C.int(1)
+	if C.mpfr_signbit(a.im()) != 0 {
+		sign = -1
+	}
+	c.polygamma(n, a, m)
+	if onAxis {
+		C.mpfr_set_zero(c.im(), sign)
+	}
+	return c
+}
+
+// polygamma sets c = ψ⁽ⁿ⁾(a) for finite a off the poles.
+func (c *Complex) polygamma(n uint, a *Complex, m RoundingMode) *Complex {
+	wp := gammaWorkPrec(c, a, false) + uint(bits.Len(n))
+	s := New(wp)
+	defer s.Close()
+	if C.mpfr_cmp_d(a.re(), 0.5) < 0 {
+		// ψ⁽ⁿ⁾(z) = (-1)ⁿ ψ⁽ⁿ⁾(1-z) - π dⁿ/dzⁿ cot(πz), and with u = cot(πz)
+		// dⁿ/dzⁿ cot(πz) = πⁿ csc²(πz) Qₙ₋₁(u) (see cotDerivPoly) for n ≥ 1.
+		t, g := New(wp), New(wp)
+		defer t.Close()
+		defer g.Close()
+		reduceUnit(t, a, wp).Close()
+		C.mpc_ui_ui_sub(&g.z[0], 1, 0, &a.z[0], C.MPC_RNDNN)
+		polygammaRight(s, g, n, wp)
+		if n%2 == 1 {
+			s.Neg(s, RNDNN)
+		}
+		pi := NewReal(wp).SetConst(ConstPi)
+		defer pi.Close()
+		C.mpc_mul_fr(&t.z[0], &t.z[0], pi.ptr(), C.MPC_RNDNN)
+		if n == 0 {
+			t.Tan(t, RNDNN).Inv(t, RNDNN)
+		} else {
+			sin, cos := New(wp), New(wp)
+			defer sin.Close()
+			defer cos.Close()
+			sin.SinCos(cos, t, RNDNN)
+			u := Div(cos, sin, RNDNN)
+			defer u.Close()
+			hornerBig(t, cotDerivPoly(n), u, wp)
+			t.Div(t, sin.Sqr(sin, RNDNN), RNDNN)
+			C.mpfr_pow_ui(pi.ptr(), pi.ptr(), C.ulong(n+1), C.MPFR_RNDN)
+		}
+		C.mpc_mul_fr(&t.z[0], &t.z[0], pi.ptr(), C.MPC_RNDNN)
+		s.Sub(s, t, RNDNN)
+	} else {
+		polygammaRight(s, a, n, wp)
+	}
+	c.inex = C.mpc_set(&c.z[0], &s.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// polygammaRight sets s = ψ⁽ⁿ⁾(z) = ψ⁽ⁿ⁾(z+m) - (-1)ⁿ n! Σ 1/(z+k)^(n+1) for Re z ≥ 1/2.
+func polygammaRight(s, z *Complex, n uint, wp uint) {
+	w := New(wp)
+	defer w.Close()
+	m := shiftCount(z, stirlingRadius(wp, n))
+	C.mpc_add_ui(&w.z[0], &z.z[0], C.ulong(m), C.MPC_RNDNN)
+	stirlingPolygamma(s, w, n, wp)
+	if m == 0 {
+		return
+	}
+	sum := New(wp).SetInt64(0, 0)
+	defer sum.Close()
+	for k := 0; k < m; k++ {
+		C.mpc_add_ui(&w.z[0], &z.z[0], C.ulong(k), C.MPC_RNDNN)
+		w.PowInt64(w, -int64(n+1), RNDNN)
+		sum.Add(sum, w, RNDNN)
+	}
+	if n > 0 {
+		f := NewReal(wp)
+		defer f.Close()
+		C.mpfr_fac_ui(f.ptr(), C.ulong(n), C.MPFR_RNDN)
+		C.mpc_mul_fr(&sum.z[0], &sum.z[0], f.ptr(), C.MPC_RNDNN)
+	}
+	if n%2 == 1 {
+		s.Add(s, sum, RNDNN)
+	} else {
+		s.Sub(s, sum, RNDNN)
+	}
+}
+
+// cotDerivPoly returns the coefficients, constant term first, of the polynomial Qₙ₋₁
+// with dⁿ/dzⁿ cot(πz) = πⁿ csc²(πz) Qₙ₋₁(cot πz) for n ≥ 1. Factoring out csc² = 1 + u²
+// avoids the cancellation of evaluating the full derivative polynomial near u = ±i.
+// From Q₀ = -1 and d/dz cot(πz) = -π(1 + u²): Qⱼ = -(2u Qⱼ₋₁ + (1 + u²) Q'ⱼ₋₁).
+func cotDerivPoly(n uint) []*big.Int {
+	q := []*big.Int{big.NewInt(-1)}
+	for j := uint(1); j < n; j++ {
+		next := make([]*big.Int, len(q)+1)
+		for i := range next {
+			next[i] = new(big.Int)
+		}
+		var t big.Int
+		for i, c := range q {
+			next[i+1].Sub(next[i+1], t.Lsh(c, 1))
+			if i > 0 {
+				t.Mul(c, big.NewInt(int64(i)))
+				next[i-1].Sub(next[i-1], &t)
+				next[i+1].Sub(next[i+1], &t)
+			}
+		}
+		q = next
+	}
+	return q
+}
+
+// hornerBig sets s = Σ coef[i] uⁱ.
+func hornerBig(s *Complex, coef []*big.Int, u *Complex, wp uint) {
+	c := New(wp)
+	defer c.Close()
+	s.SetInt64(0, 0)
+	for i := len(coef) - 1; i >= 0; i-- {
+		s.Mul(s, u, RNDNN)
+		s.Add(s, c.SetBigInt(coef[i], nil), RNDNN)
+	}
+}
+
+// Non-mutating forms.
+func Gamma(a *Complex, mode ...RoundingMode) *Complex    { return newLike(a).Gamma(a, mode...) }
+func LogGamma(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).LogGamma(a, mode...) }
+func Digamma(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Digamma(a, mode...) }
+func Polygamma(n uint, a *Complex, mode ...RoundingMode) *Complex {
+	return newLike(a).Polygamma(n, a, mode...)
+}
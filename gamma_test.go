@@ -0,0 +1,215 @@
+package apcomplex
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestGammaKnown(t *testing.T) {
+	want := MustParse("-0.15494982830181068512495513048388660519593-0.49801566811835604271369111746219809195296i", 200)
+	if got := Gamma(I(200)); !got.WithinAbs(want, MustParseReal("1e-40", 64)) {
+		t.Errorf("Γ(i) = %v, want %v", got, want)
+	}
+	// Real arguments are correctly rounded by MPFR.
+	sqrtPi := Sqrt(Pi(100))
+	if got := Gamma(MustParse("0.5", 100)); !got.Equal(sqrtPi) {
+		t.Errorf("Γ(1/2) = %v, want %v", got, sqrtPi)
+	}
+	if got, want := Gamma(MustParse("-1.5", 100)), Mul(sqrtPi, MustParse("1.3333333333333333333333333333333333", 100)); !got.WithinULP(want, 2) {
+		t.Errorf("Γ(-3/2) = %v, want %v", got, want)
+	}
+	for _, s := range []string{"0", "-1", "-7"} {
+		if got := Gamma(MustParse(s, 64)); !got.Re().IsInf() || !got.Im().IsZero() {
+			t.Errorf("Γ(%s) = %v, want ∞", s, got)
+		}
+	}
+	if got := Gamma(MustParse("nan+i", 64)); !got.IsNaN() {
+		t.Errorf("Γ(nan+i) = %v", got)
+	}
+}
+
+func TestGammaIdentities(t *testing.T) {
+	for _, prec := range []uint{64, 300, 1000} {
+		one := MustParse("1", prec)
+		pi := Pi(prec)
+		for _, s := range []string{"0.3+0.7i", "2.5-3i", "-4.25+1.5i", "-30.7-0.01i", "1e-5+12i", "40+40i"} {
+			z := MustParse(s, prec)
+			g := Gamma(z)
+			// Reflection: Γ(z)Γ(1-z) = π/sin(πz)
+			lhs := Mul(g, Gamma(Sub(one, z)))
+			if rhs := Div(pi, Sin(Mul(pi, z))); !relClose(lhs, rhs, 8) {
+				t.Errorf("%d bits, z = %s: Γ(z)Γ(1-z) = %v, π/sin(πz) = %v", prec, s, lhs, rhs)
+			}
+			// Duplication: Γ(z)Γ(z+1/2) = 2^(1-2z) √π Γ(2z)
+			h := MustParse("0.5", prec)
+			lhs = Mul(g, Gamma(Add(z, h)))
+			rhs := Mul(Pow(MustParse("2", prec), Sub(one, Add(z, z))), Mul(Sqrt(pi), Gamma(Add(z, z))))
+			if !relClose(lhs, rhs, 10) {
+				t.Errorf("%d bits, z = %s: duplication %v vs %v", prec, s, lhs, rhs)
+			}
+			// exp(log Γ(z)) = Γ(z)
+			if e := Exp(LogGamma(z)); !relClose(e, g, 12) {
+				t.Errorf("%d bits, z = %s: exp(logΓ) = %v, Γ = %v", prec, s, e, g)
+			}
+		}
+		// |Γ(iy)|² = π / (y sinh πy)
+		y := MustParse("2.75", prec)
+		n := Gamma(MulI(y, 1)).Norm()
+		want := Div(pi, Mul(y, Sinh(Mul(pi, y))))
+		if got := New(prec).SetReals(n, nil); !got.WithinULP(want, 8) {
+			t.Errorf("%d bits: |Γ(2.75i)|² = %v, want %v", prec, got, want)
+		}
+	}
+}
+
+func TestLogGammaBranch(t *testing.T) {
+	// On the continuous branch log Γ(z+1) = log Γ(z) + log z for Im z > 0 exactly,
+	// however far left z is.
+	for _, s := range []string{"0.5+1e-3i", "-10.5+1e-3i", "-10+1e-3i", "-1000.25+3i", "-3.5+50i", "7+0.5i"} {
+		z := MustParse(s, 200)
+		lhs := LogGamma(Add(z, MustParse("1", 200)))
+		rhs := Add(LogGamma(z), Log(z))
+		if !lhs.WithinAbs(rhs, MustParseReal("1e-50", 64)) {
+			t.Errorf("z = %s: logΓ(z+1) = %v, logΓ(z) + log z = %v", s, lhs, rhs)
+		}
+		// Conjugate symmetry.
+		if got, want := LogGamma(Conj(z)), Conj(LogGamma(z)); !got.Equal(want) {
+			t.Errorf("logΓ(conj %s) = %v, want %v", s, got, want)
+		}
+	}
+	// On the negative axis the sign of the zero picks the side: -π⌈-x⌉ from above.
+	tests := []struct {
+		x     string
+		turns int64
+	}{{"-0.5", 1}, {"-2.5", 3}, {"-10.25", 11}, {"3.5", 0}}
+	for _, tt := range tests {
+		above := LogGamma(MustParse(tt.x, 100))
+		below := LogGamma(Conj(MustParse(tt.x, 100)))
+		want := Mul(Pi(100), New(100).SetInt64(-tt.turns, 0))
+		if got := above.Im(); !New(100).SetReals(got, nil).WithinULP(want, 1) {
+			t.Errorf("Im logΓ(%s+0i) = %v, want %v", tt.x, got, want)
+		}
+		if !below.Equal(Conj(above)) {
+			t.Errorf("logΓ(%s-0i) = %v, want %v", tt.x, below, Conj(above))
+		}
+		near := LogGamma(MustParse(tt.x+"+1e-40i", 100))
+		if !near.WithinAbs(above, MustParseReal("1e-25", 64)) {
+			t.Errorf("logΓ just above %s = %v, on the axis %v", tt.x, near, above)
+		}
+	}
+	// The imaginary part is correctly rounded in every mode.
+	for _, k := range []int64{3, 11, 1000001} {
+		x := New(64).SetFloat64(0.5-float64(k), 0)
+		hi := Mul(Pi(400), New(400).SetInt64(-k, 0))
+		for _, r := range []Rnd{RNDN, RNDU, RNDD, RNDZ} {
+			if got, want := LogGamma(x, Rounding(RNDN, r)).Im(), New(64).Set(hi, Rounding(r, RNDN)).Re(); got.Cmp(want) != 0 {
+				t.Errorf("Im logΓ(%v) rounded %v = %v, want %v", x, r, got, want)
+			}
+		}
+	}
+}
+
+func TestDigamma(t *testing.T) {
+	// ψ(1) = -γ, correctly rounded through MPFR.
+	if got, want := Digamma(MustParse("1", 128)), Neg(EulerGamma(128)); !got.Equal(want) {
+		t.Errorf("ψ(1) = %v, want %v", got, want)
+	}
+	pi := Pi(200)
+	half := MustParse("0.5", 200)
+	for _, ys := range []string{"0.25", "1", "7.5"} {
+		y := MustParse(ys, 200)
+		// Im ψ(iy) = 1/(2y) + (π/2) coth(πy), Im ψ(1/2+iy) = (π/2) tanh(πy)
+		want := Add(Div(half, y), Mul(Mul(half, pi), Inv(Tanh(Mul(pi, y)))))
+		got := New(200).SetReals(Digamma(MulI(y, 1)).Im(), nil)
+		if !got.WithinULP(want, 16) {
+			t.Errorf("Im ψ(%si) = %v, want %v", ys, got, want)
+		}
+		want = Mul(Mul(half, pi), Tanh(Mul(pi, y)))
+		got = New(200).SetReals(Digamma(Add(half, MulI(y, 1))).Im(), nil)
+		if !got.WithinULP(want, 16) {
+			t.Errorf("Im ψ(1/2+%si) = %v, want %v", ys, got, want)
+		}
+	}
+	for _, s := range []string{"0.2+0.3i", "-5.5+0.1i", "-100.3-2i", "30-60i"} {
+		z := MustParse(s, 200)
+		// ψ(z+1) = ψ(z) + 1/z and ψ(1-z) - ψ(z) = π cot(πz)
+		if lhs, rhs := Digamma(Add(z, MustParse("1", 200))), Add(Digamma(z), Inv(z)); !lhs.WithinAbs(rhs, MustParseReal("1e-55", 64)) {
+			t.Errorf("z = %s: ψ(z+1) = %v, ψ(z) + 1/z = %v", s, lhs, rhs)
+		}
+		lhs := Sub(Digamma(Sub(MustParse("1", 200), z)), Digamma(z))
+		if rhs := Mul(pi, Inv(Tan(Mul(pi, z)))); !lhs.WithinAbs(rhs, MustParseReal("1e-55", 64)) {
+			t.Errorf("z = %s: reflection %v vs %v", s, lhs, rhs)
+		}
+	}
+}
+
+func TestPolygamma(t *testing.T) {
+	pi := Pi(200)
+	zeta3 := MustParse("1.2020569031595942853997381615114499907649862923404988817922715553", 200)
+	tests := []struct {
+		n    uint
+		z    string
+		want *Complex
+	}{
+		{1, "1", Div(Sqr(pi), MustParse("6", 200))},
+		{2, "1", Mul(zeta3, MustParse("-2", 200))},
+		{3, "0.5", PowUint64(pi, 4)},
+		{1, "0.5", Div(Sqr(pi), MustParse("2", 200))},
+	}
+	for _, tt := range tests {
+		got := Polygamma(tt.n, MustParse(tt.z, 200))
+		if !got.WithinULP(tt.want, 16) || !got.Im().IsZero() {
+			t.Errorf("ψ⁽%d⁾(%s) = %v, want %v", tt.n, tt.z, got, tt.want)
+		}
+	}
+	one := MustParse("1", 200)
+	for _, s := range []string{"0.3+0.4i", "-7.3+0.2i", "-2.5-3i", "15+1i", "0.5+20i"} {
+		z := MustParse(s, 200)
+		if got, want := Polygamma(0, z), Digamma(z); !got.Equal(want) {
+			t.Errorf("ψ⁽⁰⁾(%s) = %v, ψ = %v", s, got, want)
+		}
+		// ψ'(z) + ψ'(1-z) = π²/sin²(πz)
+		lhs := Add(Polygamma(1, z), Polygamma(1, Sub(one, z)))
+		if rhs := Div(Sqr(pi), Sqr(Sin(Mul(pi, z)))); !lhs.WithinAbs(rhs, MustParseReal("1e-50", 64)) {
+			t.Errorf("z = %s: ψ'(z) + ψ'(1-z) = %v, want %v", s, lhs, rhs)
+		}
+		// ψ⁽ⁿ⁾(z+1) = ψ⁽ⁿ⁾(z) + (-1)ⁿ n!/z^(n+1)
+		for _, n := range []uint{1, 2, 5, 12} {
+			lhs := Polygamma(n, Add(z, one))
+			fact := New(200).SetInt64(1, 0)
+			for k := uint(2); k <= n; k++ {
+				fact.Mul(fact, New(200).SetInt64(int64(k), 0))
+			}
+			if n%2 == 1 {
+				fact.Neg(fact)
+			}
+			rhs := Add(Polygamma(n, z), Mul(fact, PowInt64(z, -int64(n+1))))
+			tol := Mul(rhs, MustParse("1e-50", 200)).Abs()
+			if d := Sub(lhs, rhs).Abs(); d.Cmp(tol) > 0 {
+				t.Errorf("z = %s, n = %d: ψ⁽ⁿ⁾(z+1) = %v, want %v", s, n, lhs, rhs)
+			}
+		}
+	}
+	if got := Polygamma(2, MustParse("-3", 64)); !got.Re().IsInf() {
+		t.Errorf("ψ''(-3) = %v, want ∞", got)
+	}
+}
+
+func ExampleGamma() {
+	z := MustParse("1+i", 64)
+	fmt.Println(Gamma(z).StringFixed(15))
+	fmt.Println(LogGamma(MustParse("-2.5", 64)).StringFixed(15))
+	// Output:
+	// 0.498015668118356-0.154949828301811i
+	// -0.056243716497674-9.424777960769380i
+}
+
+// relClose reports whether |got - want| ≤ 2^(lost-prec) |want|: the parts may be of very
+// different sizes, so ulps of each part would be too strict for the smaller one.
+func relClose(got, want *Complex, lost int) bool {
+	d := Sub(got, want).Abs()
+	tol := want.Abs()
+	tol.Mul(tol, NewReal(64).SetFloat64(math.Ldexp(1, lost-int(want.Prec()))))
+	return d.Cmp(tol) <= 0
+}
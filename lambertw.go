@@ -0,0 +1,182 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// The Lambert W function, the inverse of w e^w, on all its branches W_k. Each value is
+// refined by Halley's iteration from an initial guess: the series in p = ±√(2(ez+1))
+// near the branch point -1/e, a Padé approximant of W₀ around the origin, and the
+// asymptotic expansion in L = log z + 2πik elsewhere. The branch cuts are those of
+// Corless et al., (-∞, -1/e] for W₀ and (-∞, 0] for the other branches, with the sign
+// of a zero imaginary part selecting the side; W₀(x) for x ≥ -1/e, and W₋₁(x + 0i) and
+// W₁(x - 0i) for -1/e ≤ x < 0 are real.
+//
+// The iteration runs gammaGuard bits above the result's precision, with more next to
+// the branch point, and stops once a step is down to a few ulps of w, after which the
+// cubic convergence leaves far less; the single final rounding then gives an error of
+// about an ulp. That is not proven, and W is not correctly rounded.
+
+// setLambertSpecial handles a = 0 and non-finite arguments: W₀(±0) = ±0, W_k(0) = -∞
+// for k ≠ 0, every branch gives +∞ + 0i at any infinity, and NaN gives NaN. It reports
+// whether c has been set.
+func (c *Complex) setLambertSpecial(k int, a *Complex) bool {
+	switch {
+	case C.mpfr_nan_p(a.re()) != 0 || C.mpfr_nan_p(a.im()) != 0:
+		C.mpc_set_nan(&c.z[0])
+	case C.mpfr_inf_p(a.re()) != 0 || C.mpfr_inf_p(a.im()) != 0:
+		C.mpfr_set_inf(c.re(), 1)
+		C.mpfr_set_zero(c.im(), 1)
+	case C.mpfr_zero_p(a.re()) != 0 && C.mpfr_zero_p(a.im()) != 0:
+		if k == 0 {
+			C.mpc_set(&c.z[0], &a.z[0], C.MPC_RNDNN)
+		} else {
+			C.mpfr_set_inf(c.re(), -1)
+			C.mpfr_set_zero(c.im(), 1)
+		}
+	default:
+		return false
+	}
+	c.inex = 0
+	return true
+}
+
+// lambertHalley refines w to the root of w e^w = z next to it by Halley's iteration
+//
+//	w ← w - f / (e^w (w+1) - (w+2) f / (2w+2)),  f = w e^w - z,
+//
+// at w's precision.
+func lambertHalley(w, z *Complex) {
+	wp := w.prec
+	e, f, t, u := New(wp), New(wp), New(wp), New(wp)
+	defer e.Close()
+	defer f.Close()
+	defer t.Close()
+	defer u.Close()
+	for i := 0; i < 100; i++ {
+		e.Exp(w, RNDNN)
+		f.Mul(w, e, RNDNN).Sub(f, z, RNDNN)
+		if f.IsZero() {
+			return
+		}
+		// t = w+1, e = e^w (w+1), u = (w+2) f / (2w+2)
+		C.mpc_add_ui(&t.z[0], &w.z[0], 1, C.MPC_RNDNN)
+		e.Mul(e, t, RNDNN)
+		C.mpc_add_ui(&u.z[0], &t.z[0], 1, C.MPC_RNDNN)
+		u.Mul(u, f, RNDNN).Div(u, t, RNDNN)
+		C.mpc_div_2si(&u.z[0], &u.z[0], 1, C.MPC_RNDNN)
+		e.Sub(e, u, RNDNN)
+		f.Div(f, e, RNDNN)
+		w.Sub(w, f, RNDNN)
+		if f.IsZero() || magExp(f) < magExp(w)-int(wp)+2 {
+			return
+		}
+	}
+}
+
+// LambertW sets c = W_k(a), the k-th branch of the Lambert W function, and returns c.
+// W₀ is the principal branch, real and increasing from W₀(-1/e) = -1 on [-1/e, ∞);
+// W₋₁ is the other branch real on [-1/e, 0), decreasing from -1 to -∞.
+func (c *Complex) LambertW(k int, a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if c.setLambertSpecial(k, a) {
+		return c
+	}
+	wp := max(c.prec, a.prec) + gammaGuard
+	// d = ez + 1 at twice the precision, as it cancels near the branch point.
+	d := New(2 * wp)
+	defer d.Close()
+	e := NewReal(2 * wp).SetConst(ConstE)
+	defer e.Close()
+	C.mpc_mul_fr(&d.z[0], &a.z[0], e.ptr(), C.MPC_RNDNN)
+	C.mpc_add_ui(&d.z[0], &d.z[0], 1, C.MPC_RNDNN)
+	below := C.mpfr_signbit(a.im()) != 0
+	v := a.Complex128()
+	var w *Complex
+	switch {
+	case cmplx.Abs(d.Complex128()) < 0.3*math.E && (k == 0 || k == -1 && !below || k == 1 && below):
+		// W ≈ -1 + p - p²/3 + 11p³/72, p = √(2(ez+1)) for W₀ and -√(2(ez+1)) for the
+		// branch W₋₁ above the cut or W₁ below it that meets W₀ at -1/e. Halley's
+		// iteration divides by about w+1 ≈ p, which costs the bits p lacks.
+		p := New(wp)
+		defer p.Close()
+		C.mpc_mul_2si(&p.z[0], &d.z[0], 1, C.MPC_RNDNN)
+		p.Sqrt(p, RNDNN)
+		if k != 0 {
+			p.Neg(p, RNDNN)
+		}
+		wq := wp
+		if x := magExp(p); x < 0 && x != math.MinInt {
+			wq += uint(-x)
+		}
+		w = New(wq).SetInt64(11, 0)
+		C.mpc_div_ui(&w.z[0], &w.z[0], 72, C.MPC_RNDNN)
+		w.Mul(w, p, RNDNN)
+		t := New(wq).SetInt64(1, 0)
+		defer t.Close()
+		C.mpc_div_ui(&t.z[0], &t.z[0], 3, C.MPC_RNDNN)
+		w.Sub(w, t, RNDNN).Mul(w, p, RNDNN)
+		C.mpc_add_ui(&w.z[0], &w.z[0], 1, C.MPC_RNDNN)
+		w.Mul(w, p, RNDNN)
+		C.mpc_sub_ui(&w.z[0], &w.z[0], 1, C.MPC_RNDNN)
+	case k == 0 && real(v) > -1 && real(v) < 1.5 && math.Abs(imag(v)) < 1 && real(v) > -2.5*math.Abs(imag(v))-0.2:
+		// W₀ ≈ z (3 + 6z + z²) / (3 + 9z + 5z²)
+		w = New(wp)
+		t := New(wp)
+		defer t.Close()
+		w.SetInt64(1, 0).Mul(w, a, RNDNN)
+		C.mpc_add_ui(&w.z[0], &w.z[0], 6, C.MPC_RNDNN)
+		w.Mul(w, a, RNDNN)
+		C.mpc_add_ui(&w.z[0], &w.z[0], 3, C.MPC_RNDNN)
+		w.Mul(w, a, RNDNN)
+		t.SetInt64(5, 0).Mul(t, a, RNDNN)
+		C.mpc_add_ui(&t.z[0], &t.z[0], 9, C.MPC_RNDNN)
+		t.Mul(t, a, RNDNN)
+		C.mpc_add_ui(&t.z[0], &t.z[0], 3, C.MPC_RNDNN)
+		w.Div(w, t, RNDNN)
+	default:
+		// W ≈ L - log L + log L / L, L = log z + 2πik
+		w = New(wp)
+		l, ll := New(wp), New(wp)
+		defer l.Close()
+		defer ll.Close()
+		l.Log(a, RNDNN)
+		if k != 0 {
+			pi := NewReal(wp).SetConst(ConstPi)
+			defer pi.Close()
+			C.mpfr_mul_si(pi.ptr(), pi.ptr(), C.long(2*k), C.MPFR_RNDN)
+			C.mpfr_add(l.im(), l.im(), pi.ptr(), C.MPFR_RNDN)
+		}
+		ll.Log(l, RNDNN)
+		w.Div(ll, l, RNDNN).Add(w, l, RNDNN).Sub(w, ll, RNDNN)
+	}
+	defer w.Close()
+	lambertHalley(w, a)
+	// Real on the real axis where the branch is: x ≥ -1/e, that is d ≥ 0, for W₀, and
+	// -1/e ≤ x < 0 above the cut for W₋₁ or below it for W₁.
+	if C.mpfr_zero_p(a.im()) != 0 && C.mpfr_sgn(d.re()) >= 0 &&
+		(k == 0 || (k == -1 && !below || k == 1 && below) && C.mpfr_sgn(a.re()) < 0) {
+		sign := C.int(1)
+		if below {
+			sign = -1
+		}
+		tr := C.mpfr_set(c.re(), w.re(), m.Re().mpfr())
+		C.mpfr_set_zero(c.im(), sign)
+		c.inex = inexOf(tr, 0)
+		return c
+	}
+	c.inex = C.mpc_set(&c.z[0], &w.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// Non-mutating helpers
+func LambertW(k int, a *Complex, mode ...RoundingMode) *Complex {
+	return newLike(a).LambertW(k, a, mode...)
+}
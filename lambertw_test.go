@@ -0,0 +1,132 @@
+package apcomplex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLambertWKnown(t *testing.T) {
+	tol := MustParseReal("1e-35", 64)
+	tests := []struct {
+		k    int
+		z    string
+		want string
+	}{
+		{0, "1", "0.567143290409783872999968662210355549753"},
+		{0, "-1", "-0.318131505204764135312654251587664517203+1.33723570143068940890116214319371061254i"},
+		{0, "-0.2", "-0.259171101819073745056651950215406705714"},
+		{-1, "-0.2", "-2.54264135777352642429380615666184829017"},
+		{-1, "-0.1", "-3.5771520639572972184093919635119948804"},
+	}
+	for _, tt := range tests {
+		got := LambertW(tt.k, MustParse(tt.z, 160))
+		if !got.WithinAbs(MustParse(tt.want, 160), tol) {
+			t.Errorf("W_%d(%s) = %v, want %s", tt.k, tt.z, got, tt.want)
+		}
+	}
+	// The fixed points of √2^z: W₀(-ln 2/2) = -ln 2 and W₋₁(-ln 2/2) = -2 ln 2.
+	ln2 := Log(MustParse("2", 200))
+	z := Conj(Neg(Mul2Si(ln2, -1))) // Neg leaves -0i
+	if got, want := LambertW(0, z), Neg(ln2); !relClose(got, want, 8) || !got.Im().IsZero() {
+		t.Errorf("W_0(-ln 2/2) = %v, want %v", got, want)
+	}
+	if got, want := LambertW(-1, z), Neg(Mul2Si(ln2, 1)); !relClose(got, want, 8) || !got.Im().IsZero() {
+		t.Errorf("W_-1(-ln 2/2) = %v, want %v", got, want)
+	}
+	// Below the cut W₁ takes the real values W₋₁ has above it.
+	if got, want := LambertW(1, Conj(z)), Conj(LambertW(-1, z)); !got.Equal(want) {
+		t.Errorf("W_1(-ln 2/2 - 0i) = %v, want %v", got, want)
+	}
+	if got := LambertW(1, z); got.Im().Sign() <= 0 {
+		t.Errorf("W_1(-ln 2/2 + 0i) = %v", got)
+	}
+}
+
+func TestLambertWSpecial(t *testing.T) {
+	zero := New(64).SetInt64(0, 0)
+	if got := LambertW(0, zero); !got.IsZero() {
+		t.Errorf("W_0(0) = %v", got)
+	}
+	if got := LambertW(2, zero); !got.Re().IsInf() || got.Re().Sign() > 0 {
+		t.Errorf("W_2(0) = %v, want -∞", got)
+	}
+	if got := LambertW(-1, MustParse("inf", 64)); !got.Re().IsInf() || got.Re().Sign() < 0 {
+		t.Errorf("W_-1(∞) = %v, want ∞", got)
+	}
+	if got := LambertW(0, MustParse("nan", 64)); !got.IsNaN() {
+		t.Errorf("W_0(NaN) = %v", got)
+	}
+}
+
+// parseSide parses s, where a trailing "-0i" puts a real point below the axis.
+func parseSide(s string, prec uint) *Complex {
+	if strings.HasSuffix(s, "-0i") {
+		return Conj(MustParse(strings.TrimSuffix(s, "-0i"), prec))
+	}
+	return MustParse(s, prec)
+}
+
+func TestLambertWIdentities(t *testing.T) {
+	// The points cover the branch-point series, the Padé guess, the asymptotic guess
+	// and both sides of the cuts.
+	points := []string{"0.5", "3+4i", "-0.3", "-0.3-0i", "-5", "-5-0i", "-2+1e-3i", "0.1-0.7i",
+		"1e-30+2e-30i", "1e40-1e40i", "-0.36+0.01i"}
+	ks := []int{-3, -1, 0, 1, 2, 5}
+	for _, prec := range []uint{64, 256} {
+		for _, s := range points {
+			z := parseSide(s, prec)
+			var last *Complex
+			for _, k := range ks {
+				w := LambertW(k, z)
+				if got := Mul(w, Exp(w)); !relClose(got, z, 16) {
+					t.Errorf("%d bits: W_%d(%s) = %v, w e^w = %v", prec, k, s, w, got)
+				}
+				// The branches are ordered by their imaginary parts; two neighbours are
+				// both real on the negative axis.
+				if last != nil && w.Im().Cmp(last.Im()) < 0 {
+					t.Errorf("%d bits: Im W_%d(%s) = %v is below the previous branch %v", prec, k, s, w, last)
+				}
+				last = w
+				if z.Im().IsZero() {
+					continue
+				}
+				if got, want := LambertW(-k, Conj(z)), Conj(w); !got.Equal(want) {
+					t.Errorf("%d bits: W_%d(conj %s) = %v, want %v", prec, -k, s, got, want)
+				}
+			}
+		}
+	}
+	// Next to the branch point W₀ and W₋₁ are about -1 ± √(2(ez+1)).
+	e := New(256).SetReals(NewReal(256).SetConst(ConstE), nil)
+	z := Add(Neg(Inv(e)), MustParse("1e-40", 256))
+	for _, k := range []int{0, -1} {
+		w := LambertW(k, z)
+		if got := Mul(w, Exp(w)); !relClose(got, z, 16) || !w.Im().IsZero() {
+			t.Errorf("W_%d(-1/e + 1e-40) = %v, w e^w = %v", k, w, got)
+		}
+		if d := Add(w, MustParse("1", 256)).Complex128(); real(d)*float64(2*k+1) < 2e-20 {
+			t.Errorf("W_%d(-1/e + 1e-40) = %v", k, w)
+		}
+	}
+	// The same values at different precisions.
+	for _, s := range points[:8] {
+		lo, hi := parseSide(s, 64), parseSide(s, 400)
+		for _, k := range ks {
+			want := New(64).Set(LambertW(k, hi))
+			if got := LambertW(k, lo); !relClose(got, want, 4) {
+				t.Errorf("W_%d(%s) = %v at 64 bits, %v at 400", k, s, got, want)
+			}
+		}
+	}
+}
+
+func ExampleLambertW() {
+	fmt.Println(LambertW(0, MustParse("1", 64)).StringFixed(15))
+	fmt.Println(LambertW(-1, MustParse("-0.1", 64)).StringFixed(15))
+	fmt.Println(LambertW(1, MustParse("-1", 64)).StringFixed(15))
+	// Output:
+	// 0.567143290409784+0.000000000000000i
+	// -3.577152063957297+0.000000000000000i
+	// -2.062277729598284+7.588631178472513i
+}
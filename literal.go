@@ -0,0 +1,596 @@
+package apcomplex
+
+/*
+#include <stdlib.h>
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// ParseOptions selects the syntax accepted by ParseWith and SetStringWith.
+type ParseOptions struct {
+	// Base is the base of the numbers, 2..62; 0 means 10. See ParseBase.
+	Base int
+
+	// Strict accepts only the forms this package prints: "a", "bi", "a+bi", "a-bi",
+	// "i", "-i", "(a b)", "(a,b)", "(a, b)" and polar "r∠θ", "r∠θ°" or "r·e^{iθ}", with
+	// at most one sign per number, i as the unit and no other whitespace. The default, lenient,
+	// syntax is described under Parse.
+	Strict bool
+}
+
+// ParseWith is like Parse with the syntax selected by o.
+func ParseWith(s string, prec uint, o ParseOptions) (*Complex, error) {
+	z := New(prec)
+	if err := z.SetStringWith(s, o); err != nil {
+		z.Close()
+		return nil, err
+	}
+	return z, nil
+}
+
+// SetStringWith sets c from a complex literal with the syntax selected by o, rounding
+// with c's mode. Polar literals are correctly rounded for the magnitude and angle
+// as read at 64 bits above c's precision.
+func (c *Complex) SetStringWith(s string, o ParseOptions) error {
+	if !c.init {
+		return errors.New("apcomplex: not initialized")
+	}
+	if o.Base != 0 && (o.Base < 2 || o.Base > 62) {
+		return fmt.Errorf("apcomplex: invalid base %d", o.Base)
+	}
+	lit, err := parseLiteral(s, o)
+	if err != nil {
+		return err
+	}
+	return c.setLiteral(s, lit, c.mode)
+}
+
+// literal is a parsed complex literal: its two parts, or a magnitude and an angle.
+type literal struct {
+	re, im  litNum // a missing part is zero
+	polar   bool   // re is the magnitude and im the angle
+	degrees bool
+}
+
+// litNum is a number as mpfr_strtofr reads it: the digits with the sign and without
+// a base prefix. pos is its offset in the input.
+type litNum struct {
+	text string
+	base int
+	pos  int
+}
+
+func (n litNum) signed(neg bool) litNum {
+	if neg {
+		n.text = "-" + n.text
+	}
+	return n
+}
+
+func (lit *literal) set(n litNum, imag bool) {
+	if imag {
+		lit.im = n
+	} else {
+		lit.re = n
+	}
+}
+
+// setLiteral stores lit (parsed from in) in c, rounding with m.
+func (c *Complex) setLiteral(in string, lit literal, m RoundingMode) error {
+	prec := c.prec
+	if lit.polar {
+		prec += 64
+	}
+	var r, i C.mpfr_t
+	C.mpfr_init2(&r[0], C.mpfr_prec_t(prec))
+	C.mpfr_init2(&i[0], C.mpfr_prec_t(prec))
+	defer C.mpfr_clear(&r[0])
+	defer C.mpfr_clear(&i[0])
+	tr, err := setLitNum(&r[0], in, lit.re, m.Re())
+	if err != nil {
+		return err
+	}
+	ti, err := setLitNum(&i[0], in, lit.im, m.Im())
+	if err != nil {
+		return err
+	}
+	if lit.polar {
+		c.setPolar(&r[0], &i[0], lit.degrees, m)
+		return nil
+	}
+	C.mpc_set_fr_fr(&c.z[0], &r[0], &i[0], m.mpc()) // exact: same precision
+	c.inex = inexOf(tr, ti)
+	return nil
+}
+
+func setLitNum(x *C.__mpfr_struct, in string, n litNum, rnd Rnd) (C.int, error) {
+	if n.text == "" {
+		C.mpfr_set_zero(x, 1)
+		return 0, nil
+	}
+	cs := C.CString(n.text)
+	defer C.free(unsafe.Pointer(cs))
+	var end *C.char
+	t := C.mpfr_strtofr(x, cs, &end, C.int(n.base), rnd.mpfr())
+	if *end != 0 {
+		return 0, &ParseError{Input: in, Offset: n.pos, Msg: "malformed number"}
+	}
+	return t, nil
+}
+
+// Literal parser. Offsets in errors are byte offsets into the input.
+
+type litParser struct {
+	in     string
+	pos    int
+	base   int
+	strict bool
+}
+
+func parseLiteral(in string, o ParseOptions) (literal, error) {
+	p := &litParser{in: in, base: o.Base, strict: o.Strict}
+	if p.base == 0 {
+		p.base = 10
+	}
+	var lit literal
+	if err := p.gap(); err != nil {
+		return lit, err
+	}
+	var err error
+	switch {
+	case p.pos == len(in):
+		if p.strict {
+			err = p.errorf(p.pos, "empty literal")
+		}
+		return lit, err
+	case p.hasPrefix("("):
+		err = p.pair(&lit)
+	case !p.strict && p.base == 10 && p.atExp():
+		err = p.exp(&lit, litNum{text: "1", base: 10, pos: p.pos})
+	default:
+		err = p.sum(&lit)
+	}
+	if err == nil {
+		err = p.gap()
+	}
+	if err == nil && p.pos < len(in) {
+		err = p.unexpected()
+	}
+	return lit, err
+}
+
+func (p *litParser) errorf(pos int, format string, args ...any) *ParseError {
+	return &ParseError{Input: p.in, Offset: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *litParser) unexpected() *ParseError {
+	r, _ := utf8.DecodeRuneInString(p.in[p.pos:])
+	return p.errorf(p.pos, "unexpected %q", r)
+}
+
+// space skips whitespace and reports whether there was any.
+func (p *litParser) space() bool {
+	start := p.pos
+	for p.pos < len(p.in) && strings.IndexByte(" \t\r\n", p.in[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// gap skips whitespace where the lenient syntax allows it.
+func (p *litParser) gap() error {
+	start := p.pos
+	if p.space() && p.strict {
+		return p.errorf(start, "unexpected space")
+	}
+	return nil
+}
+
+func (p *litParser) hasPrefix(s string) bool { return strings.HasPrefix(p.in[p.pos:], s) }
+
+func (p *litParser) hasWord(w string) bool {
+	end := p.pos + len(w)
+	return p.hasPrefix(w) && (end == len(p.in) || !isIdentByte(p.in[end], false))
+}
+
+func (p *litParser) accept(b byte) bool {
+	if p.pos < len(p.in) && p.in[p.pos] == b {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// unitAt reports whether an imaginary unit stands at i: i, or in the lenient syntax
+// I, j or J while they are not digits.
+func (p *litParser) unitAt(i int) bool {
+	if i >= len(p.in) || i+1 < len(p.in) && isIdentByte(p.in[i+1], false) {
+		return false
+	}
+	switch p.in[i] {
+	case 'i':
+		return true
+	case 'I', 'j', 'J':
+		return !p.strict && p.base <= 36
+	}
+	return false
+}
+
+// atEnd reports whether only whitespace follows i.
+func (p *litParser) atEnd(i int) bool {
+	return strings.TrimLeft(p.in[i:], " \t\r\n") == ""
+}
+
+// signs consumes a run of signs (one in the strict syntax) and reports whether they
+// negate.
+func (p *litParser) signs() (neg bool, err error) {
+	for n := 0; p.pos < len(p.in) && (p.in[p.pos] == '+' || p.in[p.pos] == '-'); n++ {
+		if n > 0 && p.strict {
+			return false, p.errorf(p.pos, "repeated sign")
+		}
+		neg = neg != (p.in[p.pos] == '-')
+		p.pos++
+		if err := p.gap(); err != nil {
+			return false, err
+		}
+	}
+	return neg, nil
+}
+
+// sum parses a real and/or an imaginary term, in either order in the lenient syntax,
+// or a polar literal.
+func (p *litParser) sum(lit *literal) error {
+	first, imag, err := p.term()
+	if err != nil {
+		return err
+	}
+	if !imag {
+		if ok, err := p.polar(lit, first); ok || err != nil {
+			return err
+		}
+	}
+	lit.set(first, imag)
+	save := p.pos
+	if !p.strict {
+		p.space()
+	}
+	if p.pos == len(p.in) || p.in[p.pos] != '+' && p.in[p.pos] != '-' {
+		p.pos = save
+		return nil
+	}
+	second, imag2, err := p.term()
+	if err != nil {
+		return err
+	}
+	switch {
+	case imag2 == imag && imag:
+		return p.errorf(second.pos, "second imaginary part")
+	case imag2 == imag:
+		return p.errorf(second.pos, "second real part")
+	case p.strict && !imag2:
+		return p.errorf(second.pos, "real part after imaginary part")
+	}
+	lit.set(second, imag2)
+	return nil
+}
+
+// term parses a signed number, which is imaginary if it has a unit: 2i, or in the
+// lenient syntax 2j, 2 i, 2*i and i*2. A unit alone stands for 1.
+func (p *litParser) term() (n litNum, imag bool, err error) {
+	neg, err := p.signs()
+	if err != nil {
+		return n, false, err
+	}
+	at := p.pos
+	start, end, base := p.scanNumber(at)
+	// Above base 18 i is also a digit; a final one is the unit.
+	if end > start && p.unitAt(end-1) && p.atEnd(end) {
+		end--
+	}
+	if end <= start {
+		if !p.unitAt(at) {
+			return n, false, p.errorf(at, "expected a number")
+		}
+		p.pos = at + 1
+		n = litNum{text: "1", base: 10, pos: at}
+		save := p.pos
+		if !p.strict {
+			p.space()
+			if p.accept('*') {
+				p.space()
+				n, err = p.number()
+				return n.signed(neg), true, err
+			}
+		}
+		p.pos = save
+		return n.signed(neg), true, nil
+	}
+	n = litNum{text: p.in[start:end], base: base, pos: at}
+	p.pos = end
+	save := p.pos
+	if !p.strict {
+		p.space()
+	}
+	if p.unitAt(p.pos) {
+		p.pos++
+		return n.signed(neg), true, nil
+	}
+	if !p.strict && p.accept('*') {
+		p.space()
+		if p.unitAt(p.pos) {
+			p.pos++
+			return n.signed(neg), true, nil
+		}
+	}
+	p.pos = save
+	return n.signed(neg), false, nil
+}
+
+// real parses a signed real number.
+func (p *litParser) real() (litNum, error) {
+	neg, err := p.signs()
+	if err != nil {
+		return litNum{}, err
+	}
+	n, err := p.number()
+	return n.signed(neg), err
+}
+
+// number parses an unsigned real number.
+func (p *litParser) number() (litNum, error) {
+	start, end, base := p.scanNumber(p.pos)
+	if end == p.pos {
+		return litNum{}, p.errorf(p.pos, "expected a number")
+	}
+	n := litNum{text: p.in[start:end], base: base, pos: p.pos}
+	p.pos = end
+	return n, nil
+}
+
+// pair parses the MPC form "(re im)" or "(re, im)"; the lenient syntax allows "(re)".
+func (p *litParser) pair(lit *literal) error {
+	p.pos++
+	if err := p.gap(); err != nil {
+		return err
+	}
+	re, err := p.real()
+	if err != nil {
+		return err
+	}
+	lit.re = re
+	sep := p.pos
+	p.space()
+	comma := p.accept(',')
+	p.space()
+	switch s := p.in[sep:p.pos]; {
+	case !p.strict && !comma && p.accept(')'):
+		return nil
+	case s == "" || p.strict && s != " " && s != "," && s != ", ":
+		return p.errorf(sep, "expected ' ' or ',' between the parts")
+	}
+	if lit.im, err = p.real(); err != nil {
+		return err
+	}
+	if err := p.gap(); err != nil {
+		return err
+	}
+	if !p.accept(')') {
+		if p.pos == len(p.in) {
+			return p.errorf(p.pos, "missing ')'")
+		}
+		return p.unexpected()
+	}
+	return nil
+}
+
+// polar parses the rest of "r∠θ", "r∠θ°" or "r·e^{iθ}" after the magnitude mag, and
+// reports whether the literal is polar. The lenient syntax also accepts * for · and
+// the exponentials listed under exp.
+func (p *litParser) polar(lit *literal, mag litNum) (bool, error) {
+	save := p.pos
+	if !p.strict {
+		p.space()
+	}
+	switch {
+	case p.hasPrefix("∠"):
+		p.pos += len("∠")
+		if err := p.gap(); err != nil {
+			return true, err
+		}
+		ang, err := p.real()
+		if err != nil {
+			return true, err
+		}
+		lit.re, lit.im, lit.polar = mag, ang, true
+		save := p.pos
+		if !p.strict {
+			p.space()
+		}
+		if p.hasPrefix("°") {
+			p.pos += len("°")
+			lit.degrees = true
+		} else {
+			p.pos = save
+		}
+		return true, nil
+	case p.hasPrefix("·"):
+		p.pos += len("·")
+		if err := p.gap(); err != nil {
+			return true, err
+		}
+		if !p.atExp() {
+			return true, p.errorf(p.pos, "expected e^")
+		}
+		return true, p.exp(lit, mag)
+	case !p.strict && p.accept('*'):
+		p.space()
+		if p.atExp() {
+			return true, p.exp(lit, mag)
+		}
+	}
+	p.pos = save
+	return false, nil
+}
+
+func (p *litParser) atExp() bool {
+	return p.hasPrefix("e^") || !p.strict && p.base == 10 && p.hasWord("exp")
+}
+
+// exp parses the exponential "e^{iθ}", or in the lenient syntax "e^(iθ)" or "exp(iθ)",
+// with the magnitude mag.
+func (p *litParser) exp(lit *literal, mag litNum) error {
+	closing := byte(')')
+	if p.hasPrefix("exp") {
+		p.pos += len("exp")
+		p.space()
+		if !p.accept('(') {
+			return p.errorf(p.pos, "expected '('")
+		}
+	} else {
+		p.pos += len("e^")
+		if err := p.gap(); err != nil {
+			return err
+		}
+		switch {
+		case p.accept('{'):
+			closing = '}'
+		case !p.strict && p.accept('('):
+		default:
+			return p.errorf(p.pos, "expected '{'")
+		}
+	}
+	if err := p.gap(); err != nil {
+		return err
+	}
+	ang, err := p.angle()
+	if err != nil {
+		return err
+	}
+	if err := p.gap(); err != nil {
+		return err
+	}
+	if !p.accept(closing) {
+		if p.pos == len(p.in) {
+			return p.errorf(p.pos, "missing %q", closing)
+		}
+		return p.unexpected()
+	}
+	lit.re, lit.im, lit.polar = mag, ang, true
+	return nil
+}
+
+// angle parses an imaginary exponent iθ: i directly followed by θ, as TextPolar writes
+// it, or any imaginary term (θi, or leniently i*θ, ...).
+func (p *litParser) angle() (litNum, error) {
+	save := p.pos
+	neg, err := p.signs()
+	if err != nil {
+		return litNum{}, err
+	}
+	if p.pos < len(p.in) && p.in[p.pos] == 'i' {
+		p.pos++
+		if _, end, _ := p.scanNumber(p.pos); end > p.pos {
+			n, err := p.number()
+			return n.signed(neg), err
+		}
+	}
+	p.pos = save
+	ang, imag, err := p.term()
+	if err == nil && !imag {
+		err = p.errorf(ang.pos, "exponent must be imaginary")
+	}
+	return ang, err
+}
+
+// scanNumber returns the extent of the unsigned number at i, without its base prefix,
+// and its base; end == i if there is none. Numbers are digits with an optional point
+// and exponent (see isExponentMark), inf, infinity and nan up to base 16 and @inf@
+// and @nan@ in any base, all case-insensitive. In base 10 the prefixes 0x, 0o and 0b
+// introduce a hexadecimal, octal or binary number; in those bases they are optional.
+func (p *litParser) scanNumber(i int) (start, end, base int) {
+	s := p.in
+	for _, w := range [...]string{"@inf@", "@nan@", "infinity", "inf", "nan"} {
+		if w[0] != '@' && p.base > 16 {
+			break
+		}
+		if len(s)-i >= len(w) && strings.EqualFold(s[i:i+len(w)], w) {
+			return i, i + len(w), p.base
+		}
+	}
+	start, base = i, p.base
+	if i+1 < len(s) && s[i] == '0' {
+		b := 0
+		switch s[i+1] {
+		case 'x', 'X':
+			b = 16
+		case 'o', 'O':
+			b = 8
+		case 'b', 'B':
+			b = 2
+		}
+		if (b == base || base == 10 && b != 0) && i+2 < len(s) && (digitValue(s[i+2], b) >= 0 ||
+			s[i+2] == '.' && i+3 < len(s) && digitValue(s[i+3], b) >= 0) {
+			start, base = i+2, b
+		}
+	}
+	j, digits := start, 0
+	for j < len(s) && digitValue(s[j], base) >= 0 {
+		j++
+		digits++
+	}
+	if j < len(s) && s[j] == '.' {
+		j++
+		for j < len(s) && digitValue(s[j], base) >= 0 {
+			j++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return i, i, p.base
+	}
+	if j < len(s) && isExponentMark(s[j], base) {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+		if k < len(s) && isDigit(s[k]) {
+			for k < len(s) && isDigit(s[k]) {
+				k++
+			}
+			j = k
+		}
+	}
+	return start, j, base
+}
+
+// digitValue returns the value of the digit b in base, or -1. As in MPFR, letters are
+// case-insensitive up to base 36; above it A-Z are 10..35 and a-z are 36..61.
+func digitValue(b byte, base int) int {
+	var d int
+	switch {
+	case '0' <= b && b <= '9':
+		d = int(b - '0')
+	case 'A' <= b && b <= 'Z':
+		d = int(b-'A') + 10
+	case 'a' <= b && b <= 'z':
+		d = int(b-'a') + 10
+		if base > 36 {
+			d += 26
+		}
+	default:
+		return -1
+	}
+	if d >= base {
+		return -1
+	}
+	return d
+}
@@ -0,0 +1,210 @@
+package apcomplex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	tests := []struct{ in, re, im string }{
+		{"", "0", "0"},
+		{"2+i", "2", "1"},
+		{"2-i", "2", "-1"},
+		{"-i", "0", "-1"},
+		{"3j", "0", "3"},
+		{"3J", "0", "3"},
+		{"2*i", "0", "2"},
+		{"i * 2.5", "0", "2.5"},
+		{"-i*2", "0", "-2"},
+		{"1 + 2 i", "1", "2"},
+		{"  1 - 2i  ", "1", "-2"},
+		{"1+-2i", "1", "-2"},
+		{"1 - -2i", "1", "2"},
+		{"--3", "3", "0"},
+		{"+-+3", "-3", "0"},
+		{"4i+3", "3", "4"},
+		{"0x1.8p3+0x1p-2i", "12", "0.25"},
+		{"0b101-0o17i", "5", "-15"},
+		{"-0X.8", "-0.5", "0"},
+		{"inf", "inf", "0"},
+		{"-Infinity+nani", "-inf", "nan"},
+		{"@inf@-@inf@i", "inf", "-inf"},
+		{"nan", "nan", "0"},
+		{"1e3-2.5E-1i", "1000", "-0.25"},
+		{"(1.5 -2)", "1.5", "-2"},
+		{"( 1.5 , -2 )", "1.5", "-2"},
+		{"(7)", "7", "0"},
+		{"5∠90°", "0", "5"},
+		{"2 ∠ -180 °", "-2", "0"},
+		{"4∠30°", "3.4641016151377545870548926830117447338856105076207612561116139", "2"},
+		{"1∠0", "1", "0"},
+		{"2*exp(0i)", "2", "0"},
+		{"exp(i*0)", "1", "0"},
+		{"3 * exp(-i)", "1.6209069176044191522028098223289298111969312618537666830103", "-2.5244129544236895199575069648908969988676891823951131970183"},
+	}
+	tol := MustParseReal("1e-55", 64)
+	for _, tt := range tests {
+		z, err := Parse(tt.in, 200)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		want := New(200)
+		if err := want.SetParts(tt.re, tt.im); err != nil {
+			t.Fatal(err)
+		}
+		if z.Cmp(want) != 0 && !z.WithinAbs(want, tol) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, z, want)
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	strict := ParseOptions{Strict: true}
+	for _, s := range []string{"1", "-2.5e-3", "3i", "i", "-i", "1.5-2i", "+1+2i", "inf-nani",
+		"(1 2)", "(1,2)", "(1, -2)", "0x1.8p+3", "2∠30°", "2∠-0.5"} {
+		if _, err := ParseWith(s, 64, strict); err != nil {
+			t.Errorf("strict %q: %v", s, err)
+		}
+	}
+	tests := []struct {
+		in     string
+		offset int
+	}{
+		{"", 0},
+		{" 1", 0},
+		{"1 ", 1},
+		{"1 + 2i", 1},
+		{"1+-2i", 2},
+		{"--1", 1},
+		{"3j", 1},
+		{"2*i", 1},
+		{"2i+1", 3},
+		{"(1)", 2},
+		{"(1  2)", 2},
+		{"2*exp(i)", 1},
+		{"exp(i)", 0},
+		{"2∠ 30", 4},
+	}
+	for _, tt := range tests {
+		_, err := ParseWith(tt.in, 64, strict)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Offset != tt.offset {
+			t.Errorf("strict %q: got %v, want a *ParseError at offset %d", tt.in, err, tt.offset)
+		}
+		if _, err := Parse(tt.in, 64); err != nil {
+			t.Errorf("lenient %q: %v", tt.in, err)
+		}
+	}
+}
+
+func TestParseErrorOffset(t *testing.T) {
+	tests := []struct {
+		in     string
+		offset int
+	}{
+		{"1x+2i", 1},
+		{"1+2i+3", 4},
+		{"1i+2i", 3},
+		{"1+2+3i", 2},
+		{"(1 2", 4},
+		{"(1 2i)", 4},
+		{"(1-2)", 2},
+		{"1+", 2},
+		{"abc", 0},
+		{"2*exp(3)", 6},
+		{"2*exp(3i", 8},
+		{"5∠", 4},
+		{"0x", 1},
+		{"1.2.3", 3},
+		{"1e", 1},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in, 64)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Offset != tt.offset {
+			t.Errorf("Parse(%q): got %v, want a *ParseError at offset %d", tt.in, err, tt.offset)
+		}
+	}
+}
+
+func TestParsePolarRounding(t *testing.T) {
+	// Both parts are correctly rounded in every direction: the bracket [down, up]
+	// is one ulp wide and contains the nearest value.
+	for _, s := range []string{"3∠1", "1e10∠-2.5", "7∠45°", "1∠1e30", "0.1∠100°"} {
+		z := New(24).SetMode(RNDDD)
+		if err := z.SetString(s); err != nil {
+			t.Fatal(err)
+		}
+		up := New(24).SetMode(RNDUU)
+		if err := up.SetString(s); err != nil {
+			t.Fatal(err)
+		}
+		near := New(24)
+		if err := near.SetString(s); err != nil {
+			t.Fatal(err)
+		}
+		if re, im := z.Acc(); re != Below || im != Below {
+			t.Errorf("%s rounded down: accuracy %v/%v", s, re, im)
+		}
+		dr, di := near.CmpParts(z)
+		ur, ui := near.CmpParts(up)
+		if !up.WithinULP(z, 1) || dr < 0 || di < 0 || ur > 0 || ui > 0 {
+			t.Errorf("%s: down %v, nearest %v, up %v", s, z, near, up)
+		}
+	}
+	z := MustParse("2∠120°", 64)
+	if re, _ := z.Acc(); re != Exact || z.Re().Text('g', -1) != "-1" {
+		t.Errorf("2∠120° = %v (%v), want an exact real part -1", z, re)
+	}
+}
+
+func TestParseBaseUnit(t *testing.T) {
+	// In base 36 i is a digit except at the end.
+	z, err := ParseBase("hi+1i", 36, 64)
+	if err != nil || z.Re().Text('g', -1) != "630" || z.Im().Text('g', -1) != "1" {
+		t.Errorf("ParseBase(hi+1i, 36) = %v, %v", z, err)
+	}
+	z, err = ParseBase("i", 36, 64)
+	if err != nil || !z.Equal(MustParse("i", 64)) {
+		t.Errorf("ParseBase(i, 36) = %v, %v", z, err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"", "1.5-2i", "(1 2)", "3j", "2*i", "5∠30°", "2*exp(i*0.5)", "2·e^{-i0.5}",
+		"0x1.8p3+0x1p-2i", "inf", "-nan+@inf@i", "1 + -2i", "(1,", "∠°", "0b", "1e+"} {
+		f.Add(s, false)
+		f.Add(s, true)
+	}
+	f.Fuzz(func(t *testing.T, s string, strict bool) {
+		z, err := ParseWith(s, 64, ParseOptions{Strict: strict})
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("%q: error %v is not a *ParseError", s, err)
+			}
+			if pe.Offset < 0 || pe.Offset > len(s) {
+				t.Fatalf("%q: offset %d out of range", s, pe.Offset)
+			}
+			return
+		}
+		defer z.Close()
+		if strict {
+			// Whatever the strict syntax accepts the lenient one reads the same way.
+			l, err := Parse(s, 64)
+			if err != nil || !sameParse(l, z) {
+				t.Fatalf("%q: strict %v, lenient %v (%v)", s, z, l, err)
+			}
+		}
+		// Every value prints in the strict syntax and reads back.
+		back, err := ParseWith(z.String(), 64, ParseOptions{Strict: true})
+		if err != nil || !sameParse(back, z) {
+			t.Fatalf("%q: %v does not read back: %v (%v)", s, z, back, err)
+		}
+	})
+}
+
+func sameParse(a, b *Complex) bool {
+	return a.Cmp(b) == 0 || a.IsNaN() && b.IsNaN()
+}
@@ -0,0 +1,323 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Encodings preserve the precision and the exact value of both parts:
+//
+//	text    "256:1.5-2i"                               (precision, shortest round-trip literal)
+//	JSON    {"re":"1.5","im":"-2","prec":256}
+//	binary  version, precision, rounding mode, then each part's sign, kind and mantissa
+//
+// Decoding into a zero Complex initializes it with the encoded precision (it is not
+// freed automatically, so Close it when done); an existing value adopts the encoded
+// precision. Decoding also accepts a bare literal ("1.5-2i") for text and a JSON string,
+// which keep the receiver's precision or use DefaultPrec.
+
+const binaryVersion = 1
+
+// Part kinds in the binary form; the sign is stored in bit 7.
+const (
+	partZero byte = iota
+	partFinite
+	partInf
+	partNaN
+	partSign byte = 0x80
+)
+
+type complexJSON struct {
+	Re   string `json:"re"`
+	Im   string `json:"im"`
+	Prec uint   `json:"prec"`
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c *Complex) MarshalText() ([]byte, error) {
+	if !c.init {
+		return nil, errors.New("apcomplex: not initialized")
+	}
+	return []byte(strconv.FormatUint(uint64(c.prec), 10) + ":" + c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Complex) UnmarshalText(text []byte) error {
+	s := string(text)
+	var prec uint
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		p, err := parsePrec(s[:i])
+		if err != nil {
+			return err
+		}
+		prec, s = p, s[i+1:]
+	}
+	if err := c.prepare(prec); err != nil {
+		return err
+	}
+	return c.SetString(s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *Complex) MarshalJSON() ([]byte, error) {
+	if !c.init {
+		return nil, errors.New("apcomplex: not initialized")
+	}
+	return json.Marshal(complexJSON{
+		Re:   mpfrText(c.re(), 'g', -1),
+		Im:   mpfrText(c.im(), 'g', -1),
+		Prec: c.prec,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. JSON null leaves c unchanged.
+func (c *Complex) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return c.UnmarshalText([]byte(s))
+	}
+	var v complexJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Prec == 0 {
+		return errors.New("apcomplex: JSON value without precision")
+	}
+	prec, err := checkPrec(uint64(v.Prec))
+	if err != nil {
+		return err
+	}
+	if err := c.prepare(prec); err != nil {
+		return err
+	}
+	return c.SetBase(v.Re, v.Im, 10)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with a compact exact encoding.
+func (c *Complex) MarshalBinary() ([]byte, error) {
+	if !c.init {
+		return nil, errors.New("apcomplex: not initialized")
+	}
+	buf := []byte{binaryVersion}
+	buf = binary.AppendUvarint(buf, uint64(c.prec))
+	buf = append(buf, byte(c.mode))
+	buf = appendPart(buf, c.re())
+	buf = appendPart(buf, c.im())
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (c *Complex) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryVersion {
+		return errors.New("apcomplex: unsupported binary encoding")
+	}
+	data = data[1:]
+	p, n := binary.Uvarint(data)
+	if n <= 0 || len(data) < n+1 {
+		return errors.New("apcomplex: truncated binary encoding")
+	}
+	prec, err := checkPrec(p)
+	if err != nil {
+		return err
+	}
+	mode := RoundingMode(data[n])
+	if mode.Re() > RNDA || mode.Im() > RNDA {
+		return fmt.Errorf("apcomplex: invalid rounding mode %#x", byte(mode))
+	}
+	data = data[n+1:]
+	if err := c.prepare(prec); err != nil {
+		return err
+	}
+	if data, err = readPart(data, c.re()); err != nil {
+		return err
+	}
+	if data, err = readPart(data, c.im()); err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return errors.New("apcomplex: trailing data in binary encoding")
+	}
+	c.mode = mode
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder using the binary form.
+func (c *Complex) GobEncode() ([]byte, error) { return c.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (c *Complex) GobDecode(data []byte) error { return c.UnmarshalBinary(data) }
+
+// prepare readies c to receive a decoded value of precision prec (0 keeps c's own).
+func (c *Complex) prepare(prec uint) error {
+	c.lazyInit(prec)
+	if !c.init {
+		return errors.New("apcomplex: not initialized")
+	}
+	if prec != 0 && prec != c.prec {
+		c.SetPrec(prec)
+	}
+	c.inex = 0
+	return nil
+}
+
+func parsePrec(s string) (uint, error) {
+	p, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("apcomplex: invalid precision %q", s)
+	}
+	return checkPrec(p)
+}
+
+func checkPrec(p uint64) (uint, error) {
+	if p < C.MPFR_PREC_MIN || p > uint64(C.mpfr_prec_t(C.MPFR_PREC_MAX)) {
+		return 0, fmt.Errorf("apcomplex: precision %d out of range", p)
+	}
+	return uint(p), nil
+}
+
+// appendPart appends one MPFR value: a kind byte, and for finite values the exponent
+// and the mantissa with trailing zero bits dropped.
+func appendPart(buf []byte, x *C.__mpfr_struct) []byte {
+	var kind byte
+	switch {
+	case C.mpfr_nan_p(x) != 0:
+		return append(buf, partNaN)
+	case C.mpfr_inf_p(x) != 0:
+		kind = partInf
+	case C.mpfr_zero_p(x) != 0:
+		kind = partZero
+	default:
+		kind = partFinite
+	}
+	if C.mpfr_signbit(x) != 0 {
+		kind |= partSign
+	}
+	buf = append(buf, kind)
+	if kind&^partSign != partFinite {
+		return buf
+	}
+	mant, exp := mpfrMantExp(x)
+	tz := mant.TrailingZeroBits()
+	mant.Rsh(mant.Abs(mant), tz)
+	b := mant.Bytes()
+	buf = binary.AppendVarint(buf, exp+int64(tz))
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// readPart decodes one value written by appendPart into x and returns the rest of data.
+func readPart(data []byte, x *C.__mpfr_struct) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("apcomplex: truncated binary encoding")
+	}
+	kind, data := data[0], data[1:]
+	sign := C.int(1)
+	if kind&partSign != 0 {
+		sign = -1
+	}
+	switch kind &^ partSign {
+	case partNaN:
+		C.mpfr_set_nan(x)
+		return data, nil
+	case partInf:
+		C.mpfr_set_inf(x, sign)
+		return data, nil
+	case partZero:
+		C.mpfr_set_zero(x, sign)
+		return data, nil
+	case partFinite:
+	default:
+		return nil, fmt.Errorf("apcomplex: invalid part kind %#x", kind)
+	}
+	exp, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, errors.New("apcomplex: truncated binary encoding")
+	}
+	data = data[n:]
+	l, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < l {
+		return nil, errors.New("apcomplex: truncated binary encoding")
+	}
+	data = data[n:]
+	mant := new(big.Int).SetBytes(data[:l])
+	if mant.Sign() == 0 || uint(mant.BitLen()) > uint(C.mpfr_get_prec(x)) {
+		return nil, errors.New("apcomplex: invalid mantissa in binary encoding")
+	}
+	if sign < 0 {
+		mant.Neg(mant)
+	}
+	setMpfrMantExp(x, mant, exp, RNDN) // exact: the mantissa fits the precision
+	return data[l:], nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s *Safe) MarshalText() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Safe) UnmarshalText(text []byte) error {
+	return s.decode(func(c *Complex) error { return c.UnmarshalText(text) })
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *Safe) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Safe) UnmarshalJSON(data []byte) error {
+	return s.decode(func(c *Complex) error { return c.UnmarshalJSON(data) })
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (s *Safe) MarshalBinary() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.c.MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *Safe) UnmarshalBinary(data []byte) error {
+	return s.decode(func(c *Complex) error { return c.UnmarshalBinary(data) })
+}
+
+// GobEncode implements gob.GobEncoder.
+func (s *Safe) GobEncode() ([]byte, error) { return s.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder.
+func (s *Safe) GobDecode(data []byte) error { return s.UnmarshalBinary(data) }
+
+// decode runs f under the write lock, allocating the wrapped value of a zero Safe.
+// The value comes from New, so its finalizer frees it like any other Safe's.
+func (s *Safe) decode(f func(c *Complex) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.c == nil {
+		s.c = New(0)
+	}
+	return f(s.c)
+}
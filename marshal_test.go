@@ -0,0 +1,141 @@
+package apcomplex
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+// marshalSamples covers exact and inexact parts, signed zeros and non-finite values
+// at several precisions.
+func marshalSamples() []*Complex {
+	third := Div(MustParse("1-2i", 300), MustParse("3", 300))
+	return []*Complex{
+		MustParse("1.5-2i", 64),
+		third,
+		MustParse("-0+0i", 53),
+		MustParse("1e-300000-7e300000i", 2000),
+		MustParse("inf-infi", 17),
+		New(24), // NaN
+		MustParse("3", 1).SetMode(Rounding(RNDU, RNDZ)),
+	}
+}
+
+// sameValue reports bit-identical values, including precision and NaN and zero signs.
+func sameValue(a, b *Complex) bool {
+	return a.Prec() == b.Prec() && a.Cmp(b) == 0
+}
+
+func TestTextJSONRoundTrip(t *testing.T) {
+	for _, z := range marshalSamples() {
+		text, err := z.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Complex
+		if err := back.UnmarshalText(text); err != nil || !sameValue(&back, z) {
+			t.Errorf("text %q: round trip failed (%v)", text, err)
+		}
+		back.Close()
+
+		data, err := json.Marshal(z)
+		if err != nil {
+			t.Fatal(err)
+		}
+		into := New(8) // adopts the encoded precision
+		if err := json.Unmarshal(data, into); err != nil || !sameValue(into, z) {
+			t.Errorf("json %s: round trip failed (%v)", data, err)
+		}
+	}
+	data, _ := json.Marshal(MustParse("1.5-2i", 256))
+	if string(data) != `{"re":"1.5","im":"-2","prec":256}` {
+		t.Errorf("JSON form = %s", data)
+	}
+}
+
+func TestJSONInStruct(t *testing.T) {
+	type payload struct {
+		Z *Complex `json:"z"`
+		S *Safe    `json:"s"`
+		N *Complex `json:"n"`
+	}
+	in := payload{Z: MustParse("0.1+0.2i", 200), S: MustParseSafe("-3i", 96)}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out payload
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !sameValue(out.Z, in.Z) || !sameValue(out.S.Unsafe(), in.S.Unsafe()) || out.N != nil {
+		t.Fatalf("struct round trip: %s -> %v %v %v", data, out.Z, out.S, out.N)
+	}
+	// A plain string keeps the receiver's precision.
+	z := New(80)
+	if err := json.Unmarshal([]byte(`"2.5+1i"`), z); err != nil || z.Prec() != 80 || !z.Equal(MustParse("2.5+1i", 80)) {
+		t.Fatalf("string form: %v %v", z, err)
+	}
+	if err := json.Unmarshal([]byte(`{"re":"1","im":"2"}`), z); err == nil {
+		t.Fatalf("expected an error for a missing precision")
+	}
+	if err := json.Unmarshal([]byte(`{"re":"1","im":"2","prec":18446744073709551615}`), z); err == nil {
+		t.Fatalf("expected an error for an out-of-range precision")
+	}
+}
+
+func TestBinaryGobRoundTrip(t *testing.T) {
+	for _, z := range marshalSamples() {
+		data, err := z.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Complex
+		if err := back.UnmarshalBinary(data); err != nil || !sameValue(&back, z) || back.Mode() != z.Mode() {
+			t.Errorf("binary %x: round trip of %v failed (%v)", data, z, err)
+		}
+		back.Close()
+		for i := range data {
+			if (&Complex{}).UnmarshalBinary(data[:i]) == nil {
+				t.Errorf("%v: truncated encoding of %d bytes accepted", z, i)
+			}
+		}
+	}
+	if data, _ := MustParse("1.5-2i", 256).MarshalBinary(); len(data) > 12 {
+		t.Errorf("binary form of 1.5-2i is %d bytes, want a compact encoding", len(data))
+	}
+
+	var buf bytes.Buffer
+	in := []*Safe{MustParseSafe("1e100+1e-100i", 512), WrapSafe(marshalSamples()[1])}
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out []*Safe
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	for i := range in {
+		if !sameValue(out[i].Unsafe(), in[i].Unsafe()) {
+			t.Errorf("gob %d: got %v, want %v", i, out[i], in[i])
+		}
+	}
+}
+
+func TestDecodeZeroSafe(t *testing.T) {
+	want := MustParse("0.25-7i", 150)
+	text, _ := want.MarshalText()
+	data, _ := want.MarshalBinary()
+	js, _ := json.Marshal(want)
+	decoders := map[string]func(s *Safe) error{
+		"text":   func(s *Safe) error { return s.UnmarshalText(text) },
+		"binary": func(s *Safe) error { return s.UnmarshalBinary(data) },
+		"json":   func(s *Safe) error { return json.Unmarshal(js, s) },
+	}
+	for name, decode := range decoders {
+		var s Safe
+		if err := decode(&s); err != nil || !sameValue(s.Unsafe(), want) {
+			t.Errorf("%s into a zero Safe: got %v (%v), want %v", name, s.Unsafe(), err, want)
+		}
+	}
+}
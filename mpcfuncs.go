@@ -0,0 +1,384 @@
+package apcomplex
+
+/*
+#include <stdlib.h>
+#include <gmp.h>
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_in_fund reports whether z lies in the closed fundamental domain of the modular
+// group: |Re z| <= 1/2 and |z| >= 1.
+static int apc_in_fund(mpc_srcptr z, mpfr_ptr t) {
+    mpfr_srcptr re = mpc_realref(z);
+    if (mpfr_cmp_d(re, 0.5) > 0 || mpfr_cmp_d(re, -0.5) < 0) return 0;
+    mpc_norm(t, z, MPFR_RNDN);
+    return mpfr_cmp_ui(t, 1) >= 0;
+}
+
+// apc_eta sets rop to the Dedekind eta function of op in the upper half-plane. Points
+// of the fundamental domain go straight to mpc_eta_fund (correctly rounded); others
+// are first reduced with eta(z+n) = e^{i pi n/12} eta(z) and eta(-1/z) = sqrt(-iz) eta(z)
+// at 64 guard bits. Outside the upper half-plane the result is NaN.
+static int apc_eta(mpc_ptr rop, mpc_srcptr op, mpc_rnd_t rnd) {
+    mpfr_srcptr re = mpc_realref(op), im = mpc_imagref(op);
+    if (mpfr_nan_p(re) || mpfr_inf_p(re) || mpfr_nan_p(im) || mpfr_sgn(im) <= 0) {
+        mpc_set_nan(rop);
+        return 0;
+    }
+    mpfr_prec_t p = mpfr_get_prec(mpc_realref(rop));
+    if (mpfr_get_prec(mpc_imagref(rop)) > p) p = mpfr_get_prec(mpc_imagref(rop));
+    p += 64;
+    mpfr_t n;
+    mpfr_init2(n, p);
+    if (mpfr_inf_p(im) || apc_in_fund(op, n)) {
+        mpfr_clear(n);
+        return mpc_eta_fund(rop, op, rnd);
+    }
+    mpc_t t, f, w;
+    mpc_init2(t, p); mpc_init2(f, p); mpc_init2(w, p);
+    mpc_set(t, op, MPC_RNDNN);
+    mpc_set_ui(f, 1, MPC_RNDNN);
+    for (;;) {
+        // t - round(t) is exact, so is n mod 24.
+        mpfr_rint(n, mpc_realref(t), MPFR_RNDN);
+        if (!mpfr_zero_p(n)) {
+            mpfr_sub(mpc_realref(t), mpc_realref(t), n, MPFR_RNDN);
+            mpfr_fmod_ui(n, n, 24, MPFR_RNDN);
+            long k = mpfr_get_si(n, MPFR_RNDN);
+            mpc_rootofunity(w, 24, (unsigned long)(k < 0 ? k + 24 : k), MPC_RNDNN);
+            mpc_mul(f, f, w, MPC_RNDNN);
+        }
+        if (apc_in_fund(t, n)) break;
+        mpc_mul_i(w, t, -1, MPC_RNDNN);
+        mpc_sqrt(w, w, MPC_RNDNN);
+        mpc_div(f, f, w, MPC_RNDNN);
+        mpc_ui_div(t, 1, t, MPC_RNDNN);
+        mpc_neg(t, t, MPC_RNDNN);
+    }
+    mpc_eta_fund(w, t, MPC_RNDNN);
+    int inex = mpc_mul(rop, f, w, rnd);
+    mpc_clear(t); mpc_clear(f); mpc_clear(w);
+    mpfr_clear(n);
+    return inex;
+}
+*/
+import "C"
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// Sqr sets c = a² and returns c. It is faster than Mul(a, a).
+func (c *Complex) Sqr(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_sqr(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+
+// Fma sets c = a·b + d with a single rounding and returns c.
+func (c *Complex) Fma(a, b, d *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_fma(&c.z[0], &a.z[0], &b.z[0], &d.z[0], c.rnd(mode))
+	return c
+}
+
+// MulI sets c = a·i if sign >= 0 and c = -a·i otherwise, and returns c.
+func (c *Complex) MulI(a *Complex, sign int, mode ...RoundingMode) *Complex {
+	s := C.int(1)
+	if sign < 0 {
+		s = -1
+	}
+	c.inex = C.mpc_mul_i(&c.z[0], &a.z[0], s, c.rnd(mode))
+	return c
+}
+
+// Mul2Si sets c = a·2ⁿ and returns c.
+func (c *Complex) Mul2Si(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_mul_2si(&c.z[0], &a.z[0], C.long(n), c.rnd(mode))
+	return c
+}
+
+// Div2Si sets c = a/2ⁿ and returns c.
+func (c *Complex) Div2Si(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_div_2si(&c.z[0], &a.z[0], C.long(n), c.rnd(mode))
+	return c
+}
+
+// Log10 sets c to the principal base-10 logarithm of a and returns c.
+func (c *Complex) Log10(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_log10(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+
+// SinCos sets s = sin a and, unless cos is nil, cos = cos a, sharing the work between
+// the two, and returns s. Each result is rounded with its own mode unless mode is given.
+// s and cos must be distinct; either may alias a.
+func (s *Complex) SinCos(cos, a *Complex, mode ...RoundingMode) *Complex {
+	if cos == s {
+		panic("apcomplex: SinCos needs two distinct results")
+	}
+	if cos == nil {
+		return s.Sin(a, mode...)
+	}
+	rs, rc := s.rnd(mode), cos.rnd(mode)
+	inex := C.mpc_sin_cos(&s.z[0], &cos.z[0], &a.z[0], rs, rc)
+	s.inex, cos.inex = inex&15, inex>>4
+	return s
+}
+
+// PowUint64 sets c = aⁿ and returns c.
+func (c *Complex) PowUint64(a *Complex, n uint64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_pow_ui(&c.z[0], &a.z[0], C.ulong(n), c.rnd(mode))
+	return c
+}
+
+// PowInt64 sets c = aⁿ and returns c.
+func (c *Complex) PowInt64(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_pow_si(&c.z[0], &a.z[0], C.long(n), c.rnd(mode))
+	return c
+}
+
+// PowBigInt sets c = aⁿ for an integer n of any size and returns c.
+func (c *Complex) PowBigInt(a *Complex, n *big.Int, mode ...RoundingMode) *Complex {
+	var z C.mpz_t
+	C.mpz_init(&z[0])
+	defer C.mpz_clear(&z[0])
+	setMpz(&z[0], n)
+	c.inex = C.mpc_pow_z(&c.z[0], &a.z[0], &z[0], c.rnd(mode))
+	return c
+}
+
+// PowReal sets c = a^x (principal branch) and returns c.
+func (c *Complex) PowReal(a *Complex, x *Real, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_pow_fr(&c.z[0], &a.z[0], x.ptr(), c.rnd(mode))
+	return c
+}
+
+// PowFloat64 sets c = a^x (principal branch) and returns c.
+func (c *Complex) PowFloat64(a *Complex, x float64, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_pow_d(&c.z[0], &a.z[0], C.double(x), c.rnd(mode))
+	return c
+}
+
+// SetRootOfUnity sets c = e^{2πik/n}, the k-th n-th root of unity, and returns c.
+// It panics if n is 0.
+func (c *Complex) SetRootOfUnity(n, k uint64, mode ...RoundingMode) *Complex {
+	if n == 0 {
+		panic("apcomplex: root of unity of order 0")
+	}
+	c.inex = C.mpc_rootofunity(&c.z[0], C.ulong(n), C.ulong(k), c.rnd(mode))
+	return c
+}
+
+// Proj sets c to the projection of a onto the Riemann sphere and returns c: a itself
+// unless a part is infinite, in which case c is +∞ plus a zero imaginary part carrying
+// the sign of a's.
+func (c *Complex) Proj(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_proj(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+
+// Agm sets c to the arithmetic-geometric mean of a and b (the optimal AGM of MPC, with
+// the principal square root at each step) and returns c.
+func (c *Complex) Agm(a, b *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.mpc_agm(&c.z[0], &a.z[0], &b.z[0], c.rnd(mode))
+	return c
+}
+
+// Eta sets c to the Dedekind eta function η(a) = e^{πia/12} ∏(1 - e^{2πina}) and
+// returns c. In the fundamental domain (|Re a| ≤ 1/2, |a| ≥ 1) the result is correctly
+// rounded; elsewhere in the upper half-plane a is first mapped there by modular
+// transformations carried out with 64 guard bits. Below the real axis c is NaN.
+func (c *Complex) Eta(a *Complex, mode ...RoundingMode) *Complex {
+	c.inex = C.apc_eta(&c.z[0], &a.z[0], c.rnd(mode))
+	return c
+}
+
+// Sum sets c to the sum of xs with a single rounding and returns c. An empty sum is 0.
+func (c *Complex) Sum(xs []*Complex, mode ...RoundingMode) *Complex {
+	var pin runtime.Pinner
+	defer pin.Unpin()
+	ptrs := mpcPtrs(&pin, xs)
+	c.viaTemp(xs, mode, func(z *Complex, rnd C.mpc_rnd_t) C.int {
+		return C.mpc_sum(&z.z[0], ptrs, C.ulong(len(xs)), rnd)
+	})
+	return c
+}
+
+// Dot sets c = Σ x[k]·y[k] with a single rounding and returns c. It panics if x and y
+// differ in length.
+func (c *Complex) Dot(x, y []*Complex, mode ...RoundingMode) *Complex {
+	if len(x) != len(y) {
+		panic("apcomplex: Dot of vectors of different lengths")
+	}
+	var pin runtime.Pinner
+	defer pin.Unpin()
+	px, py := mpcPtrs(&pin, x), mpcPtrs(&pin, y)
+	c.viaTemp(append(x[:len(x):len(x)], y...), mode, func(z *Complex, rnd C.mpc_rnd_t) C.int {
+		return C.mpc_dot(&z.z[0], px, py, C.ulong(len(x)), rnd)
+	})
+	return c
+}
+
+// mpcPtrs returns the C array of MPC pointers mpc_sum and mpc_dot take. The values are
+// pinned so that the array (Go memory) may hold them while C reads it.
+func mpcPtrs(pin *runtime.Pinner, xs []*Complex) *C.mpc_ptr {
+	if len(xs) == 0 {
+		return nil
+	}
+	ptrs := make([]C.mpc_ptr, len(xs))
+	for i, x := range xs {
+		pin.Pin(x)
+		ptrs[i] = &x.z[0]
+	}
+	return &ptrs[0]
+}
+
+// viaTemp runs f storing into c, or into a temporary of c's precision when c is one of
+// the operands, since the vector functions of MPC do not allow the result to alias them.
+func (c *Complex) viaTemp(ops []*Complex, mode []RoundingMode, f func(z *Complex, rnd C.mpc_rnd_t) C.int) {
+	for _, x := range ops {
+		if x == c {
+			t := New(c.prec)
+			defer t.Close()
+			c.inex = f(t, c.rnd(mode))
+			C.mpc_swap(&c.z[0], &t.z[0])
+			return
+		}
+	}
+	c.inex = f(c, c.rnd(mode))
+}
+
+// Swap exchanges the values of c and d, including their precisions, in constant time.
+// Each keeps its own rounding mode.
+func (c *Complex) Swap(d *Complex) {
+	C.mpc_swap(&c.z[0], &d.z[0])
+	c.prec, d.prec = d.prec, c.prec
+	c.inex, d.inex = d.inex, c.inex
+}
+
+// RandState is a seeded source of random bits for Urandom (a GMP random state). It is
+// safe for concurrent use.
+type RandState struct {
+	mu   sync.Mutex
+	s    C.gmp_randstate_t
+	init bool
+	once sync.Once
+}
+
+// NewRandState returns a random state (GMP's default generator, the Mersenne Twister)
+// seeded with seed. The same seed yields the same sequence at the same precisions.
+func NewRandState(seed uint64) *RandState {
+	rs := new(RandState)
+	C.gmp_randinit_default(&rs.s[0])
+	C.gmp_randseed_ui(&rs.s[0], C.ulong(seed))
+	rs.init = true
+	runtime.SetFinalizer(rs, func(rs *RandState) { rs.Close() })
+	return rs
+}
+
+// Close frees C resources.
+func (rs *RandState) Close() {
+	if rs == nil {
+		return
+	}
+	rs.once.Do(func() {
+		if rs.init {
+			C.gmp_randclear(&rs.s[0])
+			rs.init = false
+		}
+	})
+}
+
+// Urandom sets c to a value with both parts drawn uniformly from [0, 1) at c's precision
+// and returns c. The result is exact.
+func (c *Complex) Urandom(rs *RandState) *Complex {
+	rs.mu.Lock()
+	C.mpc_urandom(&c.z[0], &rs.s[0])
+	rs.mu.Unlock()
+	c.inex = 0
+	return c
+}
+
+// Non-mutating forms. As above, the result takes the precision and rounding mode of the
+// first operand, or the highest precision of several.
+func Sqr(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Sqr(a, mode...) }
+func Log10(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).Log10(a, mode...) }
+func Proj(a *Complex, mode ...RoundingMode) *Complex  { return newLike(a).Proj(a, mode...) }
+func Eta(a *Complex, mode ...RoundingMode) *Complex   { return newLike(a).Eta(a, mode...) }
+func MulI(a *Complex, sign int, mode ...RoundingMode) *Complex {
+	return newLike(a).MulI(a, sign, mode...)
+}
+func Mul2Si(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	return newLike(a).Mul2Si(a, n, mode...)
+}
+func Div2Si(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	return newLike(a).Div2Si(a, n, mode...)
+}
+func PowUint64(a *Complex, n uint64, mode ...RoundingMode) *Complex {
+	return newLike(a).PowUint64(a, n, mode...)
+}
+func PowInt64(a *Complex, n int64, mode ...RoundingMode) *Complex {
+	return newLike(a).PowInt64(a, n, mode...)
+}
+func PowBigInt(a *Complex, n *big.Int, mode ...RoundingMode) *Complex {
+	return newLike(a).PowBigInt(a, n, mode...)
+}
+func PowFloat64(a *Complex, x float64, mode ...RoundingMode) *Complex {
+	return newLike(a).PowFloat64(a, x, mode...)
+}
+func PowReal(a *Complex, x *Real, mode ...RoundingMode) *Complex {
+	return New(max(a.prec, x.prec)).SetMode(a.mode).PowReal(a, x, mode...)
+}
+func Fma(a, b, d *Complex, mode ...RoundingMode) *Complex {
+	return New(maxPrec(a, b, d)).SetMode(a.mode).Fma(a, b, d, mode...)
+}
+func Agm(a, b *Complex, mode ...RoundingMode) *Complex {
+	return New(maxPrec(a, b)).SetMode(a.mode).Agm(a, b, mode...)
+}
+
+// SinCos returns sin a and cos a, computed together.
+func SinCos(a *Complex, mode ...RoundingMode) (sin, cos *Complex) {
+	sin, cos = newLike(a), newLike(a)
+	sin.SinCos(cos, a, mode...)
+	return sin, cos
+}
+
+// Sum returns the correctly rounded sum of xs at their highest precision, with the
+// rounding mode of xs[0]. An empty sum is 0 at DefaultPrec.
+func Sum(xs []*Complex, mode ...RoundingMode) *Complex {
+	return newLikeAll(xs).Sum(xs, mode...)
+}
+
+// Dot returns the correctly rounded dot product Σ x[k]·y[k] at the highest precision
+// of the operands, with the rounding mode of x[0].
+func Dot(x, y []*Complex, mode ...RoundingMode) *Complex {
+	z := newLikeAll(append(x[:len(x):len(x)], y...))
+	return z.Dot(x, y, mode...)
+}
+
+// RootOfUnity returns e^{2πik/n} at the given precision (0 means DefaultPrec), rounded
+// to nearest.
+func RootOfUnity(n, k uint64, prec uint) *Complex { return New(prec).SetRootOfUnity(n, k) }
+
+// Urandom returns a random value with both parts uniform in [0, 1) at the given
+// precision.
+func Urandom(rs *RandState, prec uint) *Complex { return New(prec).Urandom(rs) }
+
+// maxPrec returns the highest precision of xs.
+func maxPrec(xs ...*Complex) uint {
+	var p uint
+	for _, x := range xs {
+		p = max(p, x.prec)
+	}
+	return p
+}
+
+// newLikeAll allocates a result at the highest precision of xs with the rounding mode
+// of the first; DefaultPrec and the default mode when xs is empty.
+func newLikeAll(xs []*Complex) *Complex {
+	if len(xs) == 0 {
+		return New(0)
+	}
+	return New(maxPrec(xs...)).SetMode(xs[0].mode)
+}
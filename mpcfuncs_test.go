@@ -0,0 +1,256 @@
+package apcomplex
+
+import (
+	"math"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestSqrFmaMulI(t *testing.T) {
+	a, b, d := MustParse("1.1+2.3i", 64), MustParse("-0.7+1e-3i", 64), MustParse("3.3-1.9i", 64)
+	if got, want := Sqr(a), Mul(a, a); !got.Equal(want) {
+		t.Errorf("Sqr = %v, Mul = %v", got, want)
+	}
+	// The exact product fits in 400 bits, so one rounding of product+d is the fma.
+	exact := New(400).Mul(a, b)
+	if got, want := Fma(a, b, d), New(64).Add(exact, d); !got.Equal(want) || got.Prec() != 64 {
+		t.Errorf("Fma = %v, want %v", got, want)
+	}
+	if got := MulI(a, 1); !got.Equal(Mul(a, I(64))) || !got.IsExact() {
+		t.Errorf("MulI(a, 1) = %v", got)
+	}
+	if got := MulI(a, -1); !got.Equal(Neg(Mul(a, I(64)))) {
+		t.Errorf("MulI(a, -1) = %v", got)
+	}
+	if got := Mul2Si(a, 10); !got.Equal(Mul(a, MustParse("1024", 64))) {
+		t.Errorf("Mul2Si(a, 10) = %v", got)
+	}
+	if got := Div2Si(a, -3); !got.Equal(Mul(a, MustParse("8", 64))) {
+		t.Errorf("Div2Si(a, -3) = %v", got)
+	}
+}
+
+func TestLog10SinCos(t *testing.T) {
+	if got := Log10(MustParse("1000", 64)); !got.Equal(MustParse("3", 64)) {
+		t.Errorf("log10(1000) = %v", got)
+	}
+	a := MustParse("0.3-2.1i", 128)
+	// log10(a)·log(10) = log(a)
+	if got := Mul(Log10(a), Log(MustParse("10", 128))); !got.WithinULP(Log(a), 2) {
+		t.Errorf("log10(a)·log 10 = %v, log a = %v", got, Log(a))
+	}
+	s, c := SinCos(a)
+	if !s.Equal(Sin(a)) || !c.Equal(Cos(a)) {
+		t.Errorf("SinCos = %v, %v; Sin, Cos = %v, %v", s, c, Sin(a), Cos(a))
+	}
+	// sin² + cos² = 1, up to the cancellation of terms near 16.
+	if one := Add(Sqr(s), Sqr(c)); !one.WithinAbs(MustParse("1", 128), MustParseReal("1e-35", 64)) {
+		t.Errorf("sin² + cos² = %v", one)
+	}
+	lo, hi := New(30).SetMode(RNDDD), New(30).SetMode(RNDUU)
+	lo.SinCos(hi, a)
+	if re, im := lo.Acc(); re != Below || im != Below {
+		t.Errorf("sin rounded down: accuracy %v/%v", re, im)
+	}
+	if re, im := hi.Acc(); re != Above || im != Above {
+		t.Errorf("cos rounded up: accuracy %v/%v", re, im)
+	}
+	// The cosine may overwrite the argument.
+	b := a.Clone()
+	New(128).SinCos(b, b)
+	if !b.Equal(c) {
+		t.Errorf("aliased SinCos: cos = %v, want %v", b, c)
+	}
+}
+
+func TestPowVariants(t *testing.T) {
+	z := MustParse("1+i", 64)
+	if got := PowUint64(z, 8); !got.Equal(MustParse("16", 64)) || !got.IsExact() {
+		t.Errorf("(1+i)^8 = %v", got)
+	}
+	if got := PowInt64(z, -2); !got.Equal(MustParse("-0.5i", 64)) {
+		t.Errorf("(1+i)^-2 = %v", got)
+	}
+	n, _ := new(big.Int).SetString("1000000000000000000000000000003", 10)
+	if got := PowBigInt(I(64), n); !got.Equal(MustParse("-i", 64)) {
+		t.Errorf("i^(10^30+3) = %v", got)
+	}
+	if got := PowReal(MustParse("-1", 64), MustParseReal("0.5", 100)); !got.Equal(I(100)) || got.Prec() != 100 {
+		t.Errorf("(-1)^0.5 = %v (%d bits)", got, got.Prec())
+	}
+	w := MustParse("3-4i", 100)
+	if got := PowFloat64(w, 0.5); !got.WithinULP(Sqrt(w), 1) {
+		t.Errorf("(3-4i)^0.5 = %v, want %v", got, Sqrt(w))
+	}
+}
+
+func TestRootOfUnity(t *testing.T) {
+	if got := RootOfUnity(4, 1, 64); !got.Equal(I(64)) || !got.IsExact() {
+		t.Errorf("e^{2πi/4} = %v", got)
+	}
+	one := MustParse("1", 200)
+	for _, n := range []uint64{3, 5, 7, 12, 100} {
+		for _, k := range []uint64{1, n - 1, n + 2} {
+			w := RootOfUnity(n, k, 200)
+			// w^n = 1 and w^k·w^(n-k) = 1.
+			if got := PowUint64(w, n); !got.WithinAbs(one, MustParseReal("1e-55", 64)) {
+				t.Errorf("RootOfUnity(%d, %d)^%d = %v", n, k, n, got)
+			}
+			if got := Mul(w, RootOfUnity(n, n-k%n, 200)); !got.WithinAbs(one, MustParseReal("1e-58", 64)) {
+				t.Errorf("RootOfUnity(%d, %d)·RootOfUnity(%d, %d) = %v", n, k, n, n-k%n, got)
+			}
+		}
+	}
+}
+
+func TestProjAgm(t *testing.T) {
+	z := MustParse("1-2i", 64)
+	if got := Proj(z); !got.Equal(z) {
+		t.Errorf("proj(%v) = %v", z, got)
+	}
+	got := Proj(MustParse("-3-inf i", 64))
+	if re := got.Re(); !re.IsInf() || re.Sign() < 0 || !got.Im().IsZero() || !got.Im().Signbit() {
+		t.Errorf("proj(-3-∞i) = %v", got)
+	}
+	// agm(1, √2) = 1/Gauss's constant; agm is symmetric and homogeneous.
+	a, b := MustParse("1", 128), Sqrt(MustParse("2", 128))
+	want := MustParse("1.198140234735592207439922492280323878227212663215651558", 128)
+	if got := Agm(a, b); !got.WithinULP(want, 1) || !Agm(b, a).Equal(got) {
+		t.Errorf("agm(1, √2) = %v, want %v", got, want)
+	}
+	c := MustParse("0.5+2i", 128)
+	lhs := Agm(Mul(c, a), Mul(c, b))
+	if rhs := Mul(c, Agm(a, b)); !lhs.WithinULP(rhs, 4) {
+		t.Errorf("agm(ca, cb) = %v, c·agm(a, b) = %v", lhs, rhs)
+	}
+}
+
+func TestSumDot(t *testing.T) {
+	huge, one := MustParse("1e30+1e30i", 64), MustParse("1-2i", 64)
+	xs := []*Complex{huge, one, Neg(huge)}
+	if got := Sum(xs); !got.Equal(one) || !got.IsExact() {
+		t.Errorf("Sum = %v, want exactly %v", got, one)
+	}
+	if got := Sum(nil); !got.IsZero() {
+		t.Errorf("empty Sum = %v", got)
+	}
+	ys := []*Complex{MustParse("1", 64), MustParse("1", 64), MustParse("1", 64)}
+	if got := Dot(xs, ys); !got.Equal(one) {
+		t.Errorf("Dot = %v, want %v", got, one)
+	}
+	// Σ k·i^k for k = 1..4 = i - 2 - 3i + 4
+	var x, y []*Complex
+	for k := int64(1); k <= 4; k++ {
+		x = append(x, New(64).SetInt64(k, 0))
+		y = append(y, PowInt64(I(64), k))
+	}
+	if got := Dot(x, y); !got.Equal(MustParse("2-2i", 64)) {
+		t.Errorf("Σ k·i^k = %v", got)
+	}
+	// The result may be one of the operands.
+	s := one.Clone()
+	s.Sum([]*Complex{s, s, huge})
+	if !s.Equal(Add(huge, MustParse("2-4i", 64))) {
+		t.Errorf("aliased Sum = %v", s)
+	}
+}
+
+func TestEta(t *testing.T) {
+	// η(i) = Γ(1/4) / (2π^{3/4})
+	g := NewReal(200).Gamma(MustParseReal("0.25", 200))
+	pi := NewReal(200).SetConst(ConstPi)
+	p := NewReal(200).Pow(pi, MustParseReal("0.75", 200))
+	p.Mul(p, MustParseReal("2", 200))
+	want := New(200).SetReals(NewReal(200).Div(g, p), nil)
+	eta := Eta(I(200))
+	if !eta.WithinULP(want, 2) {
+		t.Errorf("η(i) = %v, want %v", eta, want)
+	}
+	// η(τ+1) = e^{iπ/12} η(τ), η(i/2) = √2 η(2i): both need the reduction.
+	if got, want := Eta(MustParse("1+i", 200)), Mul(RootOfUnity(24, 1, 200), eta); !got.WithinULP(want, 4) {
+		t.Errorf("η(1+i) = %v, want %v", got, want)
+	}
+	eta2 := Eta(MustParse("2i", 200))
+	if got, want := Eta(MustParse("0.5i", 200)), Mul(Sqrt(MustParse("2", 200)), eta2); !got.WithinULP(want, 4) {
+		t.Errorf("η(i/2) = %v, want %v", got, want)
+	}
+	// η(-1/τ) = √(-iτ) η(τ) away from the imaginary axis, with a long reduction.
+	tau := MustParse("17.3+0.01i", 200)
+	lhs := Eta(Neg(Inv(tau)))
+	if rhs := Mul(Sqrt(MulI(tau, -1)), Eta(tau)); !lhs.WithinULP(rhs, 1<<20) {
+		t.Errorf("η(-1/τ) = %v, √(-iτ)η(τ) = %v", lhs, rhs)
+	}
+	if got := Eta(MustParse("1-i", 64)); !got.IsNaN() {
+		t.Errorf("η(1-i) = %v, want NaN", got)
+	}
+}
+
+func TestSwapUrandom(t *testing.T) {
+	a, b := MustParse("1+2i", 64), MustParse("3-4i", 200)
+	a.Swap(b)
+	if a.Prec() != 200 || b.Prec() != 64 || !a.Equal(MustParse("3-4i", 64)) || !b.Equal(MustParse("1+2i", 64)) {
+		t.Errorf("after Swap: %v (%d bits), %v (%d bits)", a, a.Prec(), b, b.Prec())
+	}
+	r1, r2 := NewRandState(42), NewRandState(42)
+	defer r1.Close()
+	defer r2.Close()
+	zero, one := MustParse("0", 64), MustParse("1+i", 64)
+	for k := 0; k < 20; k++ {
+		x, y := Urandom(r1, 100), Urandom(r2, 100)
+		if !x.Equal(y) || !x.IsExact() {
+			t.Fatalf("same seed, different values %v and %v", x, y)
+		}
+		if lr, li := x.CmpParts(zero); lr < 0 || li < 0 {
+			t.Errorf("%v below 0", x)
+		}
+		if hr, hi := x.CmpParts(one); hr >= 0 || hi >= 0 {
+			t.Errorf("%v not below 1", x)
+		}
+	}
+	if re := Urandom(NewRandState(7), 64).Complex128(); math.IsNaN(real(re)) {
+		t.Errorf("Urandom returned NaN")
+	}
+}
+
+func TestSafeMPCFuncs(t *testing.T) {
+	a, b := MustParseSafe("0.5+1i", 128), MustParseSafe("2-1i", 64)
+	s, c := a.SinCos()
+	if !s.Unsafe().Equal(Sin(a.Unsafe())) || !c.Unsafe().Equal(Cos(a.Unsafe())) {
+		t.Errorf("Safe.SinCos = %v, %v", s.Unsafe(), c.Unsafe())
+	}
+	if got := a.Fma(b, a); got.Prec() != 128 || !got.Unsafe().Equal(Fma(a.Unsafe(), b.Unsafe(), a.Unsafe())) {
+		t.Errorf("Safe.Fma = %v", got.Unsafe())
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				switch g % 4 {
+				case 0:
+					a.Swap(b)
+				case 1:
+					// Repeated operands are locked once.
+					DotSafe([]*Safe{a, b, a}, []*Safe{b, a, b})
+				case 2:
+					SumSafe([]*Safe{b, a, b}).Sqr().PowInt64(3).Agm(a)
+				case 3:
+					b.Swap(a)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	sum := SumSafe([]*Safe{a, b})
+	if !sum.Unsafe().Equal(MustParse("2.5", 128)) || sum.Prec() != 128 {
+		t.Errorf("SumSafe = %v (%d bits)", sum.Unsafe(), sum.Prec())
+	}
+	if got := RootOfUnitySafe(4, 1, 64).Unsafe(); !got.Equal(RootOfUnity(4, 1, 64)) {
+		t.Errorf("RootOfUnitySafe(4, 1) = %v", got)
+	}
+	if got, want := UrandomSafe(NewRandState(42), 100), Urandom(NewRandState(42), 100); !got.Unsafe().Equal(want) || got.Prec() != 100 {
+		t.Errorf("UrandomSafe = %v, want %v", got.Unsafe(), want)
+	}
+}
@@ -0,0 +1,227 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+
+// apc_polar_part sets rop to r*cos(t), or r*sin(t) if sine, correctly rounded, with t
+// in radians or, if deg, in degrees. It returns the ternary value.
+static int apc_polar_part(mpfr_ptr rop, mpfr_srcptr r, mpfr_srcptr t, int deg, int sine, mpfr_rnd_t rnd) {
+    // 2cos and 2sin of k*30 degrees; 3 stands for the irrational +-sqrt(3).
+    static const int twice[12][2] = {
+        {2, 0}, {3, 1}, {1, 3}, {0, 2}, {-1, 3}, {-3, 1},
+        {-2, 0}, {-3, -1}, {-1, -3}, {0, -2}, {1, -3}, {3, -1},
+    };
+    mpfr_prec_t prec = mpfr_get_prec(rop), wp;
+    mpfr_t a, x, s, c, y;
+    int ret;
+
+    mpfr_init2(a, mpfr_get_prec(t));
+    mpfr_set(a, t, MPFR_RNDN);
+    if (deg && mpfr_number_p(t)) {
+        // Reduce exactly; sin and cos are rational (so Ziv's loop below would not
+        // end) only at multiples of 30 degrees.
+        mpfr_t k;
+        mpfr_init2(k, mpfr_get_prec(t) + 10);
+        mpfr_set_ui(k, 360, MPFR_RNDN);
+        mpfr_fmod(a, t, k, MPFR_RNDN);
+        if (mpfr_div_ui(k, a, 30, MPFR_RNDN) == 0 && mpfr_integer_p(k)) {
+            int v = twice[(mpfr_get_si(k, MPFR_RNDN) % 12 + 12) % 12][sine != 0];
+            if (v != 3 && v != -3) {
+                mpfr_clear(k);
+                mpfr_clear(a);
+                ret = mpfr_mul_si(rop, r, v, rnd);
+                mpfr_div_2ui(rop, rop, 1, rnd); // exact
+                return ret;
+            }
+        }
+        mpfr_clear(k);
+    }
+    mpfr_inits2(MPFR_PREC_MIN, x, s, c, y, (mpfr_ptr)0);
+    for (wp = prec + 32;; wp += wp / 2) {
+        mpfr_set_prec(x, wp);
+        mpfr_set_prec(s, wp);
+        mpfr_set_prec(c, wp);
+        mpfr_set_prec(y, wp);
+        mpfr_srcptr src = a;
+        if (deg) {
+            mpfr_const_pi(x, MPFR_RNDN);
+            mpfr_mul(x, x, a, MPFR_RNDN);
+            mpfr_div_ui(x, x, 180, MPFR_RNDN);
+            src = x;
+        }
+        int e = mpfr_sin_cos(s, c, src, MPFR_RNDN);
+        mpfr_srcptr f = sine ? s : c;
+        int exact = !deg && (sine ? (e & 3) == 0 : (e >> 2) == 0);
+        if (exact || !mpfr_regular_p(r) || !mpfr_regular_p(f)) {
+            ret = mpfr_mul(rop, r, f, rnd);
+            break;
+        }
+        mpfr_mul(y, r, f, MPFR_RNDN);
+        // |y - r*f(t)| < 2^(EXP(y)-wp+k): half an ulp for each rounding, plus the error
+        // of the converted angle, magnified where f is small.
+        mpfr_exp_t k = 1;
+        if (deg) {
+            k = mpfr_get_exp(x) - mpfr_get_exp(f) + 5;
+            if (k < 2) k = 2;
+        }
+        if (!mpfr_regular_p(y)) {
+            ret = mpfr_mul(rop, r, f, rnd);
+            break;
+        }
+        if (mpfr_can_round(y, wp - k, MPFR_RNDN, rnd, prec + (rnd == MPFR_RNDN))) {
+            ret = mpfr_set(rop, y, rnd);
+            break;
+        }
+    }
+    mpfr_clears(a, x, s, c, y, (mpfr_ptr)0);
+    return ret;
+}
+
+// apc_arg_deg sets rop to the argument of z in degrees, in [-180, 180], correctly
+// rounded, and returns the ternary value.
+static int apc_arg_deg(mpfr_ptr rop, mpc_srcptr z, mpfr_rnd_t rnd) {
+    mpfr_srcptr x = mpc_realref(z), y = mpc_imagref(z);
+    mpfr_prec_t prec = mpfr_get_prec(rop), wp;
+    mpfr_t a, pi;
+    int ret;
+
+    if (mpfr_nan_p(x) || mpfr_nan_p(y)) {
+        mpfr_set_nan(rop);
+        return 0;
+    }
+    if (!mpfr_regular_p(x) || !mpfr_regular_p(y) || mpfr_cmpabs(x, y) == 0) {
+        // On an axis or a diagonal: a multiple of 45 degrees (the only rational
+        // arguments, on which the loop below would not end), keeping the sign of zero.
+        mpfr_init2(a, 53);
+        mpc_arg(a, z, MPFR_RNDN);
+        mpfr_mul_ui(a, a, 4, MPFR_RNDN);
+        mpfr_init2(pi, 53);
+        mpfr_const_pi(pi, MPFR_RNDN);
+        mpfr_div(a, a, pi, MPFR_RNDN);
+        mpfr_round(a, a);
+        ret = mpfr_mul_ui(rop, a, 45, rnd);
+        mpfr_clear(a);
+        mpfr_clear(pi);
+        return ret;
+    }
+    mpfr_init2(a, MPFR_PREC_MIN);
+    mpfr_init2(pi, MPFR_PREC_MIN);
+    for (wp = prec + 32;; wp += wp / 2) {
+        mpfr_set_prec(a, wp);
+        mpfr_set_prec(pi, wp);
+        mpc_arg(a, z, MPFR_RNDN);
+        mpfr_const_pi(pi, MPFR_RNDN);
+        mpfr_mul_ui(a, a, 180, MPFR_RNDN);
+        mpfr_div(a, a, pi, MPFR_RNDN);
+        // four roundings of relative error 2^-wp each
+        if (mpfr_can_round(a, wp - 3, MPFR_RNDN, rnd, prec + (rnd == MPFR_RNDN))) {
+            ret = mpfr_set(rop, a, rnd);
+            break;
+        }
+    }
+    mpfr_clear(a);
+    mpfr_clear(pi);
+    return ret;
+}
+*/
+import "C"
+
+import "strings"
+
+// NewPolar returns r·e^(iθ) = r·(cos θ + i·sin θ), with θ in radians, at the larger of
+// the precisions of r and theta. Both parts are correctly rounded.
+func NewPolar(r, theta *Real) *Complex { return newPolar(r, theta).SetPolar(r, theta) }
+
+// NewPolarDegrees is like NewPolar with θ in degrees. At multiples of 30° the parts
+// that are rational are exact: 2∠60° has the real part 1.
+func NewPolarDegrees(r, theta *Real) *Complex {
+	return newPolar(r, theta).SetPolarDegrees(r, theta)
+}
+
+func newPolar(r, theta *Real) *Complex {
+	if r.prec >= theta.prec {
+		return New(r.prec)
+	}
+	return New(theta.prec)
+}
+
+// SetPolar sets c = r·e^(iθ), with θ in radians, and returns c.
+func (c *Complex) SetPolar(r, theta *Real, mode ...RoundingMode) *Complex {
+	c.setPolar(r.ptr(), theta.ptr(), false, RoundingMode(c.rnd(mode)))
+	return c
+}
+
+// SetPolarDegrees sets c = r·e^(iθ), with θ in degrees, and returns c.
+func (c *Complex) SetPolarDegrees(r, theta *Real, mode ...RoundingMode) *Complex {
+	c.setPolar(r.ptr(), theta.ptr(), true, RoundingMode(c.rnd(mode)))
+	return c
+}
+
+// setPolar sets c = r·(cos t + i·sin t), with t in degrees if deg, each part correctly
+// rounded with the modes of m.
+func (c *Complex) setPolar(r, t *C.__mpfr_struct, deg bool, m RoundingMode) {
+	d := C.int(0)
+	if deg {
+		d = 1
+	}
+	tr := C.apc_polar_part(c.re(), r, t, d, 0, m.Re().mpfr())
+	ti := C.apc_polar_part(c.im(), r, t, d, 1, m.Im().mpfr())
+	c.inex = inexOf(tr, ti)
+}
+
+// Polar returns the magnitude |c| (mpc_abs) and the argument of c in [-π, π] (mpc_arg)
+// as new Reals with c's precision. The argument is -π when c is negative real with a
+// -0 imaginary part.
+func (c *Complex) Polar() (r, theta *Real) { return c.Abs(), c.Arg() }
+
+// PolarDegrees is like Polar with the argument in degrees, in [-180, 180].
+func (c *Complex) PolarDegrees() (r, theta *Real) {
+	return c.Abs(), NewReal(c.prec).SetMode(c.mode.Re()).ArgDegreesOf(c)
+}
+
+// ArgDegreesOf sets r = arg(a) in degrees, correctly rounded, and returns r. Arguments
+// that are multiples of 45° are exact.
+func (r *Real) ArgDegreesOf(a *Complex) *Real {
+	r.inex = C.apc_arg_deg(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// PolarForm selects the notation of TextPolar.
+type PolarForm uint8
+
+const (
+	PolarAngle   PolarForm = iota // "2∠0.5", the angle in radians
+	PolarDegrees                  // "2∠30°"
+	PolarExp                      // "2·e^{i0.5}", the angle in radians
+)
+
+// TextPolar formats c in polar notation, with the magnitude and the angle formatted as
+// by Text(format, prec); the angle is in [-π, π] or [-180°, 180°]. Parse reads all three
+// notations back, also in the strict syntax; the parsed value is the correctly rounded
+// value of the printed magnitude and angle, so it may differ from c in the last place.
+func (c *Complex) TextPolar(form PolarForm, format byte, prec int) string {
+	if !c.init {
+		return "(invalid)"
+	}
+	r := c.Abs()
+	defer r.Close()
+	th := NewReal(c.prec).SetMode(c.mode.Re())
+	defer th.Close()
+	if form == PolarDegrees {
+		th.ArgDegreesOf(c)
+	} else {
+		th.ArgOf(c)
+	}
+	mag, ang := mpfrText(r.ptr(), format, prec), mpfrText(th.ptr(), format, prec)
+	switch form {
+	case PolarDegrees:
+		return mag + "∠" + ang + "°"
+	case PolarExp:
+		if rest, ok := strings.CutPrefix(ang, "-"); ok {
+			return mag + "·e^{-i" + rest + "}"
+		}
+		return mag + "·e^{i" + ang + "}"
+	}
+	return mag + "∠" + ang
+}
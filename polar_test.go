@@ -0,0 +1,132 @@
+package apcomplex
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewPolar(t *testing.T) {
+	r := NewReal(100).SetFloat64(2)
+	th := NewReal(100).SetFloat64(0.5)
+	z := NewPolar(r, th)
+	if z.Prec() != 100 {
+		t.Fatalf("precision %d, want 100", z.Prec())
+	}
+	want := Mul(MustParse("2", 100), Exp(MustParse("0.5i", 100)))
+	if !z.WithinULP(want, 1) {
+		t.Errorf("NewPolar(2, 0.5) = %v, want %v", z, want)
+	}
+	// The parts are correctly rounded: RNDZ truncates, RNDA rounds away, one ulp apart.
+	lo := New(30).SetMode(RNDZZ).SetPolar(r, th)
+	hi := New(30).SetMode(RNDAA).SetPolar(r, th)
+	if re, im := lo.Acc(); re != Below || im != Below || !lo.WithinULP(hi, 1) || lo.Equal(hi) {
+		t.Errorf("SetPolar at 30 bits: %v (%v/%v) and %v", lo, re, im, hi)
+	}
+}
+
+func TestNewPolarDegrees(t *testing.T) {
+	r := NewReal(64).SetFloat64(2)
+	tests := []struct {
+		deg    float64
+		re, im float64 // NaN: irrational, only checked approximately
+	}{
+		{0, 2, 0},
+		{30, math.NaN(), 1},
+		{60, 1, math.NaN()},
+		{90, 0, 2},
+		{150, math.NaN(), 1},
+		{-120, -1, math.NaN()},
+		{180, -2, 0},
+		{540, -2, 0},
+		{-90, 0, -2},
+		{45, math.NaN(), math.NaN()},
+	}
+	for _, tt := range tests {
+		z := NewPolarDegrees(r, NewReal(64).SetFloat64(tt.deg))
+		c := complex(2*math.Cos(tt.deg*math.Pi/180), 2*math.Sin(tt.deg*math.Pi/180))
+		got := z.Complex128()
+		if math.Abs(real(got)-real(c)) > 1e-15 || math.Abs(imag(got)-imag(c)) > 1e-15 {
+			t.Errorf("2∠%v° = %v, want %v", tt.deg, z, c)
+		}
+		re, im := z.Acc()
+		if !math.IsNaN(tt.re) && (re != Exact || real(got) != tt.re) {
+			t.Errorf("2∠%v°: real part %v (%v), want exactly %v", tt.deg, real(got), re, tt.re)
+		}
+		if !math.IsNaN(tt.im) && (im != Exact || imag(got) != tt.im) {
+			t.Errorf("2∠%v°: imaginary part %v (%v), want exactly %v", tt.deg, imag(got), im, tt.im)
+		}
+	}
+}
+
+func TestPolarDegrees(t *testing.T) {
+	tests := []struct {
+		z   string
+		deg string
+	}{
+		{"1+1i", "45"},
+		{"-3+3i", "135"},
+		{"-2", "180"},
+		{"-5i", "-90"},
+		{"0", "0"},
+		{"1+0.5i", "26.565051177077989351572193"},
+	}
+	for _, tt := range tests {
+		r, th := MustParse(tt.z, 90).PolarDegrees()
+		want := MustParseReal(tt.deg, 90)
+		if d := NewReal(90).Sub(th, want); d.CmpAbs(MustParseReal("1e-24", 64)) > 0 {
+			t.Errorf("arg(%s) = %v°, want %v°", tt.z, th, want)
+		}
+		if abs := MustParse(tt.z, 90).Abs(); r.Cmp(abs) != 0 {
+			t.Errorf("|%s| = %v, want %v", tt.z, r, abs)
+		}
+	}
+	if _, th := MustParse("1+1i", 64).PolarDegrees(); th.Acc() != Exact {
+		t.Errorf("arg(1+i) in degrees is not exact")
+	}
+	r, th := MustParse("3+4i", 64).Polar()
+	if r.Text('g', -1) != "5" || math.Abs(th.Float64()-math.Atan2(4, 3)) > 1e-16 {
+		t.Errorf("Polar(3+4i) = %v, %v", r, th)
+	}
+	// Below the negative axis the argument is -π, the closed end of the range.
+	below := Conj(MustParse("-2", 64))
+	if _, th := below.PolarDegrees(); th.Text('g', -1) != "-180" {
+		t.Errorf("arg(-2-0i) = %v°, want -180°", th)
+	}
+	if _, th := below.Polar(); th.Float64() != -math.Pi {
+		t.Errorf("arg(-2-0i) = %v, want -π", th)
+	}
+}
+
+func TestTextPolar(t *testing.T) {
+	z := MustParse("-1+1i", 64)
+	tests := []struct {
+		form PolarForm
+		want string
+	}{
+		{PolarAngle, "1.414∠2.356"},
+		{PolarDegrees, "1.414∠135.000°"},
+		{PolarExp, "1.414·e^{i2.356}"},
+	}
+	for _, tt := range tests {
+		if got := z.TextPolar(tt.form, 'f', 3); got != tt.want {
+			t.Errorf("TextPolar(%d) = %q, want %q", tt.form, got, tt.want)
+		}
+	}
+	if got := Conj(z).TextPolar(PolarExp, 'g', 3); got != "1.41·e^{-i2.36}" {
+		t.Errorf("TextPolar(PolarExp, g) = %q", got)
+	}
+	// Read back in the strict syntax, within an ulp or two of the original.
+	w := Div(MustParse("1-7i", 200), MustParse("3+2i", 200))
+	for _, form := range []PolarForm{PolarAngle, PolarDegrees, PolarExp} {
+		for _, v := range []*Complex{w, Neg(w), Conj(w)} {
+			s := v.TextPolar(form, 'g', -1)
+			back, err := ParseWith(s, 200, ParseOptions{Strict: true})
+			if err != nil || !back.WithinULP(v, 2) {
+				t.Errorf("%q reads back as %v (%v), want %v", s, back, err, v)
+			}
+		}
+	}
+	if got := MustParse("e^(i * 0)", 64); !got.Equal(MustParse("1", 64)) {
+		t.Errorf("e^(i * 0) = %v", got)
+	}
+}
@@ -0,0 +1,226 @@
+package apcomplex
+
+import "fmt"
+
+// Program is a compiled expression with named variables, for evaluating the same
+// formula many times. A Program is immutable and may be shared by any number of
+// goroutines; each goroutine evaluates it through its own Evaluator:
+//
+//	p := apcomplex.MustCompile("exp(lnb*z)", "lnb", "z")
+//	ev := p.NewEvaluator(&apcomplex.Context{Prec: 512})
+//	defer ev.Close()
+//	for ... {
+//		ev.Eval(w, lnb, w) // w = b^w, no allocation per step
+//	}
+type Program struct {
+	src    string
+	vars   []string
+	consts []*exprNode // variable-free subexpressions, evaluated once per Evaluator
+	code   []instr
+	result operand
+	temps  int
+}
+
+// operand addresses a value while a program runs: a constant, a temporary or a
+// variable (an argument of Eval).
+type operand struct {
+	kind  operandKind
+	index int
+}
+
+type operandKind uint8
+
+const (
+	opConst operandKind = iota
+	opTemp
+	opVar
+)
+
+// instr computes temps[dst] = op(a[, b]). dst may be the register of a or b.
+type instr struct {
+	kind exprKind // exprNeg, exprBinary or exprCall
+	op   byte
+	fn   *exprFunc
+	dst  int
+	a, b operand
+}
+
+// Compile parses expr (see Eval for the syntax) with the given variable names, which
+// shadow constants of the same name. Subexpressions that do not depend on variables
+// are evaluated once per Evaluator rather than on every evaluation.
+func Compile(expr string, vars ...string) (*Program, error) {
+	for i, v := range vars {
+		if !isIdent(v) {
+			return nil, fmt.Errorf("apcomplex: invalid variable name %q", v)
+		}
+		for _, w := range vars[:i] {
+			if v == w {
+				return nil, fmt.Errorf("apcomplex: duplicate variable %q", v)
+			}
+		}
+	}
+	n, err := parseExpr(expr, vars)
+	if err != nil {
+		return nil, err
+	}
+	p := &Program{src: expr, vars: append([]string(nil), vars...)}
+	p.result = p.lower(n, 0)
+	return p, nil
+}
+
+// MustCompile is like Compile but panics on error.
+func MustCompile(expr string, vars ...string) *Program {
+	p, err := Compile(expr, vars...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression.
+func (p *Program) String() string { return p.src }
+
+// Vars returns the variable names, in the order Eval expects their values.
+func (p *Program) Vars() []string { return append([]string(nil), p.vars...) }
+
+func isIdent(s string) bool {
+	if s == "" || !isIdentByte(s[0], true) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isIdentByte(s[i], false) {
+			return false
+		}
+	}
+	return true
+}
+
+// lower emits code computing n and returns where its value ends up. Temporaries are
+// used as a stack: sp is the first free one, and a result computed into a temporary
+// always lands in temps[sp].
+func (p *Program) lower(n *exprNode, sp int) operand {
+	if n.kind == exprVar {
+		return operand{kind: opVar, index: n.index}
+	}
+	if !n.hasVar() {
+		p.consts = append(p.consts, n)
+		return operand{kind: opConst, index: len(p.consts) - 1}
+	}
+	in := instr{kind: n.kind, op: n.op, fn: n.fn, dst: sp}
+	a := p.lower(n.args[0], sp)
+	in.a = a
+	if len(n.args) > 1 {
+		next := sp
+		if a.kind == opTemp {
+			next++
+		}
+		in.b = p.lower(n.args[1], next)
+	}
+	if sp+1 > p.temps {
+		p.temps = sp + 1
+	}
+	p.code = append(p.code, in)
+	return operand{kind: opTemp, index: sp}
+}
+
+func (n *exprNode) hasVar() bool {
+	if n.kind == exprVar {
+		return true
+	}
+	for _, a := range n.args {
+		if a.hasVar() {
+			return true
+		}
+	}
+	return false
+}
+
+// Eval evaluates p once with a fresh Evaluator under ctx, like the package-level Eval,
+// including its exponent range and traps. Use NewEvaluator for repeated evaluation.
+func (p *Program) Eval(ctx *Context, args ...*Complex) (*Complex, error) {
+	if len(args) != len(p.vars) {
+		return nil, fmt.Errorf("apcomplex: %q takes %d variable(s), got %d", p.src, len(p.vars), len(args))
+	}
+	z := ctx.New()
+	var ev *Evaluator
+	err := ctx.run("eval", z, func() {
+		ev = p.NewEvaluator(ctx)
+		ev.Eval(z, args...)
+	})
+	ev.Close()
+	return z, err
+}
+
+// Evaluator runs a Program with its own constants and scratch values at a fixed
+// precision and rounding mode. It is not safe for concurrent use; create one per
+// goroutine.
+type Evaluator struct {
+	p      *Program
+	consts []*Complex
+	temps  []*Complex
+}
+
+// NewEvaluator prepares p for evaluation at the precision and rounding mode of ctx
+// (nil uses DefaultPrec and RNDNN). Constants and variable-free subexpressions are
+// evaluated here, once.
+func (p *Program) NewEvaluator(ctx *Context) *Evaluator {
+	prec, mode := ctx.prec(), ctx.mode()
+	ev := &Evaluator{p: p, consts: make([]*Complex, len(p.consts)), temps: make([]*Complex, p.temps)}
+	for i, n := range p.consts {
+		ev.consts[i] = n.eval(prec, mode, nil)
+	}
+	for i := range ev.temps {
+		ev.temps[i] = New(prec).SetMode(mode)
+	}
+	return ev
+}
+
+// Close frees the evaluator's constants and scratch values.
+func (ev *Evaluator) Close() {
+	for _, z := range ev.consts {
+		z.Close()
+	}
+	for _, z := range ev.temps {
+		z.Close()
+	}
+}
+
+// Eval sets z to the value of the program for the given variable values, in the order
+// of Vars, and returns z. The operations run at the evaluator's precision and the
+// result is rounded into z. Eval panics if the number of values does not match.
+func (ev *Evaluator) Eval(z *Complex, args ...*Complex) *Complex {
+	if len(args) != len(ev.p.vars) {
+		panic(fmt.Sprintf("apcomplex: %q takes %d variable(s), got %d", ev.p.src, len(ev.p.vars), len(args)))
+	}
+	for _, in := range ev.p.code {
+		dst := ev.temps[in.dst]
+		switch in.kind {
+		case exprNeg:
+			dst.Neg(ev.value(in.a, args))
+		case exprBinary:
+			dst.binaryOp(in.op, ev.value(in.a, args), ev.value(in.b, args))
+		case exprCall:
+			if in.fn.binary != nil {
+				in.fn.binary(dst, ev.value(in.a, args), ev.value(in.b, args))
+			} else {
+				in.fn.unary(dst, ev.value(in.a, args))
+			}
+		}
+	}
+	res := ev.value(ev.p.result, args)
+	z.Set(res)
+	if z.prec == res.prec {
+		z.inex = res.inex // Set was exact; report the rounding of the last operation
+	}
+	return z
+}
+
+func (ev *Evaluator) value(o operand, args []*Complex) *Complex {
+	switch o.kind {
+	case opConst:
+		return ev.consts[o.index]
+	case opTemp:
+		return ev.temps[o.index]
+	}
+	return args[o.index]
+}
@@ -0,0 +1,125 @@
+package apcomplex
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCompileMatchesEval(t *testing.T) {
+	ctx := &Context{Prec: 160}
+	tests := []struct {
+		expr string
+		vars []string
+		args []string
+	}{
+		{"z", []string{"z"}, []string{"1+2i"}},
+		{"2+3*4", nil, nil},
+		{"x^2 + y^2 - 2*x*y", []string{"x", "y"}, []string{"3-1i", "0.5+2i"}},
+		{"exp(i*pi/3)*z + sqrt(-2)", []string{"z"}, []string{"-1.25+4i"}},
+		{"pow(a, b) / (a - b) + -a", []string{"a", "b"}, []string{"2+1i", "0.5-3i"}},
+		{"abs(z) + arg(z)*i + norm(z - 1)", []string{"z"}, []string{"3+4i"}},
+		{"e*pi", []string{"e"}, []string{"2"}}, // variables shadow constants
+		{"-(a*(b+(a*(b+(a*(b+a))))))", []string{"a", "b"}, []string{"1.5", "-2i"}},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr, tt.vars...)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.expr, err)
+		}
+		var args []*Complex
+		for _, a := range tt.args {
+			args = append(args, MustParse(a, 160))
+		}
+		got, err := p.Eval(ctx, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Reference: the tree evaluator with the same variable bindings.
+		n, _ := parseExpr(tt.expr, tt.vars)
+		want := n.eval(160, RNDNN, args)
+		if got.Cmp(want) != 0 {
+			t.Errorf("%q: compiled %v, interpreted %v", tt.expr, got, want)
+		}
+	}
+	if got, _ := MustCompile("e*pi", "e").Eval(nil, MustParse("2", 64)); !got.WithinAbs(Mul(MustParse("2", 256), mustEval(t, "pi")), MustParseReal("1e-70", 64)) {
+		t.Errorf("e*pi with e=2 gives %v", got)
+	}
+}
+
+func mustEval(t *testing.T, expr string) *Complex {
+	t.Helper()
+	z, err := Eval(expr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return z
+}
+
+func TestCompileErrors(t *testing.T) {
+	if _, err := Compile("x+y", "x"); err == nil {
+		t.Errorf("undeclared variable accepted")
+	}
+	if _, err := Compile("x", "x", "x"); err == nil {
+		t.Errorf("duplicate variable accepted")
+	}
+	if _, err := Compile("x", "2x"); err == nil {
+		t.Errorf("invalid variable name accepted")
+	}
+	p := MustCompile("x*y", "x", "y")
+	if _, err := p.Eval(nil, MustParse("1", 64)); err == nil {
+		t.Errorf("wrong number of values accepted")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Evaluator.Eval with wrong arity did not panic")
+		}
+	}()
+	p.NewEvaluator(nil).Eval(New(64), MustParse("1", 64))
+}
+
+func TestEvaluatorReuse(t *testing.T) {
+	// Iterate w = b^w for b = sqrt(2), which converges to 2.
+	const prec = 256
+	p := MustCompile("exp(lnb*w)", "lnb", "w")
+	if p.temps > 2 {
+		t.Errorf("exp(lnb*w) uses %d temporaries", p.temps)
+	}
+	ev := p.NewEvaluator(&Context{Prec: prec})
+	defer ev.Close()
+	lnb := Log(Sqrt(MustParse("2", prec)))
+	w := MustParse("1", prec)
+	for i := 0; i < 400; i++ {
+		ev.Eval(w, lnb, w)
+	}
+	if !w.WithinAbs(MustParse("2", prec), MustParseReal("1e-50", 64)) {
+		t.Fatalf("tower of sqrt(2) = %v", w)
+	}
+	allocs := testing.AllocsPerRun(100, func() { ev.Eval(w, lnb, w) })
+	if allocs > 0 {
+		t.Errorf("Evaluator.Eval allocates %.0f times per run", allocs)
+	}
+}
+
+func TestProgramConcurrent(t *testing.T) {
+	p := MustCompile("sin(z)^2 + cos(z)^2", "z")
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ev := p.NewEvaluator(&Context{Prec: 128})
+			defer ev.Close()
+			z, out := New(128), New(128)
+			one := MustParse("1", 128)
+			for i := 0; i < 200; i++ {
+				z.SetInt64(int64(g), int64(i%20-10))
+				z.Div(z, MustParse("7", 128))
+				if !ev.Eval(out, z).WithinRel(one, MustParseReal("1e-30", 64)) {
+					t.Errorf("goroutine %d: sin²+cos² at %v = %v", g, z, out)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
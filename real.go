@@ -0,0 +1,553 @@
+package apcomplex
+
+/*
+#include <stdlib.h>
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Real is an arbitrary-precision real number backed by an MPFR mpfr_t.
+// Use NewReal; zero value is not usable.
+type Real struct {
+	x    C.mpfr_t
+	prec uint
+	mode Rnd
+	inex C.int // ternary value of the last operation
+	init bool
+	once sync.Once
+}
+
+// NewReal allocates a real with the given precision in bits. If bits==0, DefaultPrec is used.
+// The value starts as NaN, like MPFR.
+func NewReal(bits uint) *Real {
+	if bits == 0 {
+		bits = DefaultPrec
+	}
+	r := &Real{prec: bits}
+	C.mpfr_init2(&r.x[0], C.mpfr_prec_t(bits))
+	r.init = true
+	runtime.SetFinalizer(r, func(rr *Real) { rr.Close() })
+	return r
+}
+
+// lazyInit initializes a zero Real (as used by Scan) at DefaultPrec, without a finalizer.
+func (r *Real) lazyInit() {
+	if r.init || r.prec != 0 {
+		return
+	}
+	r.prec = DefaultPrec
+	C.mpfr_init2(&r.x[0], C.mpfr_prec_t(r.prec))
+	r.init = true
+}
+
+// Close frees C resources.
+func (r *Real) Close() {
+	if r == nil {
+		return
+	}
+	r.once.Do(func() {
+		if r.init {
+			C.mpfr_clear(&r.x[0])
+			r.init = false
+		}
+	})
+}
+
+// Prec returns precision in bits.
+func (r *Real) Prec() uint { return r.prec }
+
+// Mode returns the rounding direction used by operations storing into r.
+func (r *Real) Mode() Rnd { return r.mode }
+
+// SetMode sets the rounding direction used by operations storing into r and returns r.
+func (r *Real) SetMode(m Rnd) *Real {
+	r.mode = m
+	return r
+}
+
+// Acc reports how the most recent operation that stored into r was rounded.
+func (r *Real) Acc() Accuracy { return accOf(r.inex) }
+
+func (r *Real) ptr() *C.__mpfr_struct { return &r.x[0] }
+
+// rnd picks the rounding direction for one call: an explicit per-call direction wins.
+func (r *Real) rnd(mode []Rnd) C.mpfr_rnd_t {
+	if len(mode) > 0 {
+		return mode[0].mpfr()
+	}
+	return r.mode.mpfr()
+}
+
+// SetPrec changes precision, rounding the current value to the new precision.
+func (r *Real) SetPrec(bits uint) *Real {
+	if !r.init {
+		panic("apcomplex: not initialized")
+	}
+	if bits == 0 {
+		bits = DefaultPrec
+	}
+	if bits == r.prec {
+		return r
+	}
+	r.inex = C.mpfr_prec_round(r.ptr(), C.mpfr_prec_t(bits), r.mode.mpfr())
+	r.prec = bits
+	return r
+}
+
+// Clone returns a deep copy (including the rounding mode).
+func (r *Real) Clone() *Real {
+	out := NewReal(r.prec).SetMode(r.mode)
+	out.inex = C.mpfr_set(out.ptr(), r.ptr(), r.mode.mpfr())
+	return out
+}
+
+// ParseReal parses a real literal at the given precision. Accepts MPFR's decimal syntax,
+// e.g. "1.25", "-3e-100", "inf", "-inf", "nan".
+func ParseReal(s string, prec uint) (*Real, error) {
+	r := NewReal(prec)
+	if err := r.SetString(s); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// MustParseReal panics on error.
+func MustParseReal(s string, prec uint) *Real {
+	r, err := ParseReal(s, prec)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// SetString sets r from a base-10 string (see ParseReal).
+func (r *Real) SetString(s string) error { return r.SetBase(s, 10) }
+
+// SetBase sets r from a string in the given base (<=0 defaults to 10).
+func (r *Real) SetBase(s string, base int, mode ...Rnd) error {
+	if !r.init {
+		return errors.New("apcomplex: not initialized")
+	}
+	if base <= 0 {
+		base = 10
+	}
+	cs := C.CString(strings.TrimSpace(s))
+	defer C.free(unsafe.Pointer(cs))
+	var end *C.char
+	t := C.mpfr_strtofr(r.ptr(), cs, &end, C.int(base), r.rnd(mode))
+	if *cs == 0 || *end != 0 {
+		return fmt.Errorf("apcomplex: invalid real literal %q", s)
+	}
+	r.inex = t
+	return nil
+}
+
+// Conversions
+func (r *Real) Set(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_set(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) SetFloat64(x float64, mode ...Rnd) *Real {
+	r.inex = C.mpfr_set_d(r.ptr(), C.double(x), r.rnd(mode))
+	return r
+}
+func (r *Real) SetInt64(x int64, mode ...Rnd) *Real {
+	r.inex = C.mpfr_set_si(r.ptr(), C.long(x), r.rnd(mode))
+	return r
+}
+
+// SetBigFloat sets r = f (nil means 0) by transferring the mantissa directly.
+func (r *Real) SetBigFloat(f *big.Float, mode ...Rnd) *Real {
+	r.inex = setMpfrBigFloat(r.ptr(), f, Rnd(r.rnd(mode)))
+	return r
+}
+
+// SetInf sets r to +Inf (sign >= 0) or -Inf; SetNaN sets r to NaN.
+func (r *Real) SetInf(sign int) *Real {
+	C.mpfr_set_inf(r.ptr(), C.int(sign))
+	r.inex = 0
+	return r
+}
+func (r *Real) SetNaN() *Real {
+	C.mpfr_set_nan(r.ptr())
+	r.inex = 0
+	return r
+}
+
+// BigRat returns r as an exact rational, or nil if r is NaN or infinite.
+func (r *Real) BigRat() *big.Rat { return mpfrToBigRat(r.ptr()) }
+
+// Int64 returns r rounded to an integer with r's mode (saturating at the int64 limits).
+func (r *Real) Int64() int64 { return int64(C.mpfr_get_si(r.ptr(), r.mode.mpfr())) }
+
+// Predicates and comparison
+func (r *Real) IsNaN() bool     { return C.mpfr_nan_p(r.ptr()) != 0 }
+func (r *Real) IsInf() bool     { return C.mpfr_inf_p(r.ptr()) != 0 }
+func (r *Real) IsZero() bool    { return C.mpfr_zero_p(r.ptr()) != 0 }
+func (r *Real) IsInt() bool     { return C.mpfr_integer_p(r.ptr()) != 0 }
+func (r *Real) Signbit() bool   { return C.mpfr_signbit(r.ptr()) != 0 }
+func (r *Real) Sign() int       { return int(C.mpfr_sgn(r.ptr())) }
+func (r *Real) Cmp(a *Real) int { return int(C.mpfr_cmp(r.ptr(), a.ptr())) }
+func (r *Real) CmpAbs(a *Real) int {
+	return int(C.mpfr_cmpabs(r.ptr(), a.ptr()))
+}
+
+// RealOf sets r = Re(a) and returns r.
+func (r *Real) RealOf(a *Complex) *Real {
+	r.inex = C.mpc_real(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// ImagOf sets r = Im(a) and returns r.
+func (r *Real) ImagOf(a *Complex) *Real {
+	r.inex = C.mpc_imag(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// Re returns the real part of c as a new Real with c's precision.
+func (c *Complex) Re() *Real { return NewReal(c.prec).SetMode(c.mode.Re()).RealOf(c) }
+
+// Im returns the imaginary part of c as a new Real with c's precision.
+func (c *Complex) Im() *Real { return NewReal(c.prec).SetMode(c.mode.Im()).ImagOf(c) }
+
+// SetReals sets c = re + i*im (a nil part means 0) and returns c.
+func (c *Complex) SetReals(re, im *Real, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	var tr, ti C.int
+	if re == nil {
+		C.mpfr_set_zero(c.re(), 1)
+	} else {
+		tr = C.mpfr_set(c.re(), re.ptr(), m.Re().mpfr())
+	}
+	if im == nil {
+		C.mpfr_set_zero(c.im(), 1)
+	} else {
+		ti = C.mpfr_set(c.im(), im.ptr(), m.Im().mpfr())
+	}
+	c.inex = inexOf(tr, ti)
+	return c
+}
+
+// AbsOf sets r = |a| and returns r.
+func (r *Real) AbsOf(a *Complex) *Real {
+	r.inex = C.mpc_abs(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// ArgOf sets r = arg(a) and returns r.
+func (r *Real) ArgOf(a *Complex) *Real {
+	r.inex = C.mpc_arg(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// NormOf sets r = |a|^2 and returns r.
+func (r *Real) NormOf(a *Complex) *Real {
+	r.inex = C.mpc_norm(r.ptr(), &a.z[0], r.mode.mpfr())
+	return r
+}
+
+// Arithmetic (mutating; return receiver for chaining). Each accepts an optional Rnd
+// overriding r's own rounding direction for that call only.
+func (r *Real) Add(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_add(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sub(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sub(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Mul(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_mul(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Div(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_div(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Neg(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_neg(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Abs(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_abs(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sqr(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sqr(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sqrt(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sqrt(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) RecSqrt(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_rec_sqrt(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Cbrt(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_cbrt(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Fma(a, b, c *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_fma(r.ptr(), a.ptr(), b.ptr(), c.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) RootN(a *Real, n uint, mode ...Rnd) *Real {
+	r.inex = C.mpfr_rootn_ui(r.ptr(), a.ptr(), C.ulong(n), r.rnd(mode))
+	return r
+}
+func (r *Real) Pow(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_pow(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Atan2(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_atan2(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Hypot(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_hypot(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Agm(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_agm(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Beta(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_beta(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Min(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_min(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Max(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_max(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Fmod(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_fmod(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Remainder(a, b *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_remainder(r.ptr(), a.ptr(), b.ptr(), r.rnd(mode))
+	return r
+}
+
+// Elementary/transcendental
+func (r *Real) Exp(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_exp(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Exp2(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_exp2(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Exp10(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_exp10(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Expm1(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_expm1(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Log(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_log(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Log2(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_log2(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Log10(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_log10(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Log1p(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_log1p(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sin(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sin(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Cos(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_cos(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Tan(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_tan(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sec(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sec(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Csc(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_csc(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Cot(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_cot(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Asin(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_asin(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Acos(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_acos(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Atan(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_atan(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Sinh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_sinh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Cosh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_cosh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Tanh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_tanh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Asinh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_asinh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Acosh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_acosh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Atanh(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_atanh(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+
+// Special functions
+func (r *Real) Gamma(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_gamma(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) LogGamma(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_lngamma(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Digamma(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_digamma(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Zeta(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_zeta(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Erf(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_erf(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Erfc(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_erfc(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Eint(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_eint(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Li2(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_li2(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) J0(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_j0(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) J1(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_j1(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Y0(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_y0(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Y1(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_y1(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Ai(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_ai(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Frac(a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_frac(r.ptr(), a.ptr(), r.rnd(mode))
+	return r
+}
+
+// LogAbsGamma sets r = log|Gamma(a)| and returns r together with the sign of Gamma(a).
+func (r *Real) LogAbsGamma(a *Real, mode ...Rnd) (*Real, int) {
+	var sign C.int
+	r.inex = C.mpfr_lgamma(r.ptr(), &sign, a.ptr(), r.rnd(mode))
+	return r, int(sign)
+}
+func (r *Real) Jn(n int, a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_jn(r.ptr(), C.long(n), a.ptr(), r.rnd(mode))
+	return r
+}
+func (r *Real) Yn(n int, a *Real, mode ...Rnd) *Real {
+	r.inex = C.mpfr_yn(r.ptr(), C.long(n), a.ptr(), r.rnd(mode))
+	return r
+}
+
+// Integer rounding (if the integer does not fit r's precision, the nearest representable
+// value in the same direction is used)
+func (r *Real) Floor(a *Real) *Real {
+	r.inex = C.mpfr_rint(r.ptr(), a.ptr(), C.MPFR_RNDD)
+	return r
+}
+func (r *Real) Ceil(a *Real) *Real {
+	r.inex = C.mpfr_rint(r.ptr(), a.ptr(), C.MPFR_RNDU)
+	return r
+}
+func (r *Real) Trunc(a *Real) *Real {
+	r.inex = C.mpfr_rint(r.ptr(), a.ptr(), C.MPFR_RNDZ)
+	return r
+}
+func (r *Real) Round(a *Real) *Real {
+	r.inex = C.mpfr_rint(r.ptr(), a.ptr(), C.MPFR_RNDNA) // ties away from zero
+	return r
+}
+
+// Float64 returns the nearest float64 (rounded with r's mode).
+func (r *Real) Float64() float64 { return float64(C.mpfr_get_d(r.ptr(), r.mode.mpfr())) }
+
+// BigFloat returns r as an exact *big.Float with r's precision, or nil if r is NaN.
+func (r *Real) BigFloat() *big.Float { return mpfrToBigFloat(r.ptr()) }
+
+// Formatting
+func (r *Real) StringFixed(digits int) string {
+	if !r.init {
+		return "(invalid)"
+	}
+	return mpfrStringFixed(r.ptr(), digits)
+}
+
+func (r *Real) StringScientific(digits int) string {
+	if !r.init {
+		return "(invalid)"
+	}
+	return mpfrStringSci(r.ptr(), digits)
+}
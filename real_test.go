@@ -0,0 +1,98 @@
+package apcomplex
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAbsArgNorm(t *testing.T) {
+	z := MustParse("3-4i", 200)
+	if a := z.Abs(); a.StringFixed(5) != "5.00000" || a.Prec() != 200 || a.Acc() != Exact {
+		t.Fatalf("|3-4i| = %s (prec %d, acc %v)", a.StringFixed(5), a.Prec(), a.Acc())
+	}
+	if n := z.Norm(); n.Float64() != 25 {
+		t.Fatalf("norm(3-4i) = %v", n.Float64())
+	}
+	if got, want := z.Arg().Float64(), math.Atan2(-4, 3); got != want {
+		t.Fatalf("arg(3-4i) = %v, want %v", got, want)
+	}
+	if z.AbsFloat64() != 5 || z.NormFloat64() != 25 || z.ArgFloat64() != math.Atan2(-4, 3) {
+		t.Fatalf("float64 shortcuts: %v %v %v", z.AbsFloat64(), z.NormFloat64(), z.ArgFloat64())
+	}
+	// The string helpers keep their previous output.
+	if s := New(200).AbsStringFixed(z, 3); s != "5.000" {
+		t.Fatalf("AbsStringFixed = %q", s)
+	}
+}
+
+func TestAbsKeepsPrecision(t *testing.T) {
+	// |1 + 1e-60 i| - 1 ~ 5e-121 vanishes in float64 but not at 512 bits.
+	z := MustParse("1+1e-60i", 512)
+	a := z.Abs().BigFloat()
+	if a == nil || a.Prec() != 512 {
+		t.Fatalf("BigFloat of |z| = %v", a)
+	}
+	one := New(512).SetInt64(1, 0).Real()
+	if a.Cmp(one) <= 0 {
+		t.Fatalf("|1+1e-60i| should exceed 1 at 512 bits, got %s", a.Text('g', 40))
+	}
+	if z.AbsFloat64() != 1 {
+		t.Fatalf("AbsFloat64 should round to 1, got %v", z.AbsFloat64())
+	}
+}
+
+func TestRealParseAndFunctions(t *testing.T) {
+	const prec = 256
+	x := MustParseReal("2", prec)
+	tests := []struct {
+		name string
+		got  *Real
+		want string
+	}{
+		{"sqrt(2)", NewReal(prec).Sqrt(x), "1.414213562373095048801688724209698078570"},
+		{"gamma(5)", NewReal(prec).Gamma(MustParseReal("5", prec)), "24.000000000000000000000000000000000000000"},
+		{"zeta(2)", NewReal(prec).Zeta(x), "1.644934066848226436472415166646025189219"},
+		{"erf(1)", NewReal(prec).Erf(MustParseReal("1", prec)), "0.842700792949714869341220635082609259296"},
+		{"j0(1)", NewReal(prec).J0(MustParseReal("1", prec)), "0.765197686557966551449717526102663220909"},
+		{"atan2(1,-1)", NewReal(prec).Atan2(MustParseReal("1", prec), MustParseReal("-1", prec)), "2.356194490192344928846982537459627163148"},
+		{"exp(log(2))", NewReal(prec).Exp(NewReal(prec).Log(x)), "2.000000000000000000000000000000000000000"},
+		{"floor(-2.5)", NewReal(prec).Floor(MustParseReal("-2.5", prec)), "-3.000000000000000000000000000000000000000"},
+		{"round(2.5)", NewReal(prec).Round(MustParseReal("2.5", prec)), "3.000000000000000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		if s := tt.got.StringFixed(39); s != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, s, tt.want)
+		}
+	}
+	if _, err := ParseReal("1.2.3", prec); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if r := MustParseReal("-inf", prec); !r.IsInf() || r.Sign() >= 0 {
+		t.Fatalf("-inf not parsed")
+	}
+	if _, sign := NewReal(prec).LogAbsGamma(MustParseReal("-0.5", prec)); sign != -1 {
+		t.Fatalf("sign of Gamma(-0.5) = %d", sign)
+	}
+}
+
+func TestRealRoundingAndParts(t *testing.T) {
+	third := NewReal(64).Div(MustParseReal("1", 64), MustParseReal("3", 64), RNDD)
+	up := NewReal(64).SetMode(RNDU).Div(MustParseReal("1", 64), MustParseReal("3", 64))
+	if third.Acc() != Below || up.Acc() != Above || third.Cmp(up) >= 0 {
+		t.Fatalf("directed division: %v %v", third.Acc(), up.Acc())
+	}
+	p := MustParseReal("1.5", 64).SetPrec(512)
+	if p.StringFixed(3) != "1.500" {
+		t.Fatalf("SetPrec must keep the value, got %s", p.StringFixed(3))
+	}
+
+	z := MustParse("1.25-7i", 128)
+	re, im := z.Re(), z.Im()
+	if re.StringFixed(2) != "1.25" || im.StringFixed(2) != "-7.00" || re.Prec() != 128 {
+		t.Fatalf("Re/Im = %s %s", re.StringFixed(2), im.StringFixed(2))
+	}
+	w := New(128).SetReals(im, re)
+	if w.StringFixed(2) != "-7.00+1.25i" {
+		t.Fatalf("SetReals = %s", w.StringFixed(2))
+	}
+}
@@ -0,0 +1,82 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+*/
+import "C"
+
+import "fmt"
+
+// Rnd is an MPFR rounding direction applied to a single real component.
+type Rnd uint8
+
+// MPFR rounding directions (values match mpfr_rnd_t).
+const (
+	RNDN Rnd = iota // round to nearest, ties to even
+	RNDZ            // round toward zero
+	RNDU            // round toward +Inf
+	RNDD            // round toward -Inf
+	RNDA            // round away from zero
+)
+
+func (r Rnd) String() string {
+	switch r {
+	case RNDN:
+		return "RNDN"
+	case RNDZ:
+		return "RNDZ"
+	case RNDU:
+		return "RNDU"
+	case RNDD:
+		return "RNDD"
+	case RNDA:
+		return "RNDA"
+	}
+	return fmt.Sprintf("Rnd(%d)", uint8(r))
+}
+
+func (r Rnd) mpfr() C.mpfr_rnd_t { return C.mpfr_rnd_t(r) }
+
+// RoundingMode selects the rounding directions of the real and imaginary parts
+// independently. It uses the same encoding as MPC's mpc_rnd_t; the zero value is RNDNN.
+type RoundingMode uint8
+
+// Common modes where both parts round the same way. Mixed modes are built with Rounding.
+const (
+	RNDNN RoundingMode = RoundingMode(RNDN) | RoundingMode(RNDN)<<4
+	RNDZZ RoundingMode = RoundingMode(RNDZ) | RoundingMode(RNDZ)<<4
+	RNDUU RoundingMode = RoundingMode(RNDU) | RoundingMode(RNDU)<<4
+	RNDDD RoundingMode = RoundingMode(RNDD) | RoundingMode(RNDD)<<4
+	RNDAA RoundingMode = RoundingMode(RNDA) | RoundingMode(RNDA)<<4
+)
+
+// Rounding combines the real and imaginary rounding directions into a RoundingMode.
+func Rounding(re, im Rnd) RoundingMode { return RoundingMode(re&0x0f) | RoundingMode(im&0x0f)<<4 }
+
+// Re returns the rounding direction of the real part.
+func (m RoundingMode) Re() Rnd { return Rnd(m & 0x0f) }
+
+// Im returns the rounding direction of the imaginary part.
+func (m RoundingMode) Im() Rnd { return Rnd(m >> 4) }
+
+func (m RoundingMode) String() string { return "(" + m.Re().String() + "," + m.Im().String() + ")" }
+
+func (m RoundingMode) mpc() C.mpc_rnd_t { return C.mpc_rnd_t(m) }
+
+// rnd picks the rounding mode for one call: an explicit per-call mode wins over the value's own mode.
+func (c *Complex) rnd(mode []RoundingMode) C.mpc_rnd_t {
+	if len(mode) > 0 {
+		return mode[0].mpc()
+	}
+	return c.mode.mpc()
+}
+
+// Mode returns the rounding mode used by operations storing into c.
+func (c *Complex) Mode() RoundingMode { return c.mode }
+
+// SetMode sets the rounding mode used by subsequent operations storing into c and returns c.
+// Individual operations can still override it by passing a mode explicitly.
+func (c *Complex) SetMode(m RoundingMode) *Complex {
+	c.mode = m
+	return c
+}
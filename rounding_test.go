@@ -0,0 +1,51 @@
+package apcomplex
+
+import "testing"
+
+func TestRoundingModeEncoding(t *testing.T) {
+	m := Rounding(RNDU, RNDD)
+	if m.Re() != RNDU || m.Im() != RNDD {
+		t.Fatalf("Rounding(RNDU, RNDD) decoded as %v", m)
+	}
+	if RoundingMode(0) != RNDNN {
+		t.Fatalf("zero RoundingMode must be RNDNN, got %v", RoundingMode(0))
+	}
+	if New(64).Mode() != RNDNN {
+		t.Fatalf("new values must round to nearest")
+	}
+}
+
+// Directed rounding must bracket the exact quotient: down(1/3) < up(1/3).
+func TestDirectedRoundingEnclosure(t *testing.T) {
+	one := MustParse("1-1i", 64)
+	three := MustParse("3", 64)
+	lo := New(64).SetMode(RNDDD).Div(one, three)
+	hi := New(64).Div(one, three, RNDUU) // per-call override
+	w := Sub(hi, lo)
+	if f64(w.RealStringFixed(40)) <= 0 || f64(w.ImagStringFixed(40)) <= 0 {
+		t.Fatalf("expected hi > lo in both parts, got hi=%s lo=%s", hi.StringScientific(25), lo.StringScientific(25))
+	}
+	if lo.Mode() != RNDDD {
+		t.Fatalf("SetMode not retained, got %v", lo.Mode())
+	}
+
+	// Mixed mode rounds each part independently.
+	mixed := New(64).Div(one, three, Rounding(RNDU, RNDD))
+	if mixed.RealStringFixed(40) != hi.RealStringFixed(40) || mixed.ImagStringFixed(40) != lo.ImagStringFixed(40) {
+		t.Fatalf("mixed rounding mismatch: %s", mixed.StringScientific(25))
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	lo := New(8).SetMode(RNDZZ)
+	hi := New(8).SetMode(RNDAA)
+	if err := lo.SetString("0.1+0.1i"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hi.SetString("0.1+0.1i"); err != nil {
+		t.Fatal(err)
+	}
+	if lo.StringScientific(10) == hi.StringScientific(10) {
+		t.Fatalf("toward-zero and away-from-zero parses of 0.1 should differ at 8 bits")
+	}
+}
@@ -1,6 +1,9 @@
 package apcomplex
 
 import (
+	"cmp"
+	"math/big"
+	"slices"
 	"sync"
 	"unsafe"
 )
@@ -27,6 +30,19 @@ func (s *Safe) Prec() uint { s.mu.RLock(); p := s.c.prec; s.mu.RUnlock(); return
 // SetPrec updates precision (rounding value).
 func (s *Safe) SetPrec(bits uint) { s.mu.Lock(); s.c.SetPrec(bits); s.mu.Unlock() }
 
+// Mode reads the rounding mode.
+func (s *Safe) Mode() RoundingMode { s.mu.RLock(); m := s.c.mode; s.mu.RUnlock(); return m }
+
+// SetMode updates the rounding mode used for results derived from s.
+func (s *Safe) SetMode(m RoundingMode) { s.mu.Lock(); s.c.SetMode(m); s.mu.Unlock() }
+
+// newSafeLike allocates a result with precision bits and the rounding mode of src.
+func newSafeLike(src *Complex, bits uint) *Safe {
+	res := NewSafe(bits)
+	res.c.mode = src.mode
+	return res
+}
+
 // String/format helpers (read-only)
 func (s *Safe) StringFixed(d int) string {
 	s.mu.RLock()
@@ -78,7 +94,7 @@ func lockPairR(a, b *Safe) (unlock func()) {
 
 func (a *Safe) Neg() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Neg(a.c)
 	a.mu.RUnlock()
 	return res
@@ -86,7 +102,7 @@ func (a *Safe) Neg() *Safe {
 
 func (a *Safe) Conj() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Conj(a.c)
 	a.mu.RUnlock()
 	return res
@@ -94,7 +110,7 @@ func (a *Safe) Conj() *Safe {
 
 func (a *Safe) Inv() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Inv(a.c)
 	a.mu.RUnlock()
 	return res
@@ -107,7 +123,7 @@ func (a *Safe) Add(b *Safe) *Safe {
 	if b.c.prec > p {
 		p = b.c.prec
 	}
-	res := NewSafe(p)
+	res := newSafeLike(a.c, p)
 	res.c.Add(a.c, b.c)
 	return res
 }
@@ -119,7 +135,7 @@ func (a *Safe) Sub(b *Safe) *Safe {
 	if b.c.prec > p {
 		p = b.c.prec
 	}
-	res := NewSafe(p)
+	res := newSafeLike(a.c, p)
 	res.c.Sub(a.c, b.c)
 	return res
 }
@@ -131,7 +147,7 @@ func (a *Safe) Mul(b *Safe) *Safe {
 	if b.c.prec > p {
 		p = b.c.prec
 	}
-	res := NewSafe(p)
+	res := newSafeLike(a.c, p)
 	res.c.Mul(a.c, b.c)
 	return res
 }
@@ -143,7 +159,7 @@ func (a *Safe) Div(b *Safe) *Safe {
 	if b.c.prec > p {
 		p = b.c.prec
 	}
-	res := NewSafe(p)
+	res := newSafeLike(a.c, p)
 	res.c.Div(a.c, b.c)
 	return res
 }
@@ -155,7 +171,7 @@ func (a *Safe) Pow(b *Safe) *Safe {
 	if b.c.prec > p {
 		p = b.c.prec
 	}
-	res := NewSafe(p)
+	res := newSafeLike(a.c, p)
 	res.c.Pow(a.c, b.c)
 	return res
 }
@@ -163,110 +179,420 @@ func (a *Safe) Pow(b *Safe) *Safe {
 // Elementary / transcendental (read one, produce new)
 func (a *Safe) Sqrt() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Sqrt(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Exp() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Exp(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Log() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Log(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Sin() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Sin(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Cos() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Cos(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Tan() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Tan(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Asin() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Asin(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Acos() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Acos(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Atan() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Atan(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Sinh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Sinh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Cosh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Cosh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Tanh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Tanh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Asinh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Asinh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Acosh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Acosh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 func (a *Safe) Atanh() *Safe {
 	a.mu.RLock()
-	res := NewSafe(a.c.prec)
+	res := newSafeLike(a.c, a.c.prec)
 	res.c.Atanh(a.c)
 	a.mu.RUnlock()
 	return res
 }
 
+func (a *Safe) Sqr() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Sqr(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Log10() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Log10(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Proj() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Proj(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Eta() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Eta(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Gamma() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Gamma(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) LogGamma() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.LogGamma(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Digamma() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Digamma(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Polygamma(n uint) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Polygamma(n, a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Zeta() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Zeta(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) DirichletEta() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.DirichletEta(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Erf() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Erf(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Erfc() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Erfc(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Erfi() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Erfi(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Faddeeva() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Faddeeva(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Dawson() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Dawson(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) FresnelC() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.FresnelC(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) FresnelS() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.FresnelS(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Ai() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Ai(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Bi() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Bi(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) AiPrime() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.AiPrime(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) BiPrime() *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.BiPrime(a.c)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) LambertW(k int) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.LambertW(k, a.c)
+	a.mu.RUnlock()
+	return res
+}
+
+// HurwitzZeta returns ζ(a, q) with a as the exponent.
+func (a *Safe) HurwitzZeta(q *Safe) *Safe {
+	unlock := lockPairR(a, q)
+	defer unlock()
+	res := newSafeLike(a.c, maxPrec(a.c, q.c))
+	res.c.HurwitzZeta(a.c, q.c)
+	return res
+}
+func (a *Safe) MulI(sign int) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.MulI(a.c, sign)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Mul2Si(n int64) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Mul2Si(a.c, n)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) Div2Si(n int64) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.Div2Si(a.c, n)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) PowUint64(n uint64) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.PowUint64(a.c, n)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) PowInt64(n int64) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.PowInt64(a.c, n)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) PowBigInt(n *big.Int) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.PowBigInt(a.c, n)
+	a.mu.RUnlock()
+	return res
+}
+func (a *Safe) PowFloat64(x float64) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, a.c.prec)
+	res.c.PowFloat64(a.c, x)
+	a.mu.RUnlock()
+	return res
+}
+
+// PowReal uses the higher of the two precisions. x must not be modified concurrently.
+func (a *Safe) PowReal(x *Real) *Safe {
+	a.mu.RLock()
+	res := newSafeLike(a.c, max(a.c.prec, x.prec))
+	res.c.PowReal(a.c, x)
+	a.mu.RUnlock()
+	return res
+}
+
+// SinCos returns sin a and cos a, computed together.
+func (a *Safe) SinCos() (sin, cos *Safe) {
+	a.mu.RLock()
+	sin, cos = newSafeLike(a.c, a.c.prec), newSafeLike(a.c, a.c.prec)
+	sin.c.SinCos(cos.c, a.c)
+	a.mu.RUnlock()
+	return sin, cos
+}
+
+func (a *Safe) Agm(b *Safe) *Safe {
+	unlock := lockPairR(a, b)
+	defer unlock()
+	res := newSafeLike(a.c, maxPrec(a.c, b.c))
+	res.c.Agm(a.c, b.c)
+	return res
+}
+
+// Fma returns a·b + d with a single rounding.
+func (a *Safe) Fma(b, d *Safe) *Safe {
+	unlock := lockAllR(a, b, d)
+	defer unlock()
+	res := newSafeLike(a.c, maxPrec(a.c, b.c, d.c))
+	res.c.Fma(a.c, b.c, d.c)
+	return res
+}
+
+// Swap exchanges the values (and precisions) of a and b, locking both for writing.
+func (a *Safe) Swap(b *Safe) {
+	if a == b {
+		return
+	}
+	first, second := a, b
+	if uintptr(unsafe.Pointer(b)) < uintptr(unsafe.Pointer(a)) {
+		first, second = b, a
+	}
+	first.mu.Lock()
+	second.mu.Lock()
+	a.c.Swap(b.c)
+	second.mu.Unlock()
+	first.mu.Unlock()
+}
+
+// SumSafe returns the correctly rounded sum of xs (see Sum).
+func SumSafe(xs []*Safe) *Safe {
+	unlock := lockAllR(xs...)
+	defer unlock()
+	return WrapSafe(Sum(unwrapSafe(xs)))
+}
+
+// DotSafe returns the correctly rounded dot product of x and y (see Dot).
+func DotSafe(x, y []*Safe) *Safe {
+	unlock := lockAllR(append(x[:len(x):len(x)], y...)...)
+	defer unlock()
+	return WrapSafe(Dot(unwrapSafe(x), unwrapSafe(y)))
+}
+
+// RootOfUnitySafe returns e^{2πik/n} with the given precision (see RootOfUnity).
+func RootOfUnitySafe(n, k uint64, prec uint) *Safe { return WrapSafe(RootOfUnity(n, k, prec)) }
+
+// UrandomSafe returns a value with both parts uniform in [0, 1) (see Urandom).
+// Urandom serializes on rs, so one RandState may feed several goroutines.
+func UrandomSafe(rs *RandState, prec uint) *Safe { return WrapSafe(Urandom(rs, prec)) }
+
+func unwrapSafe(xs []*Safe) []*Complex {
+	cs := make([]*Complex, len(xs))
+	for i, x := range xs {
+		cs[i] = x.c
+	}
+	return cs
+}
+
+// lockAllR read-locks each distinct Safe in xs once, in address order (see lockPairR).
+func lockAllR(xs ...*Safe) (unlock func()) {
+	sorted := slices.Clone(xs)
+	slices.SortFunc(sorted, func(a, b *Safe) int {
+		return cmp.Compare(uintptr(unsafe.Pointer(a)), uintptr(unsafe.Pointer(b)))
+	})
+	sorted = slices.Compact(sorted)
+	for _, s := range sorted {
+		s.mu.RLock()
+	}
+	return func() {
+		for i := len(sorted) - 1; i >= 0; i-- {
+			sorted[i].mu.RUnlock()
+		}
+	}
+}
+
 // Constructors from strings
 func ParseSafe(s string, prec uint) (*Safe, error) {
 	z, err := Parse(s, prec)
@@ -0,0 +1,100 @@
+package apcomplex
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Value implements driver.Valuer. It stores c in its lossless text form, "prec:a+bi"
+// (see MarshalText), which fits TEXT and VARCHAR columns of any database. A nil c is
+// stored as NULL.
+func (c *Complex) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	text, err := c.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// SQLScanner returns an sql.Scanner that stores a column into c, for use as a
+// destination of (*sql.Rows).Scan:
+//
+//	z := apcomplex.New(256)
+//	err := row.Scan(z.SQLScanner())
+//
+// (*Complex).Scan itself implements fmt.Scanner; the two interfaces share a method
+// name, so the database form is provided by this adapter and by NullComplex.
+//
+// The scanner accepts the text form written by Value, any literal Parse understands
+// ("a+bi", "(a b)"), the binary form of MarshalBinary (for BYTEA and BLOB columns) and
+// plain float64 and int64 numbers. Literals without a precision and numbers keep c's
+// precision. NULL is an error; scan nullable columns into a NullComplex.
+func (c *Complex) SQLScanner() sql.Scanner { return sqlScanner{c} }
+
+type sqlScanner struct{ c *Complex }
+
+func (s sqlScanner) Scan(src any) error { return s.c.scanSQL(src) }
+
+func (c *Complex) scanSQL(src any) error {
+	switch v := src.(type) {
+	case string:
+		return c.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) > 0 && v[0] == binaryVersion {
+			return c.UnmarshalBinary(v)
+		}
+		return c.UnmarshalText(v)
+	case float64:
+		if err := c.prepare(0); err != nil {
+			return err
+		}
+		c.SetFloat64(v, 0)
+		return nil
+	case int64:
+		if err := c.prepare(0); err != nil {
+			return err
+		}
+		c.SetInt64(v, 0)
+		return nil
+	case nil:
+		return errors.New("apcomplex: cannot scan NULL into *Complex; use NullComplex")
+	}
+	return fmt.Errorf("apcomplex: cannot scan %T into *Complex", src)
+}
+
+// NullComplex is a *Complex that may be NULL, like sql.NullString.
+type NullComplex struct {
+	Complex *Complex
+	Valid   bool // Valid is true if Complex is not NULL
+}
+
+// Scan implements sql.Scanner. A non-NULL value is scanned into n.Complex (see
+// SQLScanner), which is allocated at DefaultPrec if nil.
+func (n *NullComplex) Scan(src any) error {
+	if src == nil {
+		n.Complex, n.Valid = nil, false
+		return nil
+	}
+	if n.Complex == nil {
+		n.Complex = New(0)
+	}
+	if err := n.Complex.scanSQL(src); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n NullComplex) Value() (driver.Value, error) {
+	if !n.Valid || n.Complex == nil {
+		return nil, nil
+	}
+	return n.Complex.Value()
+}
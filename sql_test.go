@@ -0,0 +1,83 @@
+package apcomplex
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+var (
+	_ driver.Valuer = (*Complex)(nil)
+	_ driver.Valuer = NullComplex{}
+	_ sql.Scanner   = (*NullComplex)(nil)
+)
+
+func TestSQLValueScanRoundTrip(t *testing.T) {
+	for _, z := range marshalSamples() {
+		v, err := z.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := v.(string); !ok || !driver.IsValue(v) {
+			t.Fatalf("Value returned %T", v)
+		}
+		back := New(0)
+		if err := back.SQLScanner().Scan(v); err != nil || !sameValue(back, z) {
+			t.Errorf("%v: scan of %q failed (%v)", z, v, err)
+		}
+		bin, _ := z.MarshalBinary()
+		back = New(0)
+		if err := back.SQLScanner().Scan(bin); err != nil || !sameValue(back, z) {
+			t.Errorf("%v: scan of binary form failed (%v)", z, err)
+		}
+	}
+}
+
+func TestSQLScanForms(t *testing.T) {
+	tests := []struct {
+		src  any
+		want string
+	}{
+		{"1.5-2i", "1.5-2i"},
+		{[]byte("(2.5 -4.75)"), "2.5-4.75i"},
+		{float64(0.25), "0.25"},
+		{int64(-7), "-7"},
+	}
+	for _, tt := range tests {
+		z := New(96)
+		if err := z.SQLScanner().Scan(tt.src); err != nil {
+			t.Fatalf("Scan(%v): %v", tt.src, err)
+		}
+		if z.Prec() != 96 || !z.Equal(MustParse(tt.want, 96)) {
+			t.Errorf("Scan(%v) = %v at %d bits, want %s", tt.src, z, z.Prec(), tt.want)
+		}
+	}
+	if err := New(0).SQLScanner().Scan(nil); err == nil {
+		t.Errorf("NULL scanned into *Complex without error")
+	}
+	if err := New(0).SQLScanner().Scan(true); err == nil {
+		t.Errorf("bool scanned into *Complex without error")
+	}
+}
+
+func TestNullComplex(t *testing.T) {
+	var n NullComplex
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Fatalf("NULL: %+v, %v", n, err)
+	}
+	if v, err := n.Value(); v != nil || err != nil {
+		t.Fatalf("Value of NULL = %v, %v", v, err)
+	}
+	if v, err := (*Complex)(nil).Value(); v != nil || err != nil {
+		t.Fatalf("Value of nil *Complex = %v, %v", v, err)
+	}
+	if err := n.Scan("128:3-4i"); err != nil || !n.Valid || n.Complex.Prec() != 128 {
+		t.Fatalf("scan: %+v, %v", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != "128:3-4i" {
+		t.Fatalf("Value = %v, %v", v, err)
+	}
+	if err := n.Scan("garbage"); err == nil || n.Valid {
+		t.Fatalf("invalid literal: valid=%v err=%v", n.Valid, err)
+	}
+}
@@ -0,0 +1,347 @@
+package apcomplex
+
+/*
+#include <mpc.h>
+#include <mpfr.h>
+*/
+import "C"
+
+import (
+	"math"
+	"math/bits"
+)
+
+// The Riemann and Hurwitz zeta functions over the complex plane, evaluated by
+// Euler–Maclaurin summation
+//
+//	ζ(s, a) = Σ_{k<N} (a+k)^-s + w^(1-s)/(s-1) + w^-s/2
+//	        + Σ_{j≥1} B_2j/(2j)! s(s+1)…(s+2j-2) w^(-s-2j+1),  w = a+N,
+//
+// with N chosen from the working precision and |s| so that the tail terms shrink by at
+// least 4 bits each. The cost is O(|s| + prec) powers, so far up the critical line the
+// evaluation slows down linearly in Im s.
+//
+// Only Zeta of a real argument is correctly rounded, by mpfr_zeta. Everything else is
+// summed at the working precision and rounded once, so its error is a few ulps of the
+// largest terms of the sum: of the value itself away from the zeros of ζ, but not next
+// to them, where Z(t) and ζ(1/2 + it) keep only absolute accuracy.
+
+// zetaWorkPrec returns the working precision for a zeta value at c's precision from s
+// and a: the powers (a+k)^-s = exp(-s log(a+k)) turn an absolute error in the exponent
+// into a relative one, costing the bits of |s| log N.
+func zetaWorkPrec(c, s, a *Complex) uint {
+	wp := max(c.prec, s.prec, a.prec) + gammaGuard
+	if e := magExp(s); e > 0 {
+		wp += uint(bits.Len(uint(e))) + uint(e)
+	}
+	if e := magExp(a); e > 0 {
+		wp += uint(bits.Len(uint(e)))
+	}
+	return wp
+}
+
+// eulerMaclaurinN returns the number of terms summed directly for ζ(s, a) at wp bits:
+// with |w| ≥ 2(|s| + wp/2 + 1)/π the ratio of consecutive tail terms, about
+// |s+2j|²/(2π|w|)², stays below 1/16 for the wp/4 terms needed.
+func eulerMaclaurinN(s, a complex128, wp uint) int {
+	x := 2 * (math.Hypot(real(s), imag(s)) + float64(wp)/2 + 1) / math.Pi
+	if imag(a) != 0 {
+		// Only the real part of a+N can grow.
+		x = math.Sqrt(max(x*x-imag(a)*imag(a), 0))
+	}
+	return max(int(math.Ceil(x-real(a))), 0)
+}
+
+// hurwitzSum sets z = ζ(s, a) at wp bits by Euler–Maclaurin summation. s must not be 1.
+// A term (a+k)^-s with a+k = 0 is left out: it is 0 for Re s > 0 and the caller handles
+// the other cases.
+func hurwitzSum(z, s, a *Complex, wp uint) {
+	sv, av := s.Complex128(), a.Complex128()
+	if real(sv) < 0 {
+		// The direct terms grow like |w|^-Re s while the sum may not: cancellation.
+		w := float64(eulerMaclaurinN(sv, av, wp)) + math.Abs(real(av)) + math.Abs(imag(av)) + 1
+		wp += uint(math.Ceil(-real(sv) * math.Log2(w)))
+	}
+	n := eulerMaclaurinN(sv, av, wp)
+
+	negs, t, f, sum := New(wp).Neg(s, RNDNN), New(wp), New(wp), New(wp)
+	defer negs.Close()
+	defer t.Close()
+	defer f.Close()
+	defer sum.Close()
+	sum.SetInt64(0, 0)
+	for k := 0; k < n; k++ {
+		C.mpc_add_ui(&f.z[0], &a.z[0], C.ulong(k), C.MPC_RNDNN)
+		if f.IsZero() {
+			continue
+		}
+		t.Pow(f, negs, RNDNN)
+		sum.Add(sum, t, RNDNN)
+	}
+
+	// w = a+N; p = w^-s
+	w, p, inv2 := New(wp), New(wp), New(wp)
+	defer w.Close()
+	defer p.Close()
+	defer inv2.Close()
+	C.mpc_add_ui(&w.z[0], &a.z[0], C.ulong(n), C.MPC_RNDNN)
+	p.Pow(w, negs, RNDNN)
+	inv2.Inv(w, RNDNN).Sqr(inv2, RNDNN)
+	// w^(1-s)/(s-1)
+	C.mpc_sub_ui(&t.z[0], &s.z[0], 1, C.MPC_RNDNN)
+	f.Mul(w, p, RNDNN).Div(f, t, RNDNN)
+	sum.Add(sum, f, RNDNN)
+	C.mpc_div_2si(&t.z[0], &p.z[0], 1, C.MPC_RNDNN)
+	sum.Add(sum, t, RNDNN)
+
+	// f = s(s+1)…(s+2j-2) w^(-s-2j+1) / (2j)!
+	C.mpc_div(&f.z[0], &p.z[0], &w.z[0], C.MPC_RNDNN)
+	f.Mul(f, s, RNDNN)
+	C.mpc_div_ui(&f.z[0], &f.z[0], 2, C.MPC_RNDNN)
+	r := New(wp)
+	defer r.Close()
+	for j := 1; ; j++ {
+		C.mpc_mul_fr(&t.z[0], &f.z[0], bernoulli2k(j, wp).ptr(), C.MPC_RNDNN)
+		sum.Add(sum, t, RNDNN)
+		if magExp(t) < magExp(sum)-int(wp) || j > int(wp) || t.IsZero() {
+			break
+		}
+		C.mpc_add_ui(&r.z[0], &s.z[0], C.ulong(2*j-1), C.MPC_RNDNN)
+		f.Mul(f, r, RNDNN)
+		C.mpc_add_ui(&r.z[0], &s.z[0], C.ulong(2*j), C.MPC_RNDNN)
+		f.Mul(f, r, RNDNN).Mul(f, inv2, RNDNN)
+		C.mpc_div_ui(&f.z[0], &f.z[0], C.ulong((2*j+1)*(2*j+2)), C.MPC_RNDNN)
+	}
+	z.Set(sum, RNDNN)
+}
+
+// isOne reports whether a is exactly 1.
+func isOne(a *Complex) bool {
+	return C.mpfr_zero_p(a.im()) != 0 && C.mpfr_cmp_ui(a.re(), 1) == 0
+}
+
+// setPole sets c = +∞ + 0i, the value at a pole, and returns c.
+func (c *Complex) setPole() *Complex {
+	C.mpfr_set_inf(c.re(), 1)
+	C.mpfr_set_zero(c.im(), 1)
+	c.inex = 0
+	return c
+}
+
+// Zeta sets c = ζ(a), the Riemann zeta function, and returns c. ζ(1) is +∞. Left of
+// Re a = 0 the functional equation ζ(s) = 2^s π^(s-1) sin(πs/2) Γ(1-s) ζ(1-s) is used.
+func (c *Complex) Zeta(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if C.mpfr_number_p(a.re()) == 0 || C.mpfr_number_p(a.im()) == 0 {
+		if C.mpfr_inf_p(a.re()) != 0 && C.mpfr_sgn(a.re()) > 0 && C.mpfr_zero_p(a.im()) != 0 {
+			c.inex = C.mpc_set_ui(&c.z[0], 1, C.mpc_rnd_t(m))
+			return c
+		}
+		C.mpc_set_nan(&c.z[0])
+		c.inex = 0
+		return c
+	}
+	if C.mpfr_zero_p(a.im()) != 0 {
+		c.setRealAxis(a, m, func(x, y *C.__mpfr_struct, r C.mpfr_rnd_t) C.int { return C.mpfr_zeta(x, y, r) })
+		return c
+	}
+	one := New(2).SetInt64(1, 0)
+	defer one.Close()
+	wp := zetaWorkPrec(c, a, one)
+	if C.mpfr_sgn(a.re()) >= 0 {
+		s := New(wp)
+		defer s.Close()
+		hurwitzSum(s, a, one, wp)
+		c.inex = C.mpc_set(&c.z[0], &s.z[0], C.mpc_rnd_t(m))
+		return c
+	}
+	// Γ(1-s) and (2π)^s grow exponentially: the same extra bits as for Gamma.
+	wp = max(wp, gammaWorkPrec(c, a, true))
+	s, t, u := New(wp), New(wp), New(wp)
+	defer s.Close()
+	defer t.Close()
+	defer u.Close()
+	C.mpc_ui_ui_sub(&t.z[0], 1, 0, &a.z[0], C.MPC_RNDNN)
+	hurwitzSum(s, t, one, wp)
+	u.Gamma(t, RNDNN)
+	s.Mul(s, u, RNDNN)
+	pi := NewReal(wp).SetConst(ConstPi)
+	defer pi.Close()
+	// sin(πs/2)
+	C.mpc_mul_fr(&u.z[0], &a.z[0], pi.ptr(), C.MPC_RNDNN)
+	C.mpc_div_2si(&u.z[0], &u.z[0], 1, C.MPC_RNDNN)
+	u.Sin(u, RNDNN)
+	s.Mul(s, u, RNDNN)
+	// 2^s π^(s-1) = (2π)^s / π
+	C.mpfr_mul_2si(t.re(), pi.ptr(), 1, C.MPFR_RNDN)
+	C.mpfr_set_zero(t.im(), 1)
+	u.Pow(t, a, RNDNN)
+	s.Mul(s, u, RNDNN)
+	C.mpc_div_fr(&s.z[0], &s.z[0], pi.ptr(), C.MPC_RNDNN)
+	c.inex = C.mpc_set(&c.z[0], &s.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// HurwitzZeta sets c = ζ(s, a) = Σ (a+k)^-s, k = 0, 1, …, and returns c. The powers are
+// principal, so for a on the negative real axis the sign of its zero imaginary part
+// selects the side, as for Log. s = 1 is a pole (+∞); so is a nonpositive integer a
+// when Re s > 0, while for Re s < 0 its vanishing term 0^-s is left out.
+func (c *Complex) HurwitzZeta(s, a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	for _, x := range []*Complex{s, a} {
+		if C.mpfr_number_p(x.re()) == 0 || C.mpfr_number_p(x.im()) == 0 {
+			C.mpc_set_nan(&c.z[0])
+			c.inex = 0
+			return c
+		}
+	}
+	if isOne(s) {
+		return c.setPole()
+	}
+	if isPole(a) {
+		switch C.mpfr_sgn(s.re()) {
+		case 1:
+			return c.setPole()
+		case 0:
+			// 0^-it has no limit.
+			C.mpc_set_nan(&c.z[0])
+			c.inex = 0
+			return c
+		}
+	}
+	wp := zetaWorkPrec(c, s, a)
+	z := New(wp)
+	defer z.Close()
+	hurwitzSum(z, s, a, wp)
+	c.inex = C.mpc_set(&c.z[0], &z.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// DirichletEta sets c = η(a) = Σ (-1)^(k-1) k^-a = (1 - 2^(1-a)) ζ(a), the alternating
+// zeta function, and returns c. It is entire; η(1) = log 2. (Eta is the Dedekind eta
+// function.)
+func (c *Complex) DirichletEta(a *Complex, mode ...RoundingMode) *Complex {
+	m := RoundingMode(c.rnd(mode))
+	if isOne(a) {
+		tr := C.mpfr_const_log2(c.re(), m.Re().mpfr())
+		C.mpfr_set_zero(c.im(), 1)
+		c.inex = inexOf(tr, 0)
+		return c
+	}
+	if C.mpfr_number_p(a.re()) == 0 || C.mpfr_number_p(a.im()) == 0 {
+		if C.mpfr_inf_p(a.re()) != 0 && C.mpfr_sgn(a.re()) > 0 && C.mpfr_zero_p(a.im()) != 0 {
+			c.inex = C.mpc_set_ui(&c.z[0], 1, C.mpc_rnd_t(m))
+			return c
+		}
+		C.mpc_set_nan(&c.z[0])
+		c.inex = 0
+		return c
+	}
+	// 1 - 2^(1-a) cancels near a = 1, as ζ(a) grows.
+	wp := max(c.prec, a.prec) + gammaGuard
+	d := New(wp)
+	defer d.Close()
+	C.mpc_sub_ui(&d.z[0], &a.z[0], 1, C.MPC_RNDNN)
+	if e := magExp(d); e < 0 {
+		wp += uint(-e)
+	}
+	z, f := New(wp).SetMode(RNDNN), New(wp)
+	defer z.Close()
+	defer f.Close()
+	z.Zeta(a)
+	two := New(2).SetInt64(2, 0)
+	defer two.Close()
+	C.mpc_ui_ui_sub(&d.z[0], 1, 0, &a.z[0], C.MPC_RNDNN)
+	f.Pow(two, d, RNDNN)
+	C.mpc_ui_ui_sub(&f.z[0], 1, 0, &f.z[0], C.MPC_RNDNN)
+	z.Mul(z, f, RNDNN)
+	if C.mpfr_zero_p(a.im()) != 0 {
+		// Real a: keep a's zero rather than the product's.
+		sign := C.int(1)
+		if C.mpfr_signbit(a.im()) != 0 {
+			sign = -1
+		}
+		tr := C.mpfr_set(c.re(), z.re(), m.Re().mpfr())
+		C.mpfr_set_zero(c.im(), sign)
+		c.inex = inexOf(tr, 0)
+		return c
+	}
+	c.inex = C.mpc_set(&c.z[0], &z.z[0], C.mpc_rnd_t(m))
+	return c
+}
+
+// RiemannSiegelTheta sets r = θ(t) = arg Γ(1/4 + it/2) - (t/2) log π, the argument taken
+// continuously from θ(0) = 0, and returns r.
+func (r *Real) RiemannSiegelTheta(t *Real, mode ...Rnd) *Real {
+	if C.mpfr_number_p(t.ptr()) == 0 {
+		r.inex = C.mpfr_set(r.ptr(), t.ptr(), r.rnd(mode))
+		return r
+	}
+	wp := riemannSiegelPrec(r, t)
+	th := NewReal(wp)
+	defer th.Close()
+	riemannSiegelTheta(th, t, wp)
+	r.inex = C.mpfr_set(r.ptr(), th.ptr(), r.rnd(mode))
+	return r
+}
+
+// RiemannSiegelZ sets r = Z(t) = e^(iθ(t)) ζ(1/2 + it) and returns r. Z is real for real
+// t, with |Z(t)| = |ζ(1/2 + it)|, so its sign changes are the zeros on the critical line.
+func (r *Real) RiemannSiegelZ(t *Real, mode ...Rnd) *Real {
+	if C.mpfr_number_p(t.ptr()) == 0 {
+		r.SetNaN()
+		return r
+	}
+	wp := riemannSiegelPrec(r, t)
+	th := NewReal(wp)
+	defer th.Close()
+	riemannSiegelTheta(th, t, wp)
+	s, e := New(wp), New(wp)
+	defer s.Close()
+	defer e.Close()
+	C.mpfr_set_d(s.re(), 0.5, C.MPFR_RNDN)
+	C.mpfr_set(s.im(), t.ptr(), C.MPFR_RNDN)
+	s.Zeta(s, RNDNN)
+	C.mpfr_set_zero(e.re(), 1)
+	C.mpfr_set(e.im(), th.ptr(), C.MPFR_RNDN)
+	e.Exp(e, RNDNN)
+	s.Mul(s, e, RNDNN)
+	r.inex = C.mpfr_set(r.ptr(), s.re(), r.rnd(mode))
+	return r
+}
+
+// riemannSiegelPrec returns the working precision for θ(t) or Z(t) at r's precision:
+// θ(t) is about (t/2) log t, so its absolute error grows with t.
+func riemannSiegelPrec(r, t *Real) uint {
+	wp := max(r.prec, t.prec) + gammaGuard
+	if C.mpfr_regular_p(t.ptr()) != 0 {
+		if e := int(C.mpfr_get_exp(t.ptr())); e > 0 {
+			wp += uint(e) + uint(bits.Len(uint(e)))
+		}
+	}
+	return wp
+}
+
+// riemannSiegelTheta sets th = θ(t) at wp bits for finite t.
+func riemannSiegelTheta(th, t *Real, wp uint) {
+	z := New(wp)
+	defer z.Close()
+	C.mpfr_set_d(z.re(), 0.25, C.MPFR_RNDN)
+	C.mpfr_div_2si(z.im(), t.ptr(), 1, C.MPFR_RNDN)
+	z.LogGamma(z, RNDNN)
+	lp := NewReal(wp).SetConst(ConstPi)
+	defer lp.Close()
+	lp.Log(lp, RNDN)
+	C.mpfr_mul(lp.ptr(), lp.ptr(), t.ptr(), C.MPFR_RNDN)
+	C.mpfr_div_2si(lp.ptr(), lp.ptr(), 1, C.MPFR_RNDN)
+	C.mpfr_sub(th.ptr(), z.im(), lp.ptr(), C.MPFR_RNDN)
+}
+
+// Non-mutating helpers
+func Zeta(a *Complex, mode ...RoundingMode) *Complex { return newLike(a).Zeta(a, mode...) }
+func DirichletEta(a *Complex, mode ...RoundingMode) *Complex {
+	return newLike(a).DirichletEta(a, mode...)
+}
+func HurwitzZeta(s, a *Complex, mode ...RoundingMode) *Complex {
+	return New(maxPrec(s, a)).SetMode(s.mode).HurwitzZeta(s, a, mode...)
+}
@@ -0,0 +1,188 @@
+package apcomplex
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// firstZero is the imaginary part of the first nontrivial zero of ζ.
+const firstZero = "14.134725141734693790457251983562470270784257115699243175685567460149963429809256764949010393171561"
+
+func TestZetaKnown(t *testing.T) {
+	// Real arguments are correctly rounded by MPFR.
+	pi := Pi(200)
+	if got, want := Zeta(MustParse("2", 200)), Div(Sqr(pi), MustParse("6", 200)); !got.WithinULP(want, 1) {
+		t.Errorf("ζ(2) = %v, want %v", got, want)
+	}
+	if got := Zeta(MustParse("-1", 64)); !got.Equal(MustParse("-0.083333333333333333333", 64)) {
+		t.Errorf("ζ(-1) = %v, want -1/12", got)
+	}
+	if got := Zeta(MustParse("1", 64)); !got.Re().IsInf() || !got.Im().IsZero() {
+		t.Errorf("ζ(1) = %v, want ∞", got)
+	}
+	if got := Zeta(MustParse("nan+i", 64)); !got.IsNaN() {
+		t.Errorf("ζ(nan+i) = %v", got)
+	}
+	for _, prec := range []uint{64, 200, 300} {
+		z := Zeta(MustParse("0.5+"+firstZero+"i", prec))
+		if tol := NewReal(64).SetFloat64(math.Ldexp(1, 12-int(prec))); z.Abs().Cmp(tol) > 0 {
+			t.Errorf("%d bits: ζ at the first zero = %v", prec, z)
+		}
+	}
+	// Trivial zeros through the functional equation, slightly off the axis.
+	for _, s := range []string{"-2+1e-30i", "-20+1e-30i"} {
+		if z := Zeta(MustParse(s, 200)); !z.WithinAbs(New(200).SetInt64(0, 0), MustParseReal("1e-25", 64)) {
+			t.Errorf("ζ(%s) = %v, want about 0", s, z)
+		}
+	}
+}
+
+func TestZetaAgainstHurwitz(t *testing.T) {
+	one := MustParse("1", 200)
+	// Euler–Maclaurin on the real axis against MPFR.
+	for _, s := range []string{"0.5", "3", "-2.5", "-11.25", "40"} {
+		x := MustParse(s, 200)
+		if got, want := HurwitzZeta(x, one), Zeta(x); !relClose(got, want, 8) {
+			t.Errorf("ζ(%s, 1) = %v, ζ(%s) = %v", s, got, s, want)
+		}
+	}
+	// The functional equation against Euler–Maclaurin left of the critical strip.
+	for _, s := range []string{"-0.5+3i", "-7.25-2i", "-30+10i"} {
+		x := MustParse(s, 200)
+		if got, want := Zeta(x), HurwitzZeta(x, one); !relClose(got, want, 10) {
+			t.Errorf("ζ(%s) = %v, ζ(%s, 1) = %v", s, got, s, want)
+		}
+	}
+	// Right of the functional equation Zeta and HurwitzZeta share the summation, so the
+	// references come from Borwein's alternating series for η at 1200 bits.
+	tests := []struct{ s, want string }{
+		{"0.3-25i", "-0.2882016695874612234387677790612479694320429958+0.1296251804968942786347907443800741620972116646i"},
+		{"2+100i", "1.190780408775217015875667762381138783233990658-0.05389095935426045832395429375877888101210244296i"},
+	}
+	for _, tt := range tests {
+		want := MustParse(tt.want, 150)
+		if got := Zeta(MustParse(tt.s, 150)); !relClose(got, want, 10) {
+			t.Errorf("ζ(%s) = %v, want %v", tt.s, got, want)
+		}
+	}
+}
+
+func TestHurwitzZeta(t *testing.T) {
+	for _, prec := range []uint{64, 256} {
+		one := MustParse("1", prec)
+		two := MustParse("2", prec)
+		h := MustParse("0.5", prec)
+		for _, ss := range []string{"2.5+1i", "0.5+20i", "-3.5+0.5i", "7"} {
+			s := MustParse(ss, prec)
+			// ζ(s, 1/2) = (2^s - 1) ζ(s)
+			want := Mul(Sub(Pow(two, s), one), Zeta(s))
+			if got := HurwitzZeta(s, h); !relClose(got, want, 12) {
+				t.Errorf("%d bits: ζ(%s, 1/2) = %v, want %v", prec, ss, got, want)
+			}
+			for _, as := range []string{"0.3", "2.75-1.5i", "-4.6+0.2i"} {
+				a := MustParse(as, prec)
+				// ζ(s, a) = ζ(s, a+1) + a^-s
+				lhs := HurwitzZeta(s, a)
+				if rhs := Add(HurwitzZeta(s, Add(a, one)), Pow(a, Neg(s))); !relClose(lhs, rhs, 14) {
+					t.Errorf("%d bits: ζ(%s, %s) = %v, want %v", prec, ss, as, lhs, rhs)
+				}
+			}
+			// Multiplication: ζ(s, 3a) 3^s = ζ(s, a) + ζ(s, a+1/3) + ζ(s, a+2/3)
+			a := MustParse("0.4+0.1i", prec)
+			third := Div(one, MustParse("3", prec))
+			lhs := Add(Add(HurwitzZeta(s, a), HurwitzZeta(s, Add(a, third))), HurwitzZeta(s, Add(a, Add(third, third))))
+			rhs := Mul(Pow(MustParse("3", prec), s), HurwitzZeta(s, Mul(MustParse("3", prec), a)))
+			if !relClose(lhs, rhs, 14) {
+				t.Errorf("%d bits, s = %s: multiplication %v vs %v", prec, ss, lhs, rhs)
+			}
+		}
+	}
+	if got := HurwitzZeta(MustParse("1", 64), MustParse("0.5", 64)); !got.Re().IsInf() {
+		t.Errorf("ζ(1, 1/2) = %v, want ∞", got)
+	}
+	if got := HurwitzZeta(MustParse("2", 64), MustParse("-3", 64)); !got.Re().IsInf() {
+		t.Errorf("ζ(2, -3) = %v, want ∞", got)
+	}
+	// For Re s < 0 the term 0^-s vanishes: ζ(s, -2) = ζ(s) + (-2)^-s + (-1)^-s.
+	s := MustParse("-1.5+2i", 128)
+	want := Add(Zeta(s), Add(Pow(MustParse("-2", 128), Neg(s)), Pow(MustParse("-1", 128), Neg(s))))
+	if got := HurwitzZeta(s, MustParse("-2", 128)); !relClose(got, want, 10) {
+		t.Errorf("ζ(%v, -2) = %v, want %v", s, got, want)
+	}
+}
+
+func TestDirichletEta(t *testing.T) {
+	if got, want := DirichletEta(MustParse("1", 128)), Log(MustParse("2", 128)); !got.Equal(want) {
+		t.Errorf("η(1) = %v, want %v", got, want)
+	}
+	// The direct alternating series converges fast far right.
+	s := MustParse("20+3i", 200)
+	sum := New(200).SetInt64(0, 0)
+	for k := int64(1); k <= 2000; k++ {
+		term := Pow(New(200).SetInt64(k, 0), Neg(s))
+		if k%2 == 0 {
+			term.Neg(term)
+		}
+		sum.Add(sum, term)
+	}
+	if got := DirichletEta(s); !relClose(got, sum, 10) {
+		t.Errorf("η(%v) = %v, want %v", s, got, sum)
+	}
+	// η vanishes where 2^(1-s) = 1, ζ being finite there, and is smooth through s = 1.
+	pi := Pi(200)
+	s = Add(MustParse("1", 200), Div(Mul(pi, MustParse("2i", 200)), Log(MustParse("2", 200))))
+	if z := DirichletEta(s); !z.WithinAbs(New(200).SetInt64(0, 0), MustParseReal("1e-50", 64)) {
+		t.Errorf("η(1 + 2πi/log 2) = %v, want about 0", z)
+	}
+	near := DirichletEta(MustParse("1+1e-40i", 200))
+	if !near.WithinAbs(Log(MustParse("2", 200)), MustParseReal("1e-38", 64)) {
+		t.Errorf("η(1 + 1e-40i) = %v, want about log 2", near)
+	}
+	for _, xs := range []string{"0.5", "-3.5", "2"} {
+		x := MustParse(xs, 128)
+		want := Mul(Sub(MustParse("1", 128), Pow(MustParse("2", 128), Sub(MustParse("1", 128), x))), Zeta(x))
+		if got := DirichletEta(x); !got.WithinULP(want, 4) || !got.Im().IsZero() {
+			t.Errorf("η(%s) = %v, want %v", xs, got, want)
+		}
+	}
+}
+
+func TestRiemannSiegel(t *testing.T) {
+	// Z(0) = ζ(1/2)
+	if got, want := NewReal(128).RiemannSiegelZ(NewReal(128).SetFloat64(0)), Zeta(MustParse("0.5", 128)); !New(128).SetReals(got, nil).WithinULP(want, 4) {
+		t.Errorf("Z(0) = %v, want %v", got, want)
+	}
+	// θ(t) = (t/2) log(t/2π) - t/2 - π/8 + 1/(48t) + 7/(5760t³) + O(t⁻⁵)
+	tt := 1000.0
+	th := NewReal(128).RiemannSiegelTheta(NewReal(128).SetFloat64(tt)).Float64()
+	want := tt/2*math.Log(tt/(2*math.Pi)) - tt/2 - math.Pi/8 + 1/(48*tt) + 7/(5760*tt*tt*tt)
+	if math.Abs(th-want) > 1e-12 {
+		t.Errorf("θ(%g) = %v, want %v", tt, th, want)
+	}
+	if got := NewReal(128).RiemannSiegelTheta(NewReal(128).SetFloat64(-tt)).Float64(); got != -th {
+		t.Errorf("θ(-t) = %v, want %v", got, -th)
+	}
+	// |Z(t)| = |ζ(1/2 + it)|, and Z changes sign at the first zero.
+	for _, ts := range []string{"3", "14", "14.3", "123.5"} {
+		x := MustParseReal(ts, 200)
+		z := NewReal(200).RiemannSiegelZ(x)
+		abs := Zeta(MustParse("0.5+"+ts+"i", 200)).Abs()
+		if d := NewReal(200).Sub(NewReal(200).Abs(z), abs); d.CmpAbs(NewReal(64).SetFloat64(1e-50)) > 0 {
+			t.Errorf("Z(%s) = %v, |ζ| = %v", ts, z, abs)
+		}
+	}
+	below := NewReal(128).RiemannSiegelZ(NewReal(128).SetFloat64(14.13)).Sign()
+	above := NewReal(128).RiemannSiegelZ(NewReal(128).SetFloat64(14.14)).Sign()
+	if below == above || below == 0 {
+		t.Errorf("Z does not change sign at the first zero: %d, %d", below, above)
+	}
+}
+
+func ExampleZeta() {
+	fmt.Println(Zeta(MustParse("i", 64)).StringFixed(15))
+	fmt.Println(DirichletEta(MustParse("1", 64)).StringFixed(15))
+	// Output:
+	// 0.003300223685324-0.418155449141322i
+	// 0.693147180559945+0.000000000000000i
+}